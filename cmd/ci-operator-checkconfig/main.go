@@ -63,11 +63,11 @@ func loadResolver(path string) (registry.Resolver, error) {
 	if path == "" {
 		return nil, nil
 	}
-	refs, chains, workflows, _, _, observers, err := load.Registry(path, false)
+	refs, chains, workflows, _, _, observers, deprecations, err := load.Registry(path, false)
 	if err != nil {
 		return nil, err
 	}
-	return registry.NewResolver(refs, chains, workflows, observers), nil
+	return registry.NewResolver(refs, chains, workflows, observers, deprecations), nil
 }
 
 func validateTags(seen tagSet) []error {
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/metrics"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+const (
+	// maxBatchRequestSize limits the size of request bodies for batch
+	// resolution, which only name the configurations and tests.
+	maxBatchRequestSize = 1024 * 1024
+	// maxDiffRequestSize limits the size of request bodies for diffs, which
+	// need to fit the candidate registry tree.
+	maxDiffRequestSize = 16 * 1024 * 1024
+)
+
+// configRequest identifies a configuration and the tests in it to resolve.
+type configRequest struct {
+	api.Metadata `json:",inline"`
+	// Tests are the names of the tests to resolve, all tests are
+	// resolved if empty.
+	Tests []string `json:"tests,omitempty"`
+}
+
+type batchRequest struct {
+	Configs []configRequest `json:"configs"`
+}
+
+type batchResult struct {
+	api.Metadata `json:",inline"`
+	Tests        []api.TestStepConfiguration `json:"tests,omitempty"`
+	Error        string                      `json:"error,omitempty"`
+}
+
+type diffRequest struct {
+	Configs []configRequest `json:"configs"`
+	// Registry holds the candidate registry, as file contents by their
+	// path relative to the root of the registry. It must follow the
+	// directory structure of the registry in openshift/release.
+	Registry map[string]string `json:"registry"`
+}
+
+type testDiff struct {
+	Test        string                `json:"test"`
+	Differences []registry.Difference `json:"differences,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+type diffResult struct {
+	api.Metadata `json:",inline"`
+	Tests        []testDiff `json:"tests,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// selectTests finds the configuration and trims it down to the requested tests.
+func selectTests(configAgent agents.ConfigAgent, request configRequest) (api.ReleaseBuildConfiguration, error) {
+	config, err := configAgent.GetMatchingConfig(request.Metadata)
+	if err != nil {
+		return api.ReleaseBuildConfiguration{}, fmt.Errorf("failed to get config: %w", err)
+	}
+	if len(request.Tests) == 0 {
+		return config, nil
+	}
+	byName := map[string]api.TestStepConfiguration{}
+	for _, test := range config.Tests {
+		byName[test.As] = test
+	}
+	var tests []api.TestStepConfiguration
+	for _, name := range request.Tests {
+		test, ok := byName[name]
+		if !ok {
+			return api.ReleaseBuildConfiguration{}, fmt.Errorf("no test named %s in the config", name)
+		}
+		tests = append(tests, test)
+	}
+	config.Tests = tests
+	return config, nil
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, maxSize int64, into interface{}) bool {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusNotImplemented)
+		_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSize)).Decode(into); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Could not parse request body: %v", err)
+		return false
+	}
+	return true
+}
+
+func respond(w http.ResponseWriter, response interface{}, logger *logrus.Entry) {
+	raw, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "failed to marshal response to JSON: %v", err)
+		logger.WithError(err).Error("failed to marshal response to JSON")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(raw); err != nil {
+		logger.WithError(err).Error("Failed to write response")
+	}
+}
+
+// resolveBatch resolves many configurations at once. Failures are reported
+// for each configuration, so one bad request does not fail the others.
+func resolveBatch(configAgent agents.ConfigAgent, registryAgent agents.RegistryAgent) http.HandlerFunc {
+	logger := logrus.WithField("handler", "resolveBatch")
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request batchRequest
+		if !decodeRequest(w, r, maxBatchRequestSize, &request) {
+			return
+		}
+		results := make([]batchResult, 0, len(request.Configs))
+		for _, item := range request.Configs {
+			result := batchResult{Metadata: item.Metadata}
+			config, err := selectTests(configAgent, item)
+			if err == nil {
+				config, err = registryAgent.ResolveConfig(config)
+			}
+			if err != nil {
+				metrics.RecordError("failed to resolve config in batch", configresolverMetrics.ErrorRate)
+				result.Error = err.Error()
+			} else {
+				result.Tests = config.Tests
+			}
+			results = append(results, result)
+		}
+		respond(w, results, logger)
+	}
+}
+
+// loadCandidate writes the candidate registry into a temporary directory and
+// loads it from there, as the registry loading code expects a file tree. The
+// candidate is never loaded as a flat registry: only the directory structure
+// checks ensure that the commands of its components are read from files of
+// the candidate and not from anywhere on the server.
+func loadCandidate(files map[string]string) (registry.Resolver, error) {
+	dir, err := ioutil.TempDir("", "candidate-registry")
+	if err != nil {
+		return nil, fmt.Errorf("could not create directory for the candidate registry: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logrus.WithError(err).Warn("Failed to remove candidate registry.")
+		}
+	}()
+	for name, content := range files {
+		relative := filepath.Clean(filepath.FromSlash(name))
+		if filepath.IsAbs(relative) || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("registry file path %s must be relative to the registry root", name)
+		}
+		path := filepath.Join(dir, relative)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("could not create directory for %s: %w", name, err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("could not write %s: %w", name, err)
+		}
+	}
+	references, chains, workflows, _, _, observers, deprecations, err := load.Registry(dir, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid candidate registry: %w", err)
+	}
+	return registry.NewResolver(references, chains, workflows, observers, deprecations), nil
+}
+
+// diffTests resolves the multi-stage tests with both registries and reports
+// how the candidate changes them. Tests pinned to a registry version are
+// compared using the current version instead.
+func diffTests(current, candidate registry.Resolver, config api.ReleaseBuildConfiguration) []testDiff {
+	var diffs []testDiff
+	for _, test := range config.Tests {
+		if test.MultiStageTestConfiguration == nil {
+			continue
+		}
+		unpinned := *test.MultiStageTestConfiguration
+		unpinned.RegistryVersion = ""
+		result := testDiff{Test: test.As}
+		before, err := current.Resolve(test.As, unpinned)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to resolve with the current registry: %v", err)
+			diffs = append(diffs, result)
+			continue
+		}
+		after, err := candidate.Resolve(test.As, unpinned)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to resolve with the candidate registry: %v", err)
+			diffs = append(diffs, result)
+			continue
+		}
+		// the current version is recorded by the agent and would always differ
+		before.RegistryVersion, after.RegistryVersion = "", ""
+		if result.Differences, err = registry.DiffLiterals(before, after); err != nil {
+			result.Error = err.Error()
+		}
+		diffs = append(diffs, result)
+	}
+	return diffs
+}
+
+// requireToken only serves requests that carry the token as a bearer token.
+func requireToken(token string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		provided := strings.TrimPrefix(header, "Bearer ")
+		if provided == header || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusUnauthorized)))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// diffRegistry compares how tests resolve with the current registry and with
+// a candidate registry supplied in the request.
+func diffRegistry(configAgent agents.ConfigAgent, registryAgent agents.RegistryAgent) http.HandlerFunc {
+	logger := logrus.WithField("handler", "diff")
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request diffRequest
+		if !decodeRequest(w, r, maxDiffRequestSize, &request) {
+			return
+		}
+		if len(request.Registry) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("A candidate registry is required."))
+			return
+		}
+		candidate, err := loadCandidate(request.Registry)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "failed to load candidate registry: %v", err)
+			return
+		}
+		results := make([]diffResult, 0, len(request.Configs))
+		for _, item := range request.Configs {
+			result := diffResult{Metadata: item.Metadata}
+			config, err := selectTests(configAgent, item)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Tests = diffTests(registryAgent, candidate, config)
+			}
+			results = append(results, result)
+		}
+		respond(w, results, logger)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadCandidate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		files       map[string]string
+		expectedErr bool
+	}{
+		{
+			name: "valid registry",
+			files: map[string]string{
+				"step/step-ref.yaml": `ref:
+  as: step
+  from: base
+  commands: step-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+`,
+				"step/step-commands.sh": "true",
+			},
+		},
+		{
+			name: "commands outside of the candidate cannot be read",
+			files: map[string]string{
+				"step/step-ref.yaml": `ref:
+  as: step
+  from: base
+  commands: ../../../../../../etc/passwd
+  resources:
+    requests:
+      cpu: 100m
+`,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "files outside of the candidate cannot be written",
+			files: map[string]string{
+				"../step-commands.sh": "true",
+			},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := loadCandidate(tc.files); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	handler := requireToken("secret", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		expected      int
+	}{
+		{
+			name:     "no token",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong token",
+			authorization: "Bearer other",
+			expected:      http.StatusUnauthorized,
+		},
+		{
+			name:          "token without the bearer scheme",
+			authorization: "secret",
+			expected:      http.StatusUnauthorized,
+		},
+		{
+			name:          "correct token",
+			authorization: "Bearer secret",
+			expected:      http.StatusOK,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/diff", nil)
+			if tc.authorization != "" {
+				request.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+			if recorder.Code != tc.expected {
+				t.Errorf("expected status %d, got %d", tc.expected, recorder.Code)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -37,6 +38,8 @@ type options struct {
 	gracePeriod            time.Duration
 	validateOnly           bool
 	flatRegistry           bool
+	snapshotDir            string
+	diffTokenFile          string
 	instrumentationOptions flagutil.InstrumentationOptions
 }
 
@@ -58,6 +61,8 @@ func gatherOptions() (options, error) {
 	_ = fs.Duration("cycle", time.Minute*2, "Legacy flag kept for compatibility. Does nothing")
 	fs.BoolVar(&o.validateOnly, "validate-only", false, "Load the config and registry, validate them and exit.")
 	fs.BoolVar(&o.flatRegistry, "flat-registry", false, "Disable directory structure based registry validation")
+	fs.StringVar(&o.snapshotDir, "registry-snapshot-dir", "", "Directory to persist every loaded version of the registry in, so that tests pinned to older versions can be resolved after restarts. Without it, every version loaded since the start is kept in memory.")
+	fs.StringVar(&o.diffTokenFile, "diff-token-file", "", "File holding the token that clients of the /diff endpoint must send as a bearer token. The endpoint is disabled if unset.")
 	o.instrumentationOptions.AddFlags(fs)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
@@ -88,6 +93,11 @@ func validateOptions(o options) error {
 		}
 		return fmt.Errorf("Error getting stat info for --registry directory: %w", err)
 	}
+	if o.diffTokenFile != "" {
+		if _, err := os.Stat(o.diffTokenFile); err != nil {
+			return fmt.Errorf("--diff-token-file is not readable: %w", err)
+		}
+	}
 	if o.validateOnly && o.flatRegistry {
 		return errors.New("--validate-only and --flat-registry flags cannot be set simultaneously")
 	}
@@ -184,6 +194,13 @@ func getRegistryGeneration(agent agents.RegistryAgent) http.HandlerFunc {
 	}
 }
 
+func getRegistryVersion(agent agents.RegistryAgent) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, agent.GetVersion())
+	}
+}
+
 // l and v keep the tree legible
 func l(fragment string, children ...simplifypath.Node) simplifypath.Node {
 	return simplifypath.L(fragment, children...)
@@ -206,7 +223,7 @@ func main() {
 		logrus.Fatalf("Failed to get config agent: %v", err)
 	}
 
-	registryAgent, err := agents.NewRegistryAgent(o.registryPath, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry))
+	registryAgent, err := agents.NewRegistryAgent(o.registryPath, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry), agents.WithRegistrySnapshots(o.snapshotDir))
 	if err != nil {
 		logrus.Fatalf("Failed to get registry agent: %v", err)
 	}
@@ -219,8 +236,11 @@ func main() {
 	simplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
 		l("config"),
 		l("resolve"),
+		l("resolveBatch"),
+		l("diff"),
 		l("configGeneration"),
 		l("registryGeneration"),
+		l("registryVersion"),
 	))
 
 	uisimplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
@@ -236,6 +256,11 @@ func main() {
 		l("reference"),
 		l("chain"),
 		l("workflow"),
+		l("impact",
+			l("reference"),
+			l("chain"),
+			l("workflow"),
+		),
 	))
 	handler := metrics.TraceHandler(simplifier, configresolverMetrics.HTTPRequestDuration, configresolverMetrics.HTTPResponseSize)
 	uihandler := metrics.TraceHandler(uisimplifier, configresolverMetrics.HTTPRequestDuration, configresolverMetrics.HTTPResponseSize)
@@ -243,8 +268,23 @@ func main() {
 	http.HandleFunc("/", handler(http.HandlerFunc(http.NotFound)).ServeHTTP)
 	http.HandleFunc("/config", handler(resolveConfig(configAgent, registryAgent)).ServeHTTP)
 	http.HandleFunc("/resolve", handler(resolveLiteralConfig(registryAgent)).ServeHTTP)
+	http.HandleFunc("/resolveBatch", handler(resolveBatch(configAgent, registryAgent)).ServeHTTP)
+	if o.diffTokenFile != "" {
+		// diffs load arbitrary registries sent by clients, so they are
+		// only served to clients that know the token
+		raw, err := ioutil.ReadFile(o.diffTokenFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to read the token for /diff.")
+		}
+		token := strings.TrimSpace(string(raw))
+		if token == "" {
+			logrus.Fatal("The token for /diff must not be empty.")
+		}
+		http.HandleFunc("/diff", handler(requireToken(token, diffRegistry(configAgent, registryAgent))).ServeHTTP)
+	}
 	http.HandleFunc("/configGeneration", handler(getConfigGeneration(configAgent)).ServeHTTP)
 	http.HandleFunc("/registryGeneration", handler(getRegistryGeneration(registryAgent)).ServeHTTP)
+	http.HandleFunc("/registryVersion", handler(getRegistryVersion(registryAgent)).ServeHTTP)
 	interrupts.ListenAndServe(&http.Server{Addr: ":" + strconv.Itoa(o.port)}, o.gracePeriod)
 	uiServer := &http.Server{
 		Addr:    ":" + strconv.Itoa(o.uiPort),
@@ -80,6 +80,11 @@ func readProwgenConfig(path string) (*config.Prowgen, error) {
 		if err := yaml.Unmarshal(b, &pConfig); err != nil {
 			return nil, fmt.Errorf("prowgen config found in path %sbut couldn't unmarshal it: %w", path, err)
 		}
+		if pConfig != nil {
+			if err := pConfig.Validate(); err != nil {
+				return nil, fmt.Errorf("prowgen config found in path %s is invalid: %w", path, err)
+			}
+		}
 	}
 
 	return pConfig, nil
@@ -359,6 +359,7 @@ type options struct {
 	idleCleanupDurationSet bool
 	cleanupDuration        time.Duration
 	cleanupDurationSet     bool
+	resume                 bool
 
 	inputHash                  string
 	secrets                    []*coreapi.Secret
@@ -369,6 +370,7 @@ type options struct {
 	consoleHost                string
 	leaseServer                string
 	leaseServerCredentialsFile string
+	leaseFile                  string
 	leaseAcquireTimeout        time.Duration
 	leaseClient                lease.Client
 
@@ -421,6 +423,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 	// what we will run
 	flag.StringVar(&opt.leaseServer, "lease-server", leaseServerAddress, "Address of the server that manages leases. Required if any test is configured to acquire a lease.")
 	flag.StringVar(&opt.leaseServerCredentialsFile, "lease-server-credentials-file", "", "The path to credentials file used to access the lease server. The content is of the form <username>:<password>.")
+	flag.StringVar(&opt.leaseFile, "lease-file", "", "The path to a local file declaring the resources that can be leased. If set, leases are managed in this file instead of by the lease server.")
 	flag.DurationVar(&opt.leaseAcquireTimeout, "lease-acquire-timeout", leaseAcquireTimeout, "Maximum amount of time to wait for lease acquisition")
 	flag.StringVar(&opt.registryPath, "registry", "", "Path to the step registry directory")
 	flag.StringVar(&opt.configSpecPath, "config", "", "The configuration file. If not specified the CONFIG_SPEC environment variable or the configresolver will be used.")
@@ -440,6 +443,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.baseNamespace, "base-namespace", "stable", "Namespace to read builds from, defaults to stable.")
 	flag.DurationVar(&opt.idleCleanupDuration, "delete-when-idle", opt.idleCleanupDuration, "If no pod is running for longer than this interval, delete the namespace. Set to zero to retain the contents. Requires the namespace TTL controller to be deployed.")
 	flag.DurationVar(&opt.cleanupDuration, "delete-after", opt.cleanupDuration, "If namespace exists for longer than this interval, delete the namespace. Set to zero to retain the contents. Requires the namespace TTL controller to be deployed.")
+	flag.BoolVar(&opt.resume, "resume", false, "Skip steps that completed in a previous execution with the same input hash in the same namespace.")
 
 	// actions to add to the graph
 	flag.BoolVar(&opt.promote, "promote", false, "When all other targets complete, publish the set of images built by this job into the release configuration.")
@@ -796,7 +800,7 @@ func (o *options) Run() []error {
 		cancel()
 	}
 	var leaseClient *lease.Client
-	if o.leaseServer != "" && o.leaseServerCredentialsFile != "" {
+	if o.leaseFile != "" || (o.leaseServer != "" && o.leaseServerCredentialsFile != "") {
 		leaseClient = &o.leaseClient
 	}
 	// load the graph from the configuration
@@ -815,6 +819,10 @@ func (o *options) Run() []error {
 	if err := o.writeMetadataJSON(); err != nil {
 		return []error{fmt.Errorf("unable to write metadata.json for build: %w", err)}
 	}
+	deprecations := o.deprecatedComponents()
+	for _, deprecation := range deprecations {
+		logrus.Warn(deprecation.String())
+	}
 	if o.print {
 		if err := printDigraph(os.Stdout, buildSteps); err != nil {
 			return []error{fmt.Errorf("could not print graph: %w", err)}
@@ -873,8 +881,18 @@ func (o *options) Run() []error {
 		}
 		runtimeObject := &coreapi.ObjectReference{Namespace: o.namespace}
 		eventRecorder.Event(runtimeObject, coreapi.EventTypeNormal, "CiJobStarted", eventJobDescription(o.jobSpec, o.namespace))
+		crclient, err := ctrlruntimeclient.New(o.clusterConfig, ctrlruntimeclient.Options{})
+		if err != nil {
+			return []error{fmt.Errorf("failed to construct client: %w", err)}
+		}
+		checkpoint, err := steps.NewConfigMapCheckpoint(ctx, crclient, o.namespace, o.inputHash, o.resume)
+		if err != nil {
+			return []error{fmt.Errorf("could not load checkpoint: %w", err)}
+		}
 		// execute the graph
-		suites, graphDetails, errs := steps.Run(ctx, nodes)
+		suites, graphDetails, errs := steps.Run(ctx, nodes, checkpoint)
+		o.collectStepJUnit(suites)
+		reportDeprecations(suites, deprecations)
 		if err := o.writeJUnit(suites, "operator"); err != nil {
 			logrus.WithError(err).Warn("Unable to write JUnit result.")
 		}
@@ -1343,6 +1361,9 @@ type prowResultMetadata struct {
 	Pod           string            `json:"pod"`
 	WorkNamespace string            `json:"work-namespace"`
 	Metadata      map[string]string `json:"metadata"`
+	// RegistryVersions holds the version of the step registry each
+	// multi-stage test was resolved with, to allow reproducing it.
+	RegistryVersions map[string]string `json:"registry-versions,omitempty"`
 }
 
 const metadataJSONfile = "metadata.json"
@@ -1439,6 +1460,18 @@ func (o *options) generateProwMetadata() (m prowResultMetadata) {
 	m.Pod = o.jobSpec.ProwJobID
 	m.WorkNamespace = o.namespace
 
+	if o.configSpec != nil {
+		for _, test := range o.configSpec.Tests {
+			if test.MultiStageTestConfigurationLiteral == nil || test.MultiStageTestConfigurationLiteral.RegistryVersion == "" {
+				continue
+			}
+			if m.RegistryVersions == nil {
+				m.RegistryVersions = map[string]string{}
+			}
+			m.RegistryVersions[test.As] = test.MultiStageTestConfigurationLiteral.RegistryVersion
+		}
+	}
+
 	return m
 }
 
@@ -1535,6 +1568,73 @@ func (o *options) writeFailingJUnit(errs []error) {
 	}
 }
 
+// collectStepJUnit adds the jUnit results that steps saved in their artifacts
+// to the operator suite, one child suite per step.
+func (o *options) collectStepJUnit(suites *junit.TestSuites) {
+	artifactDir, set := api.Artifacts()
+	if !set || suites == nil {
+		return
+	}
+	stepSuites, err := junit.Collect(artifactDir)
+	if err != nil {
+		logrus.WithError(err).Warn("Unable to collect jUnit results from step artifacts.")
+		return
+	}
+	if len(stepSuites) == 0 {
+		return
+	}
+	// The step suites are already deduplicated; the operator's own test cases
+	// are left as they are, so only the counts of the step suites are added.
+	operator := suites.Suites[0]
+	for _, suite := range stepSuites {
+		operator.Children = append(operator.Children, suite)
+		operator.NumTests += suite.NumTests
+		operator.NumFailed += suite.NumFailed
+		operator.NumSkipped += suite.NumSkipped
+	}
+}
+
+// deprecatedComponents lists the deprecated registry components that the
+// targeted tests execute.
+func (o *options) deprecatedComponents() []api.DeprecatedComponent {
+	if o.configSpec == nil {
+		return nil
+	}
+	targets := sets.NewString(o.targets.values...)
+	seen := sets.NewString()
+	var deprecated []api.DeprecatedComponent
+	for _, test := range o.configSpec.Tests {
+		if test.MultiStageTestConfigurationLiteral == nil || !targets.Has(test.As) {
+			continue
+		}
+		for _, component := range test.MultiStageTestConfigurationLiteral.Deprecations {
+			if key := component.Type + "/" + component.Name; !seen.Has(key) {
+				seen.Insert(key)
+				deprecated = append(deprecated, component)
+			}
+		}
+	}
+	return deprecated
+}
+
+// reportDeprecations records the use of deprecated registry components as
+// skipped tests, so that they are visible in the results without failing
+// the job.
+func reportDeprecations(suites *junit.TestSuites, deprecations []api.DeprecatedComponent) {
+	if suites == nil || len(deprecations) == 0 {
+		return
+	}
+	suite := suites.Suites[0]
+	for _, deprecation := range deprecations {
+		suite.TestCases = append(suite.TestCases, &junit.TestCase{
+			Name:        fmt.Sprintf("Registry %s %s is not deprecated", deprecation.Type, deprecation.Name),
+			SkipMessage: &junit.SkipMessage{Message: deprecation.String()},
+		})
+		suite.NumTests++
+		suite.NumSkipped++
+	}
+}
+
 func (o *options) writeJUnit(suites *junit.TestSuites, name string) error {
 	if suites == nil {
 		return nil
@@ -1633,12 +1733,18 @@ func loadLeaseCredentials(leaseServerCredentialsFile string) (string, func() []b
 func (o *options) initializeLeaseClient() error {
 	var err error
 	owner := o.namespace + "-" + o.jobSpec.JobNameHash()
-	username, passwordGetter, err := loadLeaseCredentials(o.leaseServerCredentialsFile)
-	if err != nil {
-		return fmt.Errorf("failed to load lease credentials: %w", err)
-	}
-	if o.leaseClient, err = lease.NewClient(owner, o.leaseServer, username, passwordGetter, 60, o.leaseAcquireTimeout); err != nil {
-		return fmt.Errorf("failed to create the lease client: %w", err)
+	if o.leaseFile != "" {
+		if o.leaseClient, err = lease.NewLocalClient(owner, o.leaseFile, 60, o.leaseAcquireTimeout); err != nil {
+			return fmt.Errorf("failed to create the local lease client: %w", err)
+		}
+	} else {
+		username, passwordGetter, err := loadLeaseCredentials(o.leaseServerCredentialsFile)
+		if err != nil {
+			return fmt.Errorf("failed to load lease credentials: %w", err)
+		}
+		if o.leaseClient, err = lease.NewClient(owner, o.leaseServer, username, passwordGetter, 60, o.leaseAcquireTimeout); err != nil {
+			return fmt.Errorf("failed to create the lease client: %w", err)
+		}
 	}
 	t := time.NewTicker(30 * time.Second)
 	go func() {
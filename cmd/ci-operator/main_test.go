@@ -24,6 +24,7 @@ import (
 	imagev1 "github.com/openshift/api/image/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/load"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/secrets"
@@ -841,3 +842,42 @@ func TestDependencyOverrides(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectStepJUnit(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.Setenv("ARTIFACTS", tempDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("ARTIFACTS")
+	stepDir := filepath.Join(tempDir, "e2e", "test")
+	if err := os.MkdirAll(stepDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stepResults := `<testsuite name="openshift-tests"><testcase name="flaky"><failure message="timed out"/></testcase><testcase name="flaky"/></testsuite>`
+	if err := ioutil.WriteFile(filepath.Join(stepDir, "junit_e2e.xml"), []byte(stepResults), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Retried steps report every attempt, which must not be collapsed.
+	operator := &junit.TestSuite{
+		Name:      "job",
+		NumTests:  2,
+		NumFailed: 1,
+		TestCases: []*junit.TestCase{
+			{Name: "Run multi-stage test e2e - e2e-test (attempt 1) container test", FailureOutput: &junit.FailureOutput{Output: "failed"}},
+			{Name: "Run multi-stage test e2e - e2e-test (attempt 2) container test"},
+		},
+	}
+	suites := &junit.TestSuites{Suites: []*junit.TestSuite{operator}}
+	(&options{}).collectStepJUnit(suites)
+
+	if len(operator.TestCases) != 2 {
+		t.Errorf("expected the operator's test cases to be kept, got %d", len(operator.TestCases))
+	}
+	if operator.NumTests != 3 || operator.NumFailed != 1 {
+		t.Errorf("expected 3 tests with 1 failure, got %d tests with %d failures", operator.NumTests, operator.NumFailed)
+	}
+	if len(operator.Children) != 1 || operator.Children[0].Name != "e2e/test" || operator.Children[0].NumTests != 1 {
+		t.Errorf("expected one deduplicated suite for the step, got %v", operator.Children)
+	}
+}
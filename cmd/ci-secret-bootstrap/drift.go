@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/interrupts"
+	"k8s.io/test-infra/prow/metrics"
+	"k8s.io/test-infra/prow/pjutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/secrets"
+)
+
+const (
+	driftMissing = "missing"
+	driftStale   = "stale"
+	driftExtra   = "extra"
+	driftType    = "type"
+)
+
+var (
+	driftedKeys = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ci_secret_bootstrap_drifted_keys",
+			Help: "number of keys of a secret that differ from the source, by kind of drift, or 1 for the type kind if the type of the secret differs",
+		},
+		[]string{"cluster", "namespace", "name", "kind"},
+	)
+	reconcileErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ci_secret_bootstrap_reconcile_errors_total",
+			Help: "number of reconciliations that failed",
+		},
+	)
+	lastReconcile = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ci_secret_bootstrap_last_reconcile_timestamp_seconds",
+			Help: "time of the last finished reconciliation",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(driftedKeys, reconcileErrors, lastReconcile)
+}
+
+// keyDrift describes a key that differs between the source and the cluster.
+// Only keyed hashes of the values are ever recorded, never the values themselves.
+type keyDrift struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// secretDrift describes how a secret on a cluster differs from its source
+type secretDrift struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Absent is set when the secret does not exist on the cluster at all
+	Absent bool `json:"absent,omitempty"`
+	// Missing keys are in the source but not on the cluster
+	Missing []keyDrift `json:"missing,omitempty"`
+	// Stale keys have a different value on the cluster than in the source
+	Stale []keyDrift `json:"stale,omitempty"`
+	// Extra keys are on the cluster but not in the source. They are kept when
+	// the secret is updated, so they are informational only.
+	Extra []keyDrift `json:"extra,omitempty"`
+	// Type is set when the secret on the cluster has a different type than
+	// the one constructed from the source
+	Type *typeDrift `json:"type,omitempty"`
+}
+
+// typeDrift describes a secret whose type differs between the source and the cluster
+type typeDrift struct {
+	Expected coreapi.SecretType `json:"expected"`
+	Actual   coreapi.SecretType `json:"actual"`
+}
+
+// drifted determines whether updating the secret would change it
+func (d secretDrift) drifted() bool {
+	return d.Absent || len(d.Missing) > 0 || len(d.Stale) > 0 || d.Type != nil
+}
+
+// hasher hashes the values of secrets for the drift report
+type hasher func(value []byte) string
+
+// newHasher returns a hasher computing an HMAC of the values with the key, so
+// that the hashes cannot be matched against precomputed hashes of guessed values
+func newHasher(key []byte) hasher {
+	return func(value []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(value)
+		return fmt.Sprintf("hmac-sha256:%x", mac.Sum(nil))
+	}
+}
+
+// newRunHasher returns a hasher keyed with a random key. The hashes can only be
+// compared with each other within a single run.
+func newRunHasher() (hasher, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate the key for hashing secret values: %w", err)
+	}
+	return newHasher(key), nil
+}
+
+// diffSecret compares the secret constructed from the source with the one on
+// the cluster, which is nil if it does not exist
+func diffSecret(cluster string, expected, actual *coreapi.Secret, hashValue hasher) secretDrift {
+	drift := secretDrift{Cluster: cluster, Namespace: expected.Namespace, Name: expected.Name}
+	var actualData map[string][]byte
+	if actual == nil {
+		drift.Absent = true
+	} else {
+		actualData = actual.Data
+		if actual.Type != expected.Type {
+			drift.Type = &typeDrift{Expected: expected.Type, Actual: actual.Type}
+		}
+	}
+	for _, key := range sortedKeys(expected.Data) {
+		actualValue, exists := actualData[key]
+		switch {
+		case !exists:
+			drift.Missing = append(drift.Missing, keyDrift{Key: key, Expected: hashValue(expected.Data[key])})
+		case string(actualValue) != string(expected.Data[key]):
+			drift.Stale = append(drift.Stale, keyDrift{Key: key, Expected: hashValue(expected.Data[key]), Actual: hashValue(actualValue)})
+		}
+	}
+	for _, key := range sortedKeys(actualData) {
+		if _, exists := expected.Data[key]; !exists {
+			drift.Extra = append(drift.Extra, keyDrift{Key: key, Actual: hashValue(actualData[key])})
+		}
+	}
+	return drift
+}
+
+func sortedKeys(data map[string][]byte) []string {
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// computeDrift compares all secrets constructed from the source with their
+// counterparts on the clusters
+func computeDrift(getters map[string]Getter, secretsMap map[string][]*coreapi.Secret, hashValue hasher) ([]secretDrift, error) {
+	var clusters []string
+	for cluster := range secretsMap {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	var drifts []secretDrift
+	var errs []error
+	for _, cluster := range clusters {
+		expected := secretsMap[cluster]
+		sort.Slice(expected, func(i, j int) bool {
+			if expected[i].Namespace != expected[j].Namespace {
+				return expected[i].Namespace < expected[j].Namespace
+			}
+			return expected[i].Name < expected[j].Name
+		})
+		for _, secret := range expected {
+			actual, err := getters[cluster].Secrets(secret.Namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				actual = nil
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("error reading secret %s:%s/%s: %w", cluster, secret.Namespace, secret.Name, err))
+				continue
+			}
+			drifts = append(drifts, diffSecret(cluster, secret, actual, hashValue))
+		}
+	}
+	return drifts, utilerrors.NewAggregate(errs)
+}
+
+// writeDriftReport writes the secrets that differ from the source in any way
+func writeDriftReport(drifts []secretDrift, w io.Writer) error {
+	var report []secretDrift
+	for _, drift := range drifts {
+		if drift.drifted() || len(drift.Extra) > 0 {
+			report = append(report, drift)
+		}
+	}
+	raw, err := yaml.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the drift report: %w", err)
+	}
+	_, err = w.Write(raw)
+	return err
+}
+
+func recordDrift(drifts []secretDrift) {
+	driftedKeys.Reset()
+	for _, drift := range drifts {
+		for kind, keys := range map[string][]keyDrift{driftMissing: drift.Missing, driftStale: drift.Stale, driftExtra: drift.Extra} {
+			driftedKeys.WithLabelValues(drift.Cluster, drift.Namespace, drift.Name, kind).Set(float64(len(keys)))
+		}
+		var typeDrifted float64
+		if drift.Type != nil {
+			typeDrifted = 1
+		}
+		driftedKeys.WithLabelValues(drift.Cluster, drift.Namespace, drift.Name, driftType).Set(typeDrifted)
+	}
+}
+
+func logDrift(drifts []secretDrift) {
+	for _, drift := range drifts {
+		if !drift.drifted() {
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"cluster":   drift.Cluster,
+			"namespace": drift.Namespace,
+			"name":      drift.Name,
+			"absent":    drift.Absent,
+			"missing":   len(drift.Missing),
+			"stale":     len(drift.Stale),
+			"type":      drift.Type != nil,
+		}).Warn("Secret drifted from the source")
+	}
+}
+
+// reconcile constructs the secrets from the source, records how the secrets on
+// the clusters drifted from them and updates them unless in dry-run mode
+func reconcile(o options, client secrets.ReadOnlyClient) error {
+	var errs []error
+	// errors returned by constructSecrets will be handled once the rest of the secrets have been compared
+	secretsMap, err := constructSecrets(o.config, client)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	hashValue, err := newRunHasher()
+	if err != nil {
+		return utilerrors.NewAggregate(append(errs, err))
+	}
+	drifts, err := computeDrift(o.secretsGetters, secretsMap, hashValue)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to compute drift: %w", err))
+	}
+	recordDrift(drifts)
+	logDrift(drifts)
+	if !o.dryRun {
+		if err := updateSecrets(o.secretsGetters, secretsMap, o.force); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update secrets: %w", err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// runController reconciles the secrets periodically until interrupted
+func runController(o options, client secrets.ReadOnlyClient) {
+	metrics.ExposeMetrics("ci-secret-bootstrap", prowconfig.PushGateway{}, o.instrumentationOptions.MetricsPort)
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
+	interrupts.OnInterrupt(func() {
+		if _, err := client.Logout(); err != nil {
+			logrus.WithError(err).Error("Failed to logout.")
+		}
+	})
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := reconcile(o, client); err != nil {
+			reconcileErrors.Inc()
+			logrus.WithError(err).Error("Failed to reconcile secrets.")
+		}
+		lastReconcile.SetToCurrentTime()
+		logrus.WithField("duration", time.Since(start).String()).Info("Reconciled secrets.")
+	}, o.resyncInterval)
+	health.ServeReady()
+	interrupts.WaitForGracefulShutdown()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestComputeDrift(t *testing.T) {
+	hashValue := newHasher([]byte("key"))
+	secret := func(namespace, name string, data map[string]string) *coreapi.Secret {
+		s := &coreapi.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Type: coreapi.SecretTypeOpaque, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+	dockerConfig := func(s *coreapi.Secret) *coreapi.Secret {
+		s.Type = coreapi.SecretTypeDockerConfigJson
+		return s
+	}
+	clients := map[string]Getter{
+		"build01": fake.NewSimpleClientset(
+			secret("ci", "in-sync", map[string]string{"key": "value"}),
+			secret("ci", "drifted", map[string]string{"stale": "old", "extra": "value"}),
+			secret("ci", "retyped", map[string]string{".dockerconfigjson": "value"}),
+		).CoreV1(),
+		"build02": fake.NewSimpleClientset().CoreV1(),
+	}
+	secretsMap := map[string][]*coreapi.Secret{
+		"build01": {
+			secret("ci", "in-sync", map[string]string{"key": "value"}),
+			secret("ci", "drifted", map[string]string{"stale": "new", "missing": "value"}),
+			dockerConfig(secret("ci", "retyped", map[string]string{".dockerconfigjson": "value"})),
+		},
+		"build02": {
+			secret("ci", "pull-secret", map[string]string{"key": "value"}),
+		},
+	}
+	expected := []secretDrift{
+		{
+			Cluster:   "build01",
+			Namespace: "ci",
+			Name:      "drifted",
+			Missing:   []keyDrift{{Key: "missing", Expected: hashValue([]byte("value"))}},
+			Stale:     []keyDrift{{Key: "stale", Expected: hashValue([]byte("new")), Actual: hashValue([]byte("old"))}},
+			Extra:     []keyDrift{{Key: "extra", Actual: hashValue([]byte("value"))}},
+		},
+		{
+			Cluster:   "build01",
+			Namespace: "ci",
+			Name:      "in-sync",
+		},
+		{
+			Cluster:   "build01",
+			Namespace: "ci",
+			Name:      "retyped",
+			Type:      &typeDrift{Expected: coreapi.SecretTypeDockerConfigJson, Actual: coreapi.SecretTypeOpaque},
+		},
+		{
+			Cluster:   "build02",
+			Namespace: "ci",
+			Name:      "pull-secret",
+			Absent:    true,
+			Missing:   []keyDrift{{Key: "key", Expected: hashValue([]byte("value"))}},
+		},
+	}
+
+	actual, err := computeDrift(clients, secretsMap, hashValue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected drift: %s", diff)
+	}
+	var drifted []string
+	for _, drift := range actual {
+		if drift.drifted() {
+			drifted = append(drifted, drift.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{"drifted", "retyped", "pull-secret"}, drifted); diff != "" {
+		t.Errorf("unexpected drifted secrets: %s", diff)
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	value := []byte("super-secret")
+	if newHasher([]byte("key"))(value) != newHasher([]byte("key"))(value) {
+		t.Error("expected the same key to produce the same hash")
+	}
+	if newHasher([]byte("key"))(value) == newHasher([]byte("other"))(value) {
+		t.Error("expected different keys to produce different hashes")
+	}
+	first, err := newRunHasher()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := newRunHasher()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first(value) == second(value) {
+		t.Error("expected every run to hash values with a different key")
+	}
+}
+
+func TestWriteDriftReport(t *testing.T) {
+	hashValue := newHasher([]byte("key"))
+	drifts := []secretDrift{
+		{Cluster: "build01", Namespace: "ci", Name: "in-sync"},
+		{Cluster: "build01", Namespace: "ci", Name: "extra", Extra: []keyDrift{{Key: "extra", Actual: hashValue([]byte("super-secret"))}}},
+		{Cluster: "build02", Namespace: "ci", Name: "stale", Stale: []keyDrift{{Key: "key", Expected: hashValue([]byte("new-secret")), Actual: hashValue([]byte("old-secret"))}}},
+	}
+	var buf bytes.Buffer
+	if err := writeDriftReport(drifts, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `- cluster: build01
+  extra:
+  - actual: ` + hashValue([]byte("super-secret")) + `
+    key: extra
+  name: extra
+  namespace: ci
+- cluster: build02
+  name: stale
+  namespace: ci
+  stale:
+  - actual: ` + hashValue([]byte("old-secret")) + `
+    expected: ` + hashValue([]byte("new-secret")) + `
+    key: key
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("unexpected report: %s", diff)
+	}
+	for _, value := range []string{"super-secret", "new-secret", "old-secret"} {
+		if bytes.Contains(buf.Bytes(), []byte(value)) {
+			t.Errorf("report contains the secret value %q", value)
+		}
+	}
+}
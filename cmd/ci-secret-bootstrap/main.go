@@ -62,6 +62,11 @@ type options struct {
 	bwAllowUnused flagutil.Strings
 
 	validateOnly bool
+
+	diff                   bool
+	controller             bool
+	resyncInterval         time.Duration
+	instrumentationOptions flagutil.InstrumentationOptions
 }
 
 const (
@@ -85,6 +90,10 @@ func parseOptions(censor *secrets.DynamicCensor) (options, error) {
 	fs.BoolVar(&o.force, "force", false, "If true, update the secrets even if existing one differs from Bitwarden items instead of existing with error. Default false.")
 	fs.StringVar(&o.logLevel, "log-level", "info", fmt.Sprintf("Log level is one of %v.", logrus.AllLevels))
 	fs.StringVar(&o.impersonateUser, "as", "", "Username to impersonate")
+	fs.BoolVar(&o.diff, "diff", false, "If set, the tool only reports which keys of the secrets on the clusters are missing, stale or extra compared with the source, using hashes of their values.")
+	fs.BoolVar(&o.controller, "controller", false, "If set, the tool keeps running and reconciles the secrets periodically, exporting metrics about their drift from the source.")
+	fs.DurationVar(&o.resyncInterval, "resync-interval", 30*time.Minute, "The interval at which the secrets are reconciled in controller mode.")
+	o.instrumentationOptions.AddFlags(fs)
 	o.secrets.Bind(fs, os.Getenv, censor)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return options{}, err
@@ -106,6 +115,15 @@ func (o *options) validateOptions() error {
 	if len(o.bwAllowUnused.Strings()) > 0 && !o.validateBWItemsUsage {
 		errs = append(errs, errors.New("--bw-allow-unused must be specified with --validate-bitwarden-items-usage"))
 	}
+	if o.diff && o.controller {
+		errs = append(errs, errors.New("--diff and --controller are mutually exclusive"))
+	}
+	if o.validateOnly && (o.diff || o.controller) {
+		errs = append(errs, errors.New("--validate-only cannot be specified with --diff or --controller"))
+	}
+	if o.controller && o.resyncInterval <= 0 {
+		errs = append(errs, errors.New("--resync-interval must be positive"))
+	}
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -885,6 +903,11 @@ func main() {
 		logrus.WithError(err).Fatal("Failed to create client.")
 	}
 
+	if o.controller {
+		runController(o, client)
+		return
+	}
+
 	if errs := reconcileSecrets(o, client); len(errs) > 0 {
 		logrus.WithError(utilerrors.NewAggregate(errs)).Fatalf("errors while updating secrets")
 	}
@@ -928,6 +951,30 @@ func reconcileSecrets(o options, client secrets.ReadOnlyClient) (errs []error) {
 		}
 	}
 
+	if o.diff {
+		hashValue, err := newRunHasher()
+		if err != nil {
+			return append(errs, err)
+		}
+		drifts, err := computeDrift(o.secretsGetters, secretsMap, hashValue)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute drift: %w", err))
+		}
+		if err := writeDriftReport(drifts, os.Stdout); err != nil {
+			errs = append(errs, err)
+		}
+		var drifted int
+		for _, drift := range drifts {
+			if drift.drifted() {
+				drifted++
+			}
+		}
+		if drifted > 0 {
+			errs = append(errs, fmt.Errorf("%d secrets drifted from the source", drifted))
+		}
+		return errs
+	}
+
 	if o.dryRun {
 		logrus.Infof("Running in dry-run mode")
 		if err := writeSecrets(secretsMap); err != nil {
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -34,6 +35,7 @@ type options struct {
 	validate            bool
 	validateOnly        bool
 	maxConcurrency      int
+	rotate              bool
 
 	config          secretgenerator.Config
 	bootstrapConfig secretbootstrap.Config
@@ -50,6 +52,7 @@ func parseOptions(censor *secrets.DynamicCensor) options {
 	fs.StringVar(&o.outputFile, "output-file", "", "output file for dry-run mode")
 	fs.StringVar(&o.logLevel, "log-level", "info", fmt.Sprintf("Log level is one of %v.", logrus.AllLevels))
 	fs.IntVar(&o.maxConcurrency, "concurrency", 1, "Maximum number of concurrent in-flight goroutines to BitWarden.")
+	fs.BoolVar(&o.rotate, "rotate", false, "If set, only generate the fields, attachments and passwords of items that do not exist yet or are older than the max_age of their item, and record their rotation in the item notes.")
 	o.secrets.Bind(fs, os.Getenv, censor)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		logrus.WithError(err).Errorf("cannot parse args: %q", os.Args[1:])
@@ -63,7 +66,7 @@ func (o *options) validateOptions() error {
 		return fmt.Errorf("invalid log level specified: %w", err)
 	}
 	logrus.SetLevel(level)
-	if !o.dryRun {
+	if !o.dryRun || o.rotate {
 		if err := o.secrets.Validate(); err != nil {
 			return err
 		}
@@ -121,6 +124,9 @@ func (o *options) validateConfig() error {
 				return cmdEmptyErr(i, attachmentIndex, "attachments")
 			}
 		}
+		if item.MaxAge != nil && item.MaxAge.Duration <= 0 {
+			return fmt.Errorf("config[%d].max_age: must be positive", i)
+		}
 		for paramName, params := range item.Params {
 			if len(params) == 0 {
 				return fmt.Errorf("at least one argument required for param: %s, itemName: %s", paramName, item.ItemName)
@@ -292,14 +298,181 @@ func generateSecrets(o options, censor *secrets.DynamicCensor) (errs []error) {
 		}
 	}
 
+	config := o.config
+	if o.rotate {
+		// the dry-run client cannot read the items, so their age is determined from the secret store
+		var reader secrets.ReadOnlyClient = client
+		if o.dryRun {
+			var err error
+			reader, err = o.secrets.NewReadOnlyClient(censor)
+			if err != nil {
+				return append(errs, fmt.Errorf("failed to create read-only client: %w", err))
+			}
+		}
+		var err error
+		config, err = itemsToRotate(o.config, reader, time.Now())
+		if err != nil {
+			return append(errs, fmt.Errorf("failed to determine the items to rotate: %w", err))
+		}
+	}
+
 	// Upload the output to bitwarden
-	if err := updateSecrets(o.config, client); err != nil {
+	if err := updateSecrets(config, client); err != nil {
 		errs = append(errs, fmt.Errorf("failed to update secrets: %w", err))
 	}
 
 	return errs
 }
 
+// rotationNotePrefix starts the lines in the notes of an item that record the
+// last rotation of its fields, attachments and password
+const rotationNotePrefix = "Rotated by ci-secret-generator at "
+
+// rotationTargetSeparator separates the time of a rotation from its target.
+// Records without a target were written when whole items were rotated.
+const rotationTargetSeparator = " for "
+
+func fieldTarget(name string) string {
+	return "field " + name
+}
+
+func attachmentTarget(name string) string {
+	return "attachment " + name
+}
+
+const passwordTarget = "password"
+
+// itemsToRotate returns the items that do not exist yet or that have fields,
+// attachments or a password older than their maximum age. Only the parts that
+// need to be generated are kept in the returned items, and the time of their
+// rotation is recorded in the notes.
+func itemsToRotate(config secretgenerator.Config, client secrets.ReadOnlyClient, now time.Time) (secretgenerator.Config, error) {
+	existing, err := client.GetInUseInformationForAllItems("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the items from the secret store: %w", err)
+	}
+	var rotate secretgenerator.Config
+	for _, item := range config {
+		logger := logrus.WithField("item", item.ItemName)
+		var notes []string
+		rotated := map[string]time.Time{}
+		current, exists := existing[item.ItemName]
+		if exists {
+			if currentNotes, err := client.GetFieldOnItem(item.ItemName, "notes"); err == nil {
+				notes, rotated = parseRotations(string(currentNotes))
+			}
+		} else {
+			logger.Info("Item does not exist yet and will be generated")
+		}
+		_, legacy := rotated[""]
+		perTarget := len(rotated) > 0 && !(legacy && len(rotated) == 1)
+		// expired determines whether the target needs to be generated and records its rotation if so
+		expired := func(target string) bool {
+			if !exists {
+				rotated[target] = now
+				return true
+			}
+			lastRotated, recorded := rotated[target]
+			switch {
+			case recorded:
+			case perTarget:
+				// the target was added to the item after its other targets were generated
+				logger.WithField("target", target).Info("Target does not exist yet and will be generated")
+				rotated[target] = now
+				return true
+			case legacy:
+				lastRotated = rotated[""]
+			default:
+				lastRotated = current.LastChanged()
+			}
+			if !item.Expired(lastRotated, now) {
+				rotated[target] = lastRotated
+				return false
+			}
+			logger.WithFields(logrus.Fields{
+				"target":       target,
+				"last_rotated": lastRotated,
+				"max_age":      item.MaxAge.Duration.String(),
+			}).Info("Target expired and will be rotated")
+			rotated[target] = now
+			return true
+		}
+
+		var targets []string
+		generate := item
+		generate.Fields, generate.Attachments, generate.Password = nil, nil, ""
+		for _, field := range item.Fields {
+			targets = append(targets, fieldTarget(field.Name))
+			if expired(fieldTarget(field.Name)) {
+				generate.Fields = append(generate.Fields, field)
+			}
+		}
+		for _, attachment := range item.Attachments {
+			targets = append(targets, attachmentTarget(attachment.Name))
+			if expired(attachmentTarget(attachment.Name)) {
+				generate.Attachments = append(generate.Attachments, attachment)
+			}
+		}
+		if item.Password != "" {
+			targets = append(targets, passwordTarget)
+			if expired(passwordTarget) {
+				generate.Password = item.Password
+			}
+		}
+		if exists && len(generate.Fields) == 0 && len(generate.Attachments) == 0 && generate.Password == "" {
+			logger.Debug("Item has not expired yet")
+			continue
+		}
+
+		if item.Notes != "" {
+			// notes in the configuration replace the ones that were added manually
+			notes = strings.Split(strings.TrimRight(item.Notes, "\n"), "\n")
+		}
+		generate.Notes = recordRotations(notes, targets, rotated)
+		rotate = append(rotate, generate)
+	}
+	return rotate, nil
+}
+
+// parseRotations splits the notes into the lines that do not record rotations
+// and the times of the last rotation of every target
+func parseRotations(notes string) ([]string, map[string]time.Time) {
+	var lines []string
+	rotated := map[string]time.Time{}
+	if notes = strings.TrimRight(notes, "\n"); notes == "" {
+		return nil, rotated
+	}
+	for _, line := range strings.Split(notes, "\n") {
+		if !strings.HasPrefix(line, rotationNotePrefix) {
+			lines = append(lines, line)
+			continue
+		}
+		record := strings.SplitN(strings.TrimPrefix(line, rotationNotePrefix), rotationTargetSeparator, 2)
+		at, err := time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			// not a record we wrote, keep it as it is
+			lines = append(lines, line)
+			continue
+		}
+		var target string
+		if len(record) == 2 {
+			target = record[1]
+		}
+		rotated[target] = at
+	}
+	return lines, rotated
+}
+
+// recordRotations appends the times of the last rotation of the targets to the
+// notes. Records of targets that are no longer configured are dropped.
+func recordRotations(notes, targets []string, rotated map[string]time.Time) string {
+	lines := append([]string{}, notes...)
+	for _, target := range targets {
+		lines = append(lines, rotationNotePrefix+rotated[target].UTC().Format(time.RFC3339)+rotationTargetSeparator+target)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func bitwardenContextsFor(items secretgenerator.Config) []secretbootstrap.BitWardenContext {
 	var bitWardenContexts []secretbootstrap.BitWardenContext
 	for _, bwItem := range items {
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/openshift/ci-tools/pkg/api/secretbootstrap"
 	"github.com/openshift/ci-tools/pkg/api/secretgenerator"
 	"github.com/openshift/ci-tools/pkg/secrets"
@@ -236,3 +241,111 @@ func TestValidateContexts(t *testing.T) {
 		})
 	}
 }
+
+type fakeUsageComparer struct {
+	secrets.SecretUsageComparer
+	lastChanged time.Time
+}
+
+func (c fakeUsageComparer) LastChanged() time.Time {
+	return c.lastChanged
+}
+
+type fakeReadOnlyClient struct {
+	secrets.ReadOnlyClient
+	items map[string]secrets.SecretUsageComparer
+	notes map[string]string
+}
+
+func (c fakeReadOnlyClient) GetInUseInformationForAllItems(_ string) (map[string]secrets.SecretUsageComparer, error) {
+	return c.items, nil
+}
+
+func (c fakeReadOnlyClient) GetFieldOnItem(itemName, fieldName string) ([]byte, error) {
+	if notes, ok := c.notes[itemName]; ok && fieldName == "notes" {
+		return []byte(notes), nil
+	}
+	return nil, fmt.Errorf("item %s has no field %s", itemName, fieldName)
+}
+
+func TestItemsToRotate(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	maxAge := &metav1.Duration{Duration: 30 * 24 * time.Hour}
+	record := func(at, target string) string {
+		return rotationNotePrefix + at + rotationTargetSeparator + target
+	}
+	field := func(name string) secretgenerator.FieldGenerator {
+		return secretgenerator.FieldGenerator{Name: name, Cmd: "generate-" + name}
+	}
+	client := fakeReadOnlyClient{
+		items: map[string]secrets.SecretUsageComparer{
+			"expired":             fakeUsageComparer{lastChanged: now.AddDate(0, -2, 0)},
+			"expired-with-notes":  fakeUsageComparer{lastChanged: now.AddDate(0, -2, 0)},
+			"fresh":               fakeUsageComparer{lastChanged: now.AddDate(0, 0, -1)},
+			"without-max-age":     fakeUsageComparer{lastChanged: now.AddDate(-1, 0, 0)},
+			"expired-with-config": fakeUsageComparer{lastChanged: now.AddDate(0, -2, 0)},
+			"partially-expired":   fakeUsageComparer{lastChanged: now.AddDate(0, 0, -1)},
+			"new-field":           fakeUsageComparer{lastChanged: now.AddDate(0, 0, -1)},
+		},
+		notes: map[string]string{
+			"expired-with-notes": "Owned by DPTP\n" + rotationNotePrefix + "2021-03-01T00:00:00Z\n",
+			"partially-expired": strings.Join([]string{
+				record("2021-03-01T00:00:00Z", fieldTarget("old")),
+				record("2021-05-20T00:00:00Z", fieldTarget("recent")),
+				record("2021-03-01T00:00:00Z", passwordTarget),
+				record("2021-03-01T00:00:00Z", fieldTarget("removed")),
+			}, "\n"),
+			"new-field": record("2021-05-20T00:00:00Z", fieldTarget("existing")),
+		},
+	}
+	config := secretgenerator.Config{
+		{ItemName: "expired", MaxAge: maxAge, Fields: []secretgenerator.FieldGenerator{field("token")}},
+		{ItemName: "expired-with-notes", MaxAge: maxAge, Fields: []secretgenerator.FieldGenerator{field("token")}},
+		{ItemName: "expired-with-config", MaxAge: maxAge, Notes: "Configured notes", Fields: []secretgenerator.FieldGenerator{field("token")}},
+		{ItemName: "fresh", MaxAge: maxAge, Fields: []secretgenerator.FieldGenerator{field("token")}},
+		{ItemName: "without-max-age", Fields: []secretgenerator.FieldGenerator{field("token")}},
+		{ItemName: "new", Fields: []secretgenerator.FieldGenerator{field("token")}},
+		{
+			ItemName:    "partially-expired",
+			MaxAge:      maxAge,
+			Fields:      []secretgenerator.FieldGenerator{field("old"), field("recent")},
+			Attachments: []secretgenerator.FieldGenerator{field("never-recorded")},
+			Password:    "generate-password",
+		},
+		{ItemName: "new-field", Fields: []secretgenerator.FieldGenerator{field("existing"), field("added")}},
+	}
+	expected := secretgenerator.Config{
+		{ItemName: "expired", MaxAge: maxAge, Fields: []secretgenerator.FieldGenerator{field("token")}, Notes: record("2021-06-01T00:00:00Z", fieldTarget("token"))},
+		{ItemName: "expired-with-notes", MaxAge: maxAge, Fields: []secretgenerator.FieldGenerator{field("token")}, Notes: "Owned by DPTP\n" + record("2021-06-01T00:00:00Z", fieldTarget("token"))},
+		{ItemName: "expired-with-config", MaxAge: maxAge, Fields: []secretgenerator.FieldGenerator{field("token")}, Notes: "Configured notes\n" + record("2021-06-01T00:00:00Z", fieldTarget("token"))},
+		{ItemName: "new", Fields: []secretgenerator.FieldGenerator{field("token")}, Notes: record("2021-06-01T00:00:00Z", fieldTarget("token"))},
+		{
+			ItemName:    "partially-expired",
+			MaxAge:      maxAge,
+			Fields:      []secretgenerator.FieldGenerator{field("old")},
+			Attachments: []secretgenerator.FieldGenerator{field("never-recorded")},
+			Password:    "generate-password",
+			Notes: strings.Join([]string{
+				record("2021-06-01T00:00:00Z", fieldTarget("old")),
+				record("2021-05-20T00:00:00Z", fieldTarget("recent")),
+				record("2021-06-01T00:00:00Z", attachmentTarget("never-recorded")),
+				record("2021-06-01T00:00:00Z", passwordTarget),
+			}, "\n"),
+		},
+		{
+			ItemName: "new-field",
+			Fields:   []secretgenerator.FieldGenerator{field("added")},
+			Notes: strings.Join([]string{
+				record("2021-05-20T00:00:00Z", fieldTarget("existing")),
+				record("2021-06-01T00:00:00Z", fieldTarget("added")),
+			}, "\n"),
+		},
+	}
+	actual, err := itemsToRotate(config, client, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected items to rotate: %s", diff)
+	}
+}
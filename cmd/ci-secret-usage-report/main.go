@@ -0,0 +1,389 @@
+// ci-secret-usage-report cross-references the items in Vault with the
+// ci-secret-bootstrap configuration and with the secrets that ci-operator
+// configurations and step registry components mount, and reports the items and
+// fields that nothing uses, the ones most likely to be safe to delete first.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/logrusutil"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/secretbootstrap"
+	vaultapi "github.com/openshift/ci-tools/pkg/api/vault"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/secrets"
+)
+
+const (
+	// jobNamespace is where the secrets listed in `secrets` of tests are
+	// mounted from into the Prow jobs
+	jobNamespace = "ci"
+)
+
+type options struct {
+	secrets secrets.CLIOptions
+
+	bootstrapConfigPath string
+	configPath          string
+	registryPath        string
+	collectionPrefix    string
+	mountOnlyNamespaces flagutil.Strings
+	output              string
+}
+
+func gatherOptions(censor *secrets.DynamicCensor) (options, error) {
+	o := options{mountOnlyNamespaces: flagutil.NewStrings("test-credentials")}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.bootstrapConfigPath, "bootstrap-config", "", "Path to the ci-secret-bootstrap config file.")
+	fs.StringVar(&o.configPath, "config", "", "Path to the ci-operator configuration directory.")
+	fs.StringVar(&o.registryPath, "registry", "", "Path to the step registry directory.")
+	fs.StringVar(&o.collectionPrefix, "collection-prefix", "selfservice", "Directory in Vault under which the secret collections are stored.")
+	fs.Var(&o.mountOnlyNamespaces, "mount-only-namespace", "Namespace whose secrets are only used by jobs that mount them. Items that are only synced into secrets in these namespaces that nothing mounts are reported. Can be passed multiple times.")
+	fs.StringVar(&o.output, "output", "text", "Output format, either 'text' or 'json'.")
+	o.secrets.Bind(fs, os.Getenv, censor)
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return options{}, fmt.Errorf("could not parse input: %w", err)
+	}
+	return o, nil
+}
+
+func (o *options) validate() error {
+	if o.bootstrapConfigPath == "" {
+		return errors.New("--bootstrap-config is required")
+	}
+	if o.configPath == "" {
+		return errors.New("--config is required")
+	}
+	if o.registryPath == "" {
+		return errors.New("--registry is required")
+	}
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("invalid --output %q, expected 'text' or 'json'", o.output)
+	}
+	return o.secrets.Validate()
+}
+
+// priority orders the findings by how likely it is that the item or fields are
+// safe to delete
+type priority string
+
+const (
+	// priorityHigh items are not referenced by anything
+	priorityHigh priority = "high"
+	// priorityMedium items are only synced into secrets that nothing mounts
+	priorityMedium priority = "medium"
+	// priorityLow findings are unused fields of items that are used otherwise
+	priorityLow priority = "low"
+)
+
+var priorityRank = map[priority]int{priorityHigh: 0, priorityMedium: 1, priorityLow: 2}
+
+// finding is an item or fields of an item that nothing uses
+type finding struct {
+	Priority priority `json:"priority"`
+	Item     string   `json:"item"`
+	// Fields are set when only some fields of the item are unused
+	Fields []string `json:"fields,omitempty"`
+	// Owner is the secret collection of the item, or the top-level
+	// directory for items outside of secret collections
+	Owner       string    `json:"owner"`
+	LastChanged time.Time `json:"last_changed"`
+	Reason      string    `json:"reason"`
+}
+
+// usage records what references the items in Vault
+type usage struct {
+	// fields holds the fields of the items that the ci-secret-bootstrap config
+	// references
+	fields map[string]sets.String
+	// synced holds the items that the user secret sync copies into clusters
+	synced sets.String
+	// targets holds the secrets each item is copied into, as namespace/name
+	targets map[string]sets.String
+	// mounted holds the secrets that jobs mount, as namespace/name
+	mounted sets.String
+}
+
+func newUsage() *usage {
+	return &usage{fields: map[string]sets.String{}, synced: sets.NewString(), targets: map[string]sets.String{}, mounted: sets.NewString()}
+}
+
+func (u *usage) addTarget(item string, target types.NamespacedName) {
+	if u.targets[item] == nil {
+		u.targets[item] = sets.NewString()
+	}
+	u.targets[item].Insert(target.String())
+}
+
+func (u *usage) addField(item string, fields ...string) {
+	if u.fields[item] == nil {
+		u.fields[item] = sets.NewString()
+	}
+	for _, field := range fields {
+		if field != "" {
+			u.fields[item].Insert(field)
+		}
+	}
+}
+
+// addBootstrapConfig records the fields referenced by the ci-secret-bootstrap
+// config and the secrets they are copied into
+func (u *usage) addBootstrapConfig(config secretbootstrap.Config) {
+	for _, secretConfig := range config.Secrets {
+		items := sets.NewString()
+		for _, bwContext := range secretConfig.From {
+			if bwContext.BWItem != "" {
+				var password string
+				if bwContext.Attribute == secretbootstrap.AttributeTypePassword {
+					password = string(secretbootstrap.AttributeTypePassword)
+				}
+				u.addField(bwContext.BWItem, bwContext.Field, bwContext.Attachment, password)
+				items.Insert(bwContext.BWItem)
+			}
+			for _, data := range bwContext.DockerConfigJSONData {
+				u.addField(data.BWItem, data.RegistryURLBitwardenField, data.AuthBitwardenAttachment, data.EmailBitwardenField)
+				items.Insert(data.BWItem)
+			}
+		}
+		for _, item := range items.List() {
+			for _, to := range secretConfig.To {
+				u.addTarget(item, types.NamespacedName{Namespace: to.Namespace, Name: to.Name})
+			}
+		}
+	}
+}
+
+// addUserSecrets records the items the user secret sync copies into secrets
+func (u *usage) addUserSecrets(userSecrets map[types.NamespacedName]map[string]string, prefix string) {
+	for target, data := range userSecrets {
+		item := strings.TrimPrefix(data[vaultapi.VaultSourceKey], prefix+"/")
+		u.synced.Insert(item)
+		u.addTarget(item, target)
+	}
+}
+
+func (u *usage) addCredentials(credentials []api.CredentialReference) {
+	for _, credential := range credentials {
+		u.mounted.Insert(types.NamespacedName{Namespace: credential.Namespace, Name: credential.Name}.String())
+	}
+}
+
+func (u *usage) addLiteralSteps(steps []api.LiteralTestStep) {
+	for _, step := range steps {
+		u.addCredentials(step.Credentials)
+	}
+}
+
+// addMounts records the secrets mounted by the step registry components and by
+// the tests in ci-operator configurations
+func (u *usage) addMounts(references registry.ReferenceByName, configs []api.ReleaseBuildConfiguration) {
+	for _, reference := range references {
+		u.addCredentials(reference.Credentials)
+	}
+	for _, config := range configs {
+		for _, test := range config.Tests {
+			if test.Secret != nil {
+				u.mounted.Insert(types.NamespacedName{Namespace: jobNamespace, Name: test.Secret.Name}.String())
+			}
+			for _, secret := range test.Secrets {
+				u.mounted.Insert(types.NamespacedName{Namespace: jobNamespace, Name: secret.Name}.String())
+			}
+			if multiStage := test.MultiStageTestConfiguration; multiStage != nil {
+				for _, steps := range [][]api.TestStep{multiStage.Pre, multiStage.Test, multiStage.Post} {
+					for _, step := range steps {
+						if step.LiteralTestStep != nil {
+							u.addCredentials(step.LiteralTestStep.Credentials)
+						}
+					}
+				}
+			}
+			if literal := test.MultiStageTestConfigurationLiteral; literal != nil {
+				u.addLiteralSteps(literal.Pre)
+				u.addLiteralSteps(literal.Test)
+				u.addLiteralSteps(literal.Post)
+			}
+		}
+	}
+}
+
+// ownerOf returns the secret collection an item belongs to, or its top-level
+// directory if it is not part of a collection
+func ownerOf(item, collectionPrefix string) string {
+	segments := strings.Split(item, "/")
+	if len(segments) > 2 && segments[0] == collectionPrefix {
+		return segments[1]
+	}
+	return segments[0]
+}
+
+// isBookkeeping determines whether fields are managed by the tooling instead
+// of holding secret data
+func isBookkeeping(field string) bool {
+	return field == "notes" || strings.HasPrefix(field, "secretsync/")
+}
+
+// report cross-references the items in Vault with their usage
+func report(items map[string]secrets.SecretUsageComparer, u *usage, collectionPrefix string, mountOnlyNamespaces sets.String) []finding {
+	var findings []finding
+	for name, item := range items {
+		owner := ownerOf(name, collectionPrefix)
+		if strings.HasPrefix(name, collectionPrefix+"/") && path.Base(name) == "index" {
+			// created by the secret collection manager to make collections visible
+			continue
+		}
+		newFinding := func(p priority, reason string) finding {
+			return finding{Priority: p, Item: name, Owner: owner, LastChanged: item.LastChanged(), Reason: reason}
+		}
+
+		fields, referenced := u.fields[name]
+		if !referenced && !u.synced.Has(name) {
+			findings = append(findings, newFinding(priorityHigh, "not referenced by the ci-secret-bootstrap config and not synced by the user secret sync"))
+			continue
+		}
+
+		if targets := u.targets[name]; targets.Len() > 0 {
+			unmounted := true
+			for _, target := range targets.List() {
+				namespace := strings.SplitN(target, "/", 2)[0]
+				if !mountOnlyNamespaces.Has(namespace) || u.mounted.Has(target) {
+					unmounted = false
+					break
+				}
+			}
+			if unmounted {
+				findings = append(findings, newFinding(priorityMedium, fmt.Sprintf("only copied into secrets that no ci-operator configuration or step registry component mounts: %s", strings.Join(targets.List(), ", "))))
+				continue
+			}
+		}
+
+		if referenced && !u.synced.Has(name) {
+			// all fields of synced items are copied, so they are all used
+			item.UnusedFields(fields)
+			var unused []string
+			for _, field := range item.SuperfluousFields().List() {
+				if !isBookkeeping(field) {
+					unused = append(unused, field)
+				}
+			}
+			if len(unused) > 0 {
+				f := newFinding(priorityLow, "fields not referenced by the ci-secret-bootstrap config")
+				f.Fields = unused
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Priority != findings[j].Priority {
+			return priorityRank[findings[i].Priority] < priorityRank[findings[j].Priority]
+		}
+		if !findings[i].LastChanged.Equal(findings[j].LastChanged) {
+			return findings[i].LastChanged.Before(findings[j].LastChanged)
+		}
+		return findings[i].Item < findings[j].Item
+	})
+	return findings
+}
+
+func printReport(out io.Writer, findings []finding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(out, "All items are used.")
+		return
+	}
+	var current priority
+	for _, f := range findings {
+		if f.Priority != current {
+			if current != "" {
+				fmt.Fprintln(out)
+			}
+			current = f.Priority
+			fmt.Fprintf(out, "Priority %s:\n", current)
+		}
+		name := f.Item
+		if len(f.Fields) > 0 {
+			name = fmt.Sprintf("%s (fields: %s)", f.Item, strings.Join(f.Fields, ", "))
+		}
+		fmt.Fprintf(out, "  %s\n    owner: %s, last changed: %s\n    %s\n", name, f.Owner, f.LastChanged.Format(time.RFC3339), f.Reason)
+	}
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	censor := secrets.NewDynamicCensor()
+	logrus.SetFormatter(logrusutil.NewFormatterWithCensor(logrus.StandardLogger().Formatter, &censor))
+	o, err := gatherOptions(&censor)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to gather options.")
+	}
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options.")
+	}
+	if err := o.secrets.Complete(&censor); err != nil {
+		logrus.WithError(err).Fatal("Failed to complete options.")
+	}
+
+	var bootstrapConfig secretbootstrap.Config
+	if err := secretbootstrap.LoadConfigFromFile(o.bootstrapConfigPath, &bootstrapConfig); err != nil {
+		logrus.WithError(err).Fatal("Failed to load the ci-secret-bootstrap config.")
+	}
+	references, _, _, _, _, _, _, err := load.Registry(o.registryPath, false)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load the step registry.")
+	}
+	byOrgRepo, err := load.FromPathByOrgRepo(o.configPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load ci-operator configurations.")
+	}
+	var configs []api.ReleaseBuildConfiguration
+	for _, byRepo := range byOrgRepo {
+		for _, repoConfigs := range byRepo {
+			configs = append(configs, repoConfigs...)
+		}
+	}
+
+	client, err := o.secrets.NewReadOnlyClient(&censor)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create client.")
+	}
+	items, err := client.GetInUseInformationForAllItems("")
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to get the items from Vault.")
+	}
+	userSecrets, err := client.GetUserSecrets()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to get the user secrets from Vault.")
+	}
+
+	u := newUsage()
+	u.addBootstrapConfig(bootstrapConfig)
+	u.addUserSecrets(userSecrets, o.secrets.VaultPrefix)
+	u.addMounts(references, configs)
+	findings := report(items, u, o.collectionPrefix, o.mountOnlyNamespaces.StringSet())
+
+	if o.output == "json" {
+		raw, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to marshal the report.")
+		}
+		fmt.Println(string(raw))
+		return
+	}
+	printReport(os.Stdout, findings)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/secretbootstrap"
+	vaultapi "github.com/openshift/ci-tools/pkg/api/vault"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/secrets"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+type fakeItem struct {
+	secrets.SecretUsageComparer
+	lastChanged time.Time
+	fields      sets.String
+	inUse       sets.String
+}
+
+func (f *fakeItem) LastChanged() time.Time {
+	return f.lastChanged
+}
+
+func (f *fakeItem) UnusedFields(inUse sets.String) sets.String {
+	f.inUse = inUse
+	return inUse.Difference(f.fields)
+}
+
+func (f *fakeItem) SuperfluousFields() sets.String {
+	return f.fields.Difference(f.inUse)
+}
+
+var (
+	old    = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent = time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func item(lastChanged time.Time, fields ...string) secrets.SecretUsageComparer {
+	return &fakeItem{lastChanged: lastChanged, fields: sets.NewString(fields...)}
+}
+
+func TestReport(t *testing.T) {
+	items := map[string]secrets.SecretUsageComparer{
+		"dptp/used":                         item(old, "token"),
+		"dptp/partially-used":               item(old, "token", "old-token", "notes"),
+		"dptp/password":                     item(old, "password"),
+		"dptp/orphaned":                     item(recent, "token"),
+		"dptp/older-orphaned":               item(old, "token"),
+		"dptp/unmounted-credentials":        item(old, "token"),
+		"dptp/mounted-credentials":          item(old, "token"),
+		"selfservice/team/synced":           item(old, "token", "secretsync/target-name", "secretsync/target-namespace"),
+		"selfservice/team/unmounted-synced": item(recent, "token", "secretsync/target-name", "secretsync/target-namespace"),
+		"selfservice/team/index":            item(old, "."),
+		"selfservice/team/forgotten":        item(old, "token"),
+	}
+	bootstrapConfig := secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{
+		{
+			From: map[string]secretbootstrap.BitWardenContext{
+				"token":    {BWItem: "dptp/used", Field: "token"},
+				"partial":  {BWItem: "dptp/partially-used", Field: "token"},
+				"password": {BWItem: "dptp/password", Attribute: secretbootstrap.AttributeTypePassword},
+			},
+			To: []secretbootstrap.SecretContext{{Cluster: "build01", Namespace: "ci", Name: "infra"}},
+		},
+		{
+			From: map[string]secretbootstrap.BitWardenContext{"token": {BWItem: "dptp/unmounted-credentials", Field: "token"}},
+			To:   []secretbootstrap.SecretContext{{Cluster: "build01", Namespace: "test-credentials", Name: "unmounted"}},
+		},
+		{
+			From: map[string]secretbootstrap.BitWardenContext{"token": {BWItem: "dptp/mounted-credentials", Field: "token"}},
+			To: []secretbootstrap.SecretContext{
+				{Cluster: "build01", Namespace: "test-credentials", Name: "mounted-by-step"},
+				{Cluster: "build01", Namespace: "test-credentials", Name: "unmounted-copy"},
+			},
+		},
+	}}
+	userSecrets := map[types.NamespacedName]map[string]string{
+		{Namespace: "test-credentials", Name: "synced"}:   {vaultapi.VaultSourceKey: "kv/selfservice/team/synced", "token": "secret"},
+		{Namespace: "test-credentials", Name: "unsynced"}: {vaultapi.VaultSourceKey: "kv/selfservice/team/unmounted-synced", "token": "secret"},
+	}
+	references := registry.ReferenceByName{
+		"step": {As: "step", Credentials: []api.CredentialReference{{Namespace: "test-credentials", Name: "mounted-by-step"}}},
+	}
+	configs := []api.ReleaseBuildConfiguration{{Tests: []api.TestStepConfiguration{{
+		As: "e2e",
+		MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{As: "literal", Credentials: []api.CredentialReference{{Namespace: "test-credentials", Name: "synced"}}},
+		}}},
+	}}}}
+
+	u := newUsage()
+	u.addBootstrapConfig(bootstrapConfig)
+	u.addUserSecrets(userSecrets, "kv")
+	u.addMounts(references, configs)
+
+	expected := []finding{
+		{Priority: priorityHigh, Item: "dptp/older-orphaned", Owner: "dptp", LastChanged: old, Reason: "not referenced by the ci-secret-bootstrap config and not synced by the user secret sync"},
+		{Priority: priorityHigh, Item: "selfservice/team/forgotten", Owner: "team", LastChanged: old, Reason: "not referenced by the ci-secret-bootstrap config and not synced by the user secret sync"},
+		{Priority: priorityHigh, Item: "dptp/orphaned", Owner: "dptp", LastChanged: recent, Reason: "not referenced by the ci-secret-bootstrap config and not synced by the user secret sync"},
+		{Priority: priorityMedium, Item: "dptp/unmounted-credentials", Owner: "dptp", LastChanged: old, Reason: "only copied into secrets that no ci-operator configuration or step registry component mounts: test-credentials/unmounted"},
+		{Priority: priorityMedium, Item: "selfservice/team/unmounted-synced", Owner: "team", LastChanged: recent, Reason: "only copied into secrets that no ci-operator configuration or step registry component mounts: test-credentials/unsynced"},
+		{Priority: priorityLow, Item: "dptp/partially-used", Fields: []string{"old-token"}, Owner: "dptp", LastChanged: old, Reason: "fields not referenced by the ci-secret-bootstrap config"},
+	}
+	actual := report(items, u, "selfservice", sets.NewString("test-credentials"))
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected findings: %s", diff)
+	}
+
+	var out bytes.Buffer
+	printReport(&out, actual)
+	testhelper.CompareWithFixture(t, out.String())
+}
+
+func TestOwnerOf(t *testing.T) {
+	for item, expected := range map[string]string{
+		"dptp/item":                "dptp",
+		"selfservice/team/item":    "team",
+		"selfservice/team/sub/dir": "team",
+		"selfservice/item":         "selfservice",
+		"item":                     "item",
+	} {
+		if actual := ownerOf(item, "selfservice"); actual != expected {
+			t.Errorf("%s: expected owner %q, got %q", item, expected, actual)
+		}
+	}
+}
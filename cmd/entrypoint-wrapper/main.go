@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"flag"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreclientset "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/util"
@@ -68,6 +70,8 @@ type options struct {
 	dstPath string
 	cmd     []string
 	client  coreclientset.SecretInterface
+	// base is the content of the shared directory when the step started
+	base map[string][]byte
 }
 
 func bindOptions(flag *flag.FlagSet) *options {
@@ -105,9 +109,14 @@ func (o *options) run() error {
 	if err := copyDir(o.dstPath, o.srcPath); err != nil {
 		return fmt.Errorf("failed to copy secret mount: %w", err)
 	}
+	base, err := util.SecretFromDir(o.dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to read secret mount: %w", err)
+	}
+	o.base = base.Data
 	var errs []error
 	ctx, cancel := context.WithCancel(context.Background())
-	go uploadKubeconfig(ctx, o.client, o.name, o.dstPath, o.dry)
+	go uploadKubeconfig(ctx, o.client, o.name, o.dstPath, o.base, o.dry)
 	if err := execCmd(o.cmd); err != nil {
 		errs = append(errs, fmt.Errorf("failed to execute wrapped command: %w", err))
 	}
@@ -115,7 +124,7 @@ func (o *options) run() error {
 	// that the best-effort upload of the kubeconfig can exit now and so as
 	// not to race with the post-execution one
 	cancel()
-	if err := createSecret(o.client, o.name, o.dstPath, o.dry); err != nil {
+	if err := createSecret(o.client, o.name, o.dstPath, o.base, o.dry); err != nil {
 		errs = append(errs, fmt.Errorf("failed to create/update secret: %w", err))
 	}
 	return utilerrors.NewAggregate(errs)
@@ -269,7 +278,11 @@ func manageKubeconfig(proc *exec.Cmd) error {
 	return nil
 }
 
-func createSecret(client coreclientset.SecretInterface, name, dir string, dry bool) error {
+// createSecret uploads the changes the step made to the shared directory since
+// it started, with base holding the content at that time. Other steps may be
+// executed in parallel, so only the files the step created, changed or
+// removed are updated in the secret, and the update is retried on conflicts.
+func createSecret(client coreclientset.SecretInterface, name, dir string, base map[string][]byte, dry bool) error {
 	if _, err := os.Stat(dir); err != nil {
 		if os.IsNotExist(err) {
 			return nil
@@ -290,16 +303,51 @@ func createSecret(client coreclientset.SecretInterface, name, dir string, dry bo
 		if err != nil {
 			return fmt.Errorf("failed to log secret: %w", err)
 		}
-	} else if _, err := client.Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		return nil
+	}
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		current.Data = mergeSharedDir(current.Data, base, secret.Data)
+		if current.Labels == nil {
+			current.Labels = map[string]string{}
+		}
+		current.Labels[steps.SkipCensoringLabel] = "true"
+		_, err = client.Update(context.TODO(), current, metav1.UpdateOptions{})
+		return err
+	}); err != nil {
 		return fmt.Errorf("failed to update secret: %w", err)
 	}
 	return nil
 }
 
+// mergeSharedDir applies the changes from base to files to the current data of
+// the secret. Files that were not changed keep their current content, which
+// other steps may have updated in the meantime.
+func mergeSharedDir(current, base, files map[string][]byte) map[string][]byte {
+	merged := make(map[string][]byte, len(current))
+	for name, content := range current {
+		merged[name] = content
+	}
+	for name, content := range files {
+		if original, existed := base[name]; !existed || !bytes.Equal(original, content) {
+			merged[name] = content
+		}
+	}
+	for name := range base {
+		if _, exists := files[name]; !exists {
+			delete(merged, name)
+		}
+	}
+	return merged
+}
+
 // uploadKubeconfig will do a best-effort attempt at uploading a kubeconfig
 // file if one does not exist at the time we start running but one does get
 // created while executing the command
-func uploadKubeconfig(ctx context.Context, client coreclientset.SecretInterface, name, dir string, dry bool) {
+func uploadKubeconfig(ctx context.Context, client coreclientset.SecretInterface, name, dir string, base map[string][]byte, dry bool) {
 	if _, err := os.Stat(path.Join(dir, "kubeconfig")); err == nil {
 		// kubeconfig already exists, no need to do anything
 		return
@@ -310,7 +358,7 @@ func uploadKubeconfig(ctx context.Context, client coreclientset.SecretInterface,
 			return false, nil
 		}
 		// kubeconfig exists, we can upload it
-		uploadErr = createSecret(client, name, dir, dry)
+		uploadErr = createSecret(client, name, dir, base, dry)
 		return uploadErr == nil, nil // retry errors
 	}, ctx.Done()); !errors.Is(err, wait.ErrWaitTimeout) {
 		log.Printf("Failed to upload $KUBECONFIG: %v: %v\n", err, uploadErr)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCreateSecretMergesParallelSteps(t *testing.T) {
+	base := map[string][]byte{
+		"shared":  []byte("before"),
+		"removed": []byte("before"),
+	}
+	client := fake.NewSimpleClientset(&coreapi.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "test"},
+		Data:       base,
+	})
+	conflicts := 1
+	client.PrependReactor("update", "secrets", func(clienttesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			conflicts--
+			return true, nil, kerrors.NewConflict(schema.GroupResource{Resource: "secrets"}, "test", nil)
+		}
+		return false, nil, nil
+	})
+	secrets := client.CoreV1().Secrets("ns")
+
+	// both steps start from the same content and finish one after the other
+	writeDir := func(files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	first := writeDir(map[string]string{"shared": "before", "first": "first"})
+	second := writeDir(map[string]string{"shared": "second", "removed": "before", "second": "second"})
+	for _, dir := range []string{first, second} {
+		if err := createSecret(secrets, "test", dir, base, false); err != nil {
+			t.Fatalf("failed to create secret: %v", err)
+		}
+	}
+
+	secret, err := secrets.Get(context.TODO(), "test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]byte{
+		"shared": []byte("second"),
+		"first":  []byte("first"),
+		"second": []byte("second"),
+	}
+	if diff := cmp.Diff(expected, secret.Data); diff != "" {
+		t.Errorf("unexpected shared directory: %s", diff)
+	}
+}
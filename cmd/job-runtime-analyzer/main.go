@@ -5,16 +5,23 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"k8s.io/test-infra/prow/flagutil"
+
 	jobruntimeanalyzer "github.com/openshift/ci-tools/pkg/job-runtime-analyzer"
 )
 
 const defaultJobURL = "https://storage.googleapis.com/origin-ci-test/pr-logs/pull/openshift_ci-tools/999/pull-ci-openshift-ci-tools-master-validate-vendor/1283812971092381696"
 
 func main() {
-	jobURL := flag.String("job-url", defaultJobURL, "url to a job")
+	jobURLs := flagutil.NewStrings(defaultJobURL)
+	flag.Var(&jobURLs, "job-url", "url to a job, can be passed multiple times to compare jobs with the first one")
+	output := flag.String("output", jobruntimeanalyzer.OutputTable, "output format, either 'table' or 'json'")
 	flag.Parse()
 
-	if err := jobruntimeanalyzer.Run(*jobURL); err != nil {
+	if *output != jobruntimeanalyzer.OutputTable && *output != jobruntimeanalyzer.OutputJSON {
+		logrus.Fatalf("Invalid --output %q", *output)
+	}
+	if err := jobruntimeanalyzer.Run(jobURLs.Strings(), *output); err != nil {
 		logrus.WithError(err).Fatal("Failed")
 	}
 }
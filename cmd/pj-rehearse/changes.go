@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowconfig "k8s.io/test-infra/prow/config"
+	prowplugins "k8s.io/test-infra/prow/plugins"
+
+	apihelper "github.com/openshift/ci-tools/pkg/api/helper"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/diffs"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/rehearse"
+)
+
+// loadOptions configures which changes to the release repository are considered
+type loadOptions struct {
+	noTemplates       bool
+	noRegistry        bool
+	noClusterProfiles bool
+}
+
+// changes holds the configuration loaded from the tested revision of the release
+// repository together with the changes it introduces over the base revision
+type changes struct {
+	prConfig         *config.ReleaseRepoConfig
+	masterConfig     *config.ReleaseRepoConfig
+	configUpdaterCfg prowplugins.ConfigUpdater
+
+	changedCiopConfigData config.DataByFilename
+	affectedJobs          map[string]sets.String
+
+	changedRegistrySteps []registry.Node
+	refs                 registry.ReferenceByName
+	chains               registry.ChainByName
+	workflows            registry.WorkflowByName
+	observers            registry.ObserverByName
+	deprecations         registry.Deprecations
+
+	templates       rehearse.ConfigMaps
+	clusterProfiles rehearse.ConfigMaps
+}
+
+// loadChanges loads the configuration from the release repository checked out at
+// releaseRepoPath and determines its changes over the baseSHA revision. Errors are
+// logged and the returned error is suitable to be presented to the user.
+func loadChanges(o loadOptions, releaseRepoPath, baseSHA, buildID string, prNumber int, logger *logrus.Entry) (*changes, error) {
+	c := &changes{}
+	c.prConfig = config.GetAllConfigs(releaseRepoPath, logger)
+	var err error
+	c.configUpdaterCfg, err = loadConfigUpdaterCfg(releaseRepoPath)
+	if err != nil {
+		logger.WithError(err).Error("could not load plugin configuration from tested revision of release repo")
+		return nil, fmt.Errorf(misconfigurationOutput)
+	}
+	c.masterConfig, err = config.GetAllConfigsFromSHA(releaseRepoPath, baseSHA, logger)
+	if err != nil {
+		logger.WithError(err).Error("could not load configuration from base revision of release repo")
+		return nil, fmt.Errorf(misconfigurationOutput)
+	}
+
+	// We always need both Prow config versions, otherwise we cannot compare them
+	if c.masterConfig.Prow == nil || c.prConfig.Prow == nil {
+		logger.WithError(err).Error("could not load Prow configs from base or tested revision of release repo")
+		return nil, fmt.Errorf(misconfigurationOutput)
+	}
+	// We always need PR versions of ciop config, otherwise we cannot provide them to rehearsed jobs
+	if c.prConfig.CiOperator == nil {
+		logger.WithError(err).Error("could not load ci-operator configs from tested revision of release repo")
+		return nil, fmt.Errorf(misconfigurationOutput)
+	}
+
+	// We can only detect changes if we managed to load both ci-operator config versions
+	c.changedCiopConfigData = config.DataByFilename{}
+	c.affectedJobs = make(map[string]sets.String)
+	if c.masterConfig.CiOperator != nil && c.prConfig.CiOperator != nil {
+		c.changedCiopConfigData, c.affectedJobs = diffs.GetChangedCiopConfigs(c.masterConfig.CiOperator, c.prConfig.CiOperator, logger)
+	}
+
+	if !o.noRegistry {
+		c.refs, c.chains, c.workflows, _, _, c.observers, c.deprecations, err = load.Registry(filepath.Join(releaseRepoPath, config.RegistryPath), false)
+		if err != nil {
+			logger.WithError(err).Error("could not load step registry")
+			return nil, fmt.Errorf(misconfigurationOutput)
+		}
+		graph, err := registry.NewGraph(c.refs, c.chains, c.workflows)
+		if err != nil {
+			logger.WithError(err).Error("could not create step registry graph")
+			return nil, fmt.Errorf(misconfigurationOutput)
+		}
+		c.changedRegistrySteps, err = config.GetChangedRegistrySteps(releaseRepoPath, baseSHA, graph)
+		if err != nil {
+			logger.WithError(err).Error("could not get step registry differences")
+			return nil, fmt.Errorf(misconfigurationOutput)
+		}
+	}
+	if len(c.changedRegistrySteps) != 0 {
+		var names []string
+		for _, step := range c.changedRegistrySteps {
+			names = append(names, step.Name())
+		}
+		logger.Infof("Found %d changed registry steps: %s", len(c.changedRegistrySteps), strings.Join(names, ", "))
+	}
+
+	if !o.noTemplates {
+		changedTemplates, err := config.GetChangedTemplates(releaseRepoPath, baseSHA)
+		if err != nil {
+			logger.WithError(err).Error("could not get template differences")
+			return nil, fmt.Errorf(misconfigurationOutput)
+		}
+		c.templates, err = rehearse.NewConfigMaps(changedTemplates, "template", buildID, prNumber, c.configUpdaterCfg)
+		if err != nil {
+			logger.WithError(err).Error("could not match changed templates with cluster configmaps")
+			return nil, fmt.Errorf(misconfigurationOutput)
+		}
+
+	}
+	if len(c.templates.Paths) != 0 {
+		logger.WithField("templates", c.templates.Paths).Info("templates changed")
+	}
+
+	if !o.noClusterProfiles {
+		changedClusterProfiles, err := config.GetChangedClusterProfiles(releaseRepoPath, baseSHA)
+		if err != nil {
+			logger.WithError(err).Error("could not get cluster profile differences")
+			return nil, fmt.Errorf(misconfigurationOutput)
+		}
+		c.clusterProfiles, err = rehearse.NewConfigMaps(changedClusterProfiles, "cluster-profile", buildID, prNumber, c.configUpdaterCfg)
+		if err != nil {
+			logger.WithError(err).Error("could not match changed cluster profiles with cluster configmaps")
+			return nil, fmt.Errorf(misconfigurationOutput)
+		}
+	}
+	if len(c.clusterProfiles.Paths) != 0 {
+		logger.WithField("profiles", c.clusterProfiles.Paths).Info("cluster profiles changed")
+	}
+	return c, nil
+}
+
+// affected holds the jobs affected by the changes, both in total and by the kind
+// of change that selected them
+type affected struct {
+	presubmits config.Presubmits
+	periodics  config.Periodics
+
+	changedPresubmits        config.Presubmits
+	changedPeriodics         config.Periodics
+	presubmitsForCiopConfigs config.Presubmits
+	periodicsForCiopConfigs  config.Periodics
+}
+
+// jobsToRehearse determines the jobs that should be rehearsed to exercise the changes
+func (c *changes) jobsToRehearse(logger *logrus.Entry, loggers rehearse.Loggers) affected {
+	a := affected{presubmits: config.Presubmits{}, periodics: config.Periodics{}}
+
+	a.changedPeriodics = diffs.GetChangedPeriodics(c.masterConfig.Prow, c.prConfig.Prow, logger)
+	a.periodics.AddAll(a.changedPeriodics, config.ChangedPeriodic)
+	a.changedPresubmits = diffs.GetChangedPresubmits(c.masterConfig.Prow, c.prConfig.Prow, logger)
+	a.presubmits.AddAll(a.changedPresubmits, config.ChangedPresubmit)
+
+	a.presubmitsForCiopConfigs, a.periodicsForCiopConfigs = diffs.GetJobsForCiopConfigs(c.prConfig.Prow, c.changedCiopConfigData, c.affectedJobs, logger)
+	a.presubmits.AddAll(a.presubmitsForCiopConfigs, config.ChangedCiopConfig)
+	a.periodics.AddAll(a.periodicsForCiopConfigs, config.ChangedCiopConfig)
+
+	presubmitsForClusterProfiles := diffs.GetPresubmitsForClusterProfiles(c.prConfig.Prow, c.clusterProfiles.ProductionNames, logger)
+	a.presubmits.AddAll(presubmitsForClusterProfiles, config.ChangedClusterProfile)
+
+	randomJobsForChangedTemplates := rehearse.AddRandomJobsForChangedTemplates(c.templates.ProductionNames, a.presubmits, c.prConfig.Prow.JobConfig.PresubmitsStatic, loggers)
+	a.presubmits.AddAll(randomJobsForChangedTemplates, config.ChangedTemplate)
+
+	presubmitsForRegistry, periodicsForRegistry := rehearse.SelectJobsForChangedRegistry(c.changedRegistrySteps, c.prConfig.Prow.JobConfig.PresubmitsStatic, c.prConfig.Prow.JobConfig.Periodics, c.prConfig.CiOperator, loggers)
+	a.presubmits.AddAll(presubmitsForRegistry, config.ChangedRegistryContent)
+	a.periodics.AddAll(periodicsForRegistry, config.ChangedRegistryContent)
+	return a
+}
+
+// coverage determines which changes each of the affected jobs exercises.
+func (c *changes) coverage(a affected) rehearse.Coverage {
+	coverage := rehearse.Coverage{}
+	coverage.AddPresubmits(a.changedPresubmits, func(job prowconfig.Presubmit) string { return fmt.Sprintf("job `%s`", job.Name) })
+	coverage.AddPeriodics(a.changedPeriodics, func(job prowconfig.Periodic) string { return fmt.Sprintf("job `%s`", job.Name) })
+	coverage.AddPresubmits(a.presubmitsForCiopConfigs, func(job prowconfig.Presubmit) string {
+		return fmt.Sprintf("ci-operator configuration of `%s`", job.Name)
+	})
+	coverage.AddPeriodics(a.periodicsForCiopConfigs, func(job prowconfig.Periodic) string {
+		return fmt.Sprintf("ci-operator configuration of `%s`", job.Name)
+	})
+
+	var jobs []prowconfig.JobBase
+	candidates := sets.NewString()
+	for _, repoJobs := range a.presubmits {
+		for _, job := range repoJobs {
+			jobs = append(jobs, job.JobBase)
+			candidates.Insert(job.Name)
+		}
+	}
+	for _, job := range a.periodics {
+		jobs = append(jobs, job.JobBase)
+		candidates.Insert(job.Name)
+	}
+	coverage.AddConfigMaps(jobs, c.templates.ProductionNames, "template")
+	coverage.AddConfigMaps(jobs, c.clusterProfiles.ProductionNames, "cluster profile")
+	coverage.AddRegistryNodes(c.changedRegistrySteps, c.prConfig.CiOperator, candidates)
+	return coverage
+}
+
+// configureRehearsals creates the rehearsals of the jobs, returning the rehearsal
+// presubmits together with the imagestreamtags they require
+func (c *changes) configureRehearsals(presubmits config.Presubmits, periodics config.Periodics, prNumber int, refs *pjapi.Refs, loggers rehearse.Loggers) (apihelper.ImageStreamTagMap, []*prowconfig.Presubmit, error) {
+	resolver := registry.NewResolver(c.refs, c.chains, c.workflows, c.observers, c.deprecations)
+	jobConfigurer := rehearse.NewJobConfigurer(c.prConfig.CiOperator, resolver, prNumber, loggers, c.templates.Names, c.clusterProfiles.Names, refs)
+	imagestreamtags, presubmitsToRehearse, err := jobConfigurer.ConfigurePresubmitRehearsals(presubmits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	periodicImageStreamTags, periodicRehearsals, err := jobConfigurer.ConfigurePeriodicRehearsals(periodics)
+	if err != nil {
+		return nil, nil, err
+	}
+	apihelper.MergeImageStreamTagMaps(imagestreamtags, periodicImageStreamTags)
+
+	periodicPresubmits, err := jobConfigurer.ConvertPeriodicsToPresubmits(periodicRehearsals)
+	if err != nil {
+		return nil, nil, err
+	}
+	return imagestreamtags, append(presubmitsToRehearse, periodicPresubmits...), nil
+}
+
+// validateRehearsals adds the rehearsals to the Prow configuration and validates it
+func validateRehearsals(prowConfig *prowconfig.Config, presubmits []*prowconfig.Presubmit, org, repo string) error {
+	if prowConfig.JobConfig.PresubmitsStatic == nil {
+		prowConfig.JobConfig.PresubmitsStatic = map[string][]prowconfig.Presubmit{}
+	}
+	for _, presubmit := range presubmits {
+
+		// We can only have a given repo once, so remove whats in Refs from ExtraRefs
+		var cleanExtraRefs []pjapi.Refs
+		for _, extraRef := range presubmit.ExtraRefs {
+			if extraRef.Org == org && extraRef.Repo == repo {
+				continue
+			}
+			cleanExtraRefs = append(cleanExtraRefs, extraRef)
+		}
+		presubmit.ExtraRefs = cleanExtraRefs
+
+		prowConfig.JobConfig.PresubmitsStatic[org+"/"+repo] = append(prowConfig.JobConfig.PresubmitsStatic[org+"/"+repo], *presubmit)
+	}
+	return prowConfig.ValidateJobConfig()
+}
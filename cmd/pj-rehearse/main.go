@@ -8,7 +8,6 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
@@ -25,7 +24,13 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/config/secret"
+	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	prowgithub "k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/githubeventserver"
+	"k8s.io/test-infra/prow/interrupts"
+	"k8s.io/test-infra/prow/logrusutil"
+	"k8s.io/test-infra/prow/pjutil"
 	prowplugins "k8s.io/test-infra/prow/plugins"
 	pjdwapi "k8s.io/test-infra/prow/pod-utils/downwardapi"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,9 +40,6 @@ import (
 	apihelper "github.com/openshift/ci-tools/pkg/api/helper"
 	testimagestreamtagimportv1 "github.com/openshift/ci-tools/pkg/api/testimagestreamtagimport/v1"
 	"github.com/openshift/ci-tools/pkg/config"
-	"github.com/openshift/ci-tools/pkg/diffs"
-	"github.com/openshift/ci-tools/pkg/load"
-	"github.com/openshift/ci-tools/pkg/registry"
 	"github.com/openshift/ci-tools/pkg/rehearse"
 	"github.com/openshift/ci-tools/pkg/util"
 )
@@ -47,12 +49,19 @@ type options struct {
 	debugLogPath      string
 	prowjobKubeconfig string
 
-	noTemplates       bool
-	noRegistry        bool
-	noClusterProfiles bool
+	loadOptions
 
 	releaseRepoPath string
 	rehearsalLimit  int
+
+	rehearsalBudget float64
+	costConfigPath  string
+	github          prowflagutil.GitHubOptions
+
+	server                   bool
+	webhookSecretFile        string
+	githubEventServerOptions githubeventserver.Options
+	git                      prowflagutil.GitOptions
 }
 
 func gatherOptions() (options, error) {
@@ -65,11 +74,19 @@ func gatherOptions() (options, error) {
 	fs.StringVar(&o.releaseRepoPath, "candidate-path", "", "Path to a openshift/release working copy with a revision to be tested")
 	fs.StringVar(&o.prowjobKubeconfig, "prowjob-kubeconfig", "", "Path to the prowjob kubeconfig. If unset, default kubeconfig will be used for prowjobs.")
 
-	fs.BoolVar(&o.noTemplates, "no-templates", false, "If true, do not attempt to compare templates")
-	fs.BoolVar(&o.noRegistry, "no-registry", false, "If true, do not attempt to compare step registry content")
-	fs.BoolVar(&o.noClusterProfiles, "no-cluster-profiles", false, "If true, do not attempt to compare cluster profiles")
+	fs.BoolVar(&o.loadOptions.noTemplates, "no-templates", false, "If true, do not attempt to compare templates")
+	fs.BoolVar(&o.loadOptions.noRegistry, "no-registry", false, "If true, do not attempt to compare step registry content")
+	fs.BoolVar(&o.loadOptions.noClusterProfiles, "no-cluster-profiles", false, "If true, do not attempt to compare cluster profiles")
 
 	fs.IntVar(&o.rehearsalLimit, "rehearsal-limit", 35, "Upper limit of jobs attempted to rehearse (if more jobs are being touched, only this many will be rehearsed)")
+	fs.Float64Var(&o.rehearsalBudget, "rehearsal-budget", 0, "If set, select the rehearsals exercising the most changes within this estimated cost, and report the selection on the pull request")
+	fs.StringVar(&o.costConfigPath, "rehearsal-cost-config", "", "Path to the file with historical runtimes and lease costs used to estimate the cost of rehearsals")
+	o.github.AddFlags(fs)
+
+	fs.BoolVar(&o.server, "server", false, "If true, run as an external Prow plugin rehearsing jobs requested by /pj-rehearse commands")
+	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
+	o.git.AddFlags(fs)
+	o.githubEventServerOptions.Bind(fs)
 
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
@@ -77,10 +94,28 @@ func gatherOptions() (options, error) {
 	return o, nil
 }
 
-func validateOptions(o options) error {
+func validateOptions(o *options) error {
+	if o.server {
+		if err := o.github.Validate(o.dryRun); err != nil {
+			return err
+		}
+		if err := o.git.Validate(o.dryRun); err != nil {
+			return err
+		}
+		return o.githubEventServerOptions.DefaultAndValidate()
+	}
 	if len(o.releaseRepoPath) == 0 {
 		return fmt.Errorf("--candidate-path was not provided")
 	}
+	if o.rehearsalBudget < 0 {
+		return fmt.Errorf("--rehearsal-budget cannot be negative")
+	}
+	if o.costConfigPath != "" && o.rehearsalBudget == 0 {
+		return fmt.Errorf("--rehearsal-cost-config requires --rehearsal-budget")
+	}
+	if o.rehearsalBudget > 0 && !o.dryRun {
+		return o.github.Validate(o.dryRun)
+	}
 	return nil
 }
 
@@ -116,18 +151,8 @@ func loadConfigUpdaterCfg(releaseRepoPath string) (ret prowplugins.ConfigUpdater
 	return
 }
 
-func rehearseMain() error {
-	o, err := gatherOptions()
-	if err != nil {
-		logrus.WithError(err).Fatal("failed to gather options")
-	}
-	if err := validateOptions(o); err != nil {
-		logrus.WithError(err).Fatal("invalid options")
-	}
-	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
-		logrus.WithError(err).Fatal("failed to register imagev1 scheme")
-	}
-
+func rehearseMain(o options) error {
+	var err error
 	var jobSpec *pjdwapi.JobSpec
 	if jobSpec, err = pjdwapi.ResolveSpecFromEnv(); err != nil {
 		logrus.WithError(err).Error("could not read JOB_SPEC")
@@ -147,120 +172,35 @@ func rehearseMain() error {
 	org, repo, prNumber := jobSpec.Refs.Org, jobSpec.Refs.Repo, jobSpec.Refs.Pulls[0].Number
 	logger.Infof("Rehearsing Prow jobs for configuration PR %s/%s#%d", org, repo, prNumber)
 
-	buildClusterConfigs := map[string]*rest.Config{}
-	var prowJobConfig *rest.Config
-	if !o.dryRun {
-		// Only the env var allows to supply multiple kubeconfigs
-		if _, exists := os.LookupEnv("KUBECONFIG"); exists {
-			buildClusterConfigs, _, err = util.LoadKubeConfigs("", nil)
-			if err != nil {
-				logger.WithError(err).Error("failed to read kubeconfigs")
-				return errors.New(misconfigurationOutput)
-			}
-		}
-		prowJobConfig, err = pjKubeconfig(o.prowjobKubeconfig, buildClusterConfigs["app.ci"])
-		if err != nil {
-			logger.WithError(err).Error("Could not load prowjob kubeconfig")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-	}
-
-	prConfig := config.GetAllConfigs(o.releaseRepoPath, logger)
-	configUpdaterCfg, err := loadConfigUpdaterCfg(o.releaseRepoPath)
+	buildClusterConfigs, prowJobConfig, err := loadClusterConfigs(o)
 	if err != nil {
-		logger.WithError(err).Error("could not load plugin configuration from tested revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
-	}
-	masterConfig, err := config.GetAllConfigsFromSHA(o.releaseRepoPath, jobSpec.Refs.BaseSHA, logger)
-	if err != nil {
-		logger.WithError(err).Error("could not load configuration from base revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
+		logger.WithError(err).Error("failed to read kubeconfigs")
+		return errors.New(misconfigurationOutput)
 	}
 
-	// We always need both Prow config versions, otherwise we cannot compare them
-	if masterConfig.Prow == nil || prConfig.Prow == nil {
-		logger.WithError(err).Error("could not load Prow configs from base or tested revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
-	}
-	// We always need PR versions of ciop config, otherwise we cannot provide them to rehearsed jobs
-	if prConfig.CiOperator == nil {
-		logger.WithError(err).Error("could not load ci-operator configs from tested revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
-	}
-
-	// We can only detect changes if we managed to load both ci-operator config versions
-	changedCiopConfigData := config.DataByFilename{}
-	affectedJobs := make(map[string]sets.String)
-	if masterConfig.CiOperator != nil && prConfig.CiOperator != nil {
-		data, jobs := diffs.GetChangedCiopConfigs(masterConfig.CiOperator, prConfig.CiOperator, logger)
-		changedCiopConfigData = data
-		affectedJobs = jobs
-	}
-
-	var changedRegistrySteps []registry.Node
-	var refs registry.ReferenceByName
-	var chains registry.ChainByName
-	var workflows registry.WorkflowByName
-	var observers registry.ObserverByName
-
-	if !o.noRegistry {
-		refs, chains, workflows, _, _, observers, err = load.Registry(filepath.Join(o.releaseRepoPath, config.RegistryPath), false)
-		if err != nil {
-			logger.WithError(err).Error("could not load step registry")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-		graph, err := registry.NewGraph(refs, chains, workflows)
-		if err != nil {
-			logger.WithError(err).Error("could not create step registry graph")
+	costs := &rehearse.CostConfig{}
+	if o.costConfigPath != "" {
+		if costs, err = rehearse.LoadCostConfig(o.costConfigPath); err != nil {
+			logger.WithError(err).Error("could not load rehearsal cost configuration")
 			return fmt.Errorf(misconfigurationOutput)
 		}
-		changedRegistrySteps, err = config.GetChangedRegistrySteps(o.releaseRepoPath, jobSpec.Refs.BaseSHA, graph)
-		if err != nil {
-			logger.WithError(err).Error("could not get step registry differences")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-	}
-	if len(changedRegistrySteps) != 0 {
-		var names []string
-		for _, step := range changedRegistrySteps {
-			names = append(names, step.Name())
-		}
-		logger.Infof("Found %d changed registry steps: %s", len(changedRegistrySteps), strings.Join(names, ", "))
 	}
-
-	var rehearsalTemplates rehearse.ConfigMaps
-	if !o.noTemplates {
-		changedTemplates, err := config.GetChangedTemplates(o.releaseRepoPath, jobSpec.Refs.BaseSHA)
-		if err != nil {
-			logger.WithError(err).Error("could not get template differences")
+	var githubClient prowgithub.Client
+	if o.rehearsalBudget > 0 && !o.dryRun {
+		secretAgent := &secret.Agent{}
+		if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
+			logger.WithError(err).Error("could not start secret agent")
 			return fmt.Errorf(misconfigurationOutput)
 		}
-		rehearsalTemplates, err = rehearse.NewConfigMaps(changedTemplates, "template", jobSpec.BuildID, prNumber, configUpdaterCfg)
-		if err != nil {
-			logger.WithError(err).Error("could not match changed templates with cluster configmaps")
+		if githubClient, err = o.github.GitHubClient(secretAgent, o.dryRun); err != nil {
+			logger.WithError(err).Error("could not create GitHub client")
 			return fmt.Errorf(misconfigurationOutput)
 		}
-
-	}
-	if len(rehearsalTemplates.Paths) != 0 {
-		logger.WithField("templates", rehearsalTemplates.Paths).Info("templates changed")
 	}
 
-	var rehearsalClusterProfiles rehearse.ConfigMaps
-	if !o.noClusterProfiles {
-		changedClusterProfiles, err := config.GetChangedClusterProfiles(o.releaseRepoPath, jobSpec.Refs.BaseSHA)
-		if err != nil {
-			logger.WithError(err).Error("could not get cluster profile differences")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-		rehearsalClusterProfiles, err = rehearse.NewConfigMaps(changedClusterProfiles, "cluster-profile", jobSpec.BuildID, prNumber, configUpdaterCfg)
-		if err != nil {
-			logger.WithError(err).Error("could not match changed cluster profiles with cluster configmaps")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-	}
-	if len(rehearsalClusterProfiles.Paths) != 0 {
-		logger.WithField("profiles", rehearsalClusterProfiles.Paths).Info("cluster profiles changed")
+	c, err := loadChanges(o.loadOptions, o.releaseRepoPath, jobSpec.Refs.BaseSHA, jobSpec.BuildID, prNumber, logger)
+	if err != nil {
+		return err
 	}
 
 	pjclient, err := rehearse.NewProwJobClient(prowJobConfig, o.dryRun)
@@ -281,50 +221,38 @@ func rehearseMain() error {
 		}
 	}
 	loggers := rehearse.Loggers{Job: logger, Debug: debugLogger.WithField(prowgithub.PrLogField, prNumber)}
-	toRehearse := config.Presubmits{}
-	periodicsToRehearse := config.Periodics{}
-
-	changedPeriodics := diffs.GetChangedPeriodics(masterConfig.Prow, prConfig.Prow, logger)
-	periodicsToRehearse.AddAll(changedPeriodics, config.ChangedPeriodic)
-	changedPresubmits := diffs.GetChangedPresubmits(masterConfig.Prow, prConfig.Prow, logger)
-	toRehearse.AddAll(changedPresubmits, config.ChangedPresubmit)
 
-	presubmitsForCiopConfigs, periodicsForCiopConfigs := diffs.GetJobsForCiopConfigs(prConfig.Prow, changedCiopConfigData, affectedJobs, logger)
-	toRehearse.AddAll(presubmitsForCiopConfigs, config.ChangedCiopConfig)
-	periodicsToRehearse.AddAll(periodicsForCiopConfigs, config.ChangedCiopConfig)
-
-	presubmitsForClusterProfiles := diffs.GetPresubmitsForClusterProfiles(prConfig.Prow, rehearsalClusterProfiles.ProductionNames, logger)
-	toRehearse.AddAll(presubmitsForClusterProfiles, config.ChangedClusterProfile)
-
-	randomJobsForChangedTemplates := rehearse.AddRandomJobsForChangedTemplates(rehearsalTemplates.ProductionNames, toRehearse, prConfig.Prow.JobConfig.PresubmitsStatic, loggers)
-	toRehearse.AddAll(randomJobsForChangedTemplates, config.ChangedTemplate)
-
-	presubmitsForRegistry, periodicsForRegistry := rehearse.SelectJobsForChangedRegistry(changedRegistrySteps, prConfig.Prow.JobConfig.PresubmitsStatic, prConfig.Prow.JobConfig.Periodics, prConfig.CiOperator, loggers)
-	toRehearse.AddAll(presubmitsForRegistry, config.ChangedRegistryContent)
-	periodicsToRehearse.AddAll(periodicsForRegistry, config.ChangedRegistryContent)
-
-	resolver := registry.NewResolver(refs, chains, workflows, observers)
-	jobConfigurer := rehearse.NewJobConfigurer(prConfig.CiOperator, resolver, prNumber, loggers, rehearsalTemplates.Names, rehearsalClusterProfiles.Names, jobSpec.Refs)
-	imagestreamtags, presubmitsToRehearse, err := jobConfigurer.ConfigurePresubmitRehearsals(toRehearse)
+	affected := c.jobsToRehearse(logger, loggers)
+	imagestreamtags, presubmitsToRehearse, err := c.configureRehearsals(affected.presubmits, affected.periodics, prNumber, jobSpec.Refs, loggers)
 	if err != nil {
 		return err
 	}
 
-	periodicImageStreamTags, periodics, err := jobConfigurer.ConfigurePeriodicRehearsals(periodicsToRehearse)
-	if err != nil {
-		return err
-	}
-	apihelper.MergeImageStreamTagMaps(imagestreamtags, periodicImageStreamTags)
-
-	periodicPresubmits, err := jobConfigurer.ConvertPeriodicsToPresubmits(periodics)
-	if err != nil {
-		return err
-	}
-	presubmitsToRehearse = append(presubmitsToRehearse, periodicPresubmits...)
-
 	if rehearsals := len(presubmitsToRehearse); rehearsals == 0 {
 		logger.Info("no jobs to rehearse have been found")
 		return nil
+	} else if o.rehearsalBudget > 0 {
+		coverage := c.coverage(affected)
+		var decisions []rehearse.Decision
+		presubmitsToRehearse, decisions = rehearse.SelectWithinBudget(presubmitsToRehearse, prNumber, coverage, costs, o.rehearsalBudget, o.rehearsalLimit)
+		budgetFields := logrus.Fields{
+			"rehearsal-budget": o.rehearsalBudget,
+			"rehearsal-jobs":   rehearsals,
+			"selected-jobs":    len(presubmitsToRehearse),
+		}
+		logger.WithFields(budgetFields).Info("Selected rehearsals within the budget")
+		comment := rehearse.FormatBudgetComment(decisions, o.rehearsalBudget)
+		if githubClient != nil {
+			if err := rehearse.ReportBudget(githubClient, org, repo, prNumber, comment); err != nil {
+				logger.WithError(err).Warn("could not report the selection of rehearsals on the pull request")
+			}
+		} else {
+			loggers.Debug.Debug(comment)
+		}
+		if len(presubmitsToRehearse) == 0 {
+			logger.Info("no jobs to rehearse fit into the budget")
+			return nil
+		}
 	} else if rehearsals > o.rehearsalLimit {
 		jobCountFields := logrus.Fields{
 			"rehearsal-threshold": o.rehearsalLimit,
@@ -334,24 +262,7 @@ func rehearseMain() error {
 		presubmitsToRehearse = determineSubsetToRehearse(presubmitsToRehearse, o.rehearsalLimit)
 	}
 
-	if prConfig.Prow.JobConfig.PresubmitsStatic == nil {
-		prConfig.Prow.JobConfig.PresubmitsStatic = map[string][]prowconfig.Presubmit{}
-	}
-	for _, presubmit := range presubmitsToRehearse {
-
-		// We can only have a given repo once, so remove whats in Refs from ExtraRefs
-		var cleanExtraRefs []pjapi.Refs
-		for _, extraRef := range presubmit.ExtraRefs {
-			if extraRef.Org == org && extraRef.Repo == repo {
-				continue
-			}
-			cleanExtraRefs = append(cleanExtraRefs, extraRef)
-		}
-		presubmit.ExtraRefs = cleanExtraRefs
-
-		prConfig.Prow.JobConfig.PresubmitsStatic[org+"/"+repo] = append(prConfig.Prow.JobConfig.PresubmitsStatic[org+"/"+repo], *presubmit)
-	}
-	if err := prConfig.Prow.ValidateJobConfig(); err != nil {
+	if err := validateRehearsals(c.prConfig.Prow, presubmitsToRehearse, org, repo); err != nil {
 		logger.WithError(err).Error("jobconfig validation failed")
 		return fmt.Errorf(jobValidationOutput)
 	}
@@ -362,14 +273,14 @@ func rehearseMain() error {
 		prNumber,
 		buildClusterConfigs,
 		logger,
-		prConfig.Prow.ProwJobNamespace,
+		c.prConfig.Prow.ProwJobNamespace,
 		pjclient,
-		prConfig.Prow.PodNamespace,
-		configUpdaterCfg,
+		c.prConfig.Prow.PodNamespace,
+		c.configUpdaterCfg,
 		o.releaseRepoPath,
 		o.dryRun,
-		rehearsalTemplates,
-		rehearsalClusterProfiles,
+		c.templates,
+		c.clusterProfiles,
 		imagestreamtags)
 	if err != nil {
 		logger.WithError(err).Error("Failed to set up dependencies. This might cause subsequent failures.")
@@ -379,7 +290,7 @@ func rehearseMain() error {
 		defer cleanup()
 	}
 
-	executor := rehearse.NewExecutor(presubmitsToRehearse, prNumber, o.releaseRepoPath, jobSpec.Refs, o.dryRun, loggers, pjclient, prConfig.Prow.ProwJobNamespace)
+	executor := rehearse.NewExecutor(presubmitsToRehearse, prNumber, o.releaseRepoPath, jobSpec.Refs, o.dryRun, loggers, pjclient, c.prConfig.Prow.ProwJobNamespace)
 	success, err := executor.ExecuteJobs()
 	if err != nil {
 		logger.WithError(err).Error("Failed to rehearse jobs")
@@ -395,12 +306,107 @@ func rehearseMain() error {
 }
 
 func main() {
-	if err := rehearseMain(); err != nil {
+	o, err := gatherOptions()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to gather options")
+	}
+	if err := validateOptions(&o); err != nil {
+		logrus.WithError(err).Fatal("invalid options")
+	}
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		logrus.WithError(err).Fatal("failed to register imagev1 scheme")
+	}
+
+	if o.server {
+		serverMain(o)
+		return
+	}
+	if err := rehearseMain(o); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
 }
 
+// serverMain runs pj-rehearse as an external Prow plugin, rehearsing jobs when
+// requested by commands on pull requests
+func serverMain(o options) {
+	logrusutil.ComponentInit()
+	logger := logrus.WithField("plugin", "pj-rehearse")
+
+	buildClusterConfigs, prowJobConfig, err := loadClusterConfigs(o)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to read kubeconfigs.")
+	}
+	pjclient, err := rehearse.NewProwJobClient(prowJobConfig, o.dryRun)
+	if err != nil {
+		logger.WithError(err).Fatal("Could not create a ProwJob client.")
+	}
+
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.github.TokenPath, o.webhookSecretFile}); err != nil {
+		logger.WithError(err).Fatal("Error starting secrets agent.")
+	}
+	githubClient, err := o.github.GitHubClient(secretAgent, o.dryRun)
+	if err != nil {
+		logger.WithError(err).Fatal("Error getting GitHub client.")
+	}
+	gitClient, err := o.git.GitClient(githubClient, secretAgent.GetTokenGenerator(o.github.TokenPath), secretAgent.Censor, o.dryRun)
+	if err != nil {
+		logger.WithError(err).Fatal("Error getting Git client.")
+	}
+
+	serv := &server{
+		ghc:                 githubClient,
+		gc:                  gitClient,
+		loadOptions:         o.loadOptions,
+		rehearsalLimit:      o.rehearsalLimit,
+		buildClusterConfigs: buildClusterConfigs,
+		pjclient:            pjclient,
+		dryRun:              o.dryRun,
+	}
+
+	eventServer := githubeventserver.New(o.githubEventServerOptions, secretAgent.GetTokenGenerator(o.webhookSecretFile), logger)
+	eventServer.RegisterHandleIssueCommentEvent(serv.handleIssueComment)
+	eventServer.RegisterHelpProvider(helpProvider, logger)
+
+	interrupts.OnInterrupt(func() {
+		eventServer.GracefulShutdown()
+		// wait for the submitted rehearsals so that their dependencies are cleaned up
+		serv.running.Wait()
+		if err := gitClient.Clean(); err != nil {
+			logger.WithError(err).Error("Could not clean up git client cache.")
+		}
+	})
+
+	health := pjutil.NewHealth()
+	health.ServeReady()
+
+	interrupts.ListenAndServe(eventServer, time.Second*30)
+	interrupts.WaitForGracefulShutdown()
+}
+
+// loadClusterConfigs loads the configs for the build clusters and for the cluster
+// where the ProwJobs are created. No configs are loaded in the dry-run mode.
+func loadClusterConfigs(o options) (map[string]*rest.Config, *rest.Config, error) {
+	buildClusterConfigs := map[string]*rest.Config{}
+	if o.dryRun {
+		return buildClusterConfigs, nil, nil
+	}
+	// Only the env var allows to supply multiple kubeconfigs
+	if _, exists := os.LookupEnv("KUBECONFIG"); exists {
+		var err error
+		buildClusterConfigs, _, err = util.LoadKubeConfigs("", nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read kubeconfigs: %w", err)
+		}
+	}
+	prowJobConfig, err := pjKubeconfig(o.prowjobKubeconfig, buildClusterConfigs["app.ci"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load prowjob kubeconfig: %w", err)
+	}
+	return buildClusterConfigs, prowJobConfig, nil
+}
+
 func pjKubeconfig(path string, defaultKubeconfig *rest.Config) (*rest.Config, error) {
 	if path == "" {
 		return defaultKubeconfig, nil
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pluginhelp"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/rehearse"
+)
+
+const (
+	// rehearsalsAckLabel is added to pull requests when their author decides
+	// that no rehearsals are needed
+	rehearsalsAckLabel = "rehearsals-ack"
+
+	// stateCommentMarker identifies the comment in which the plugin tracks
+	// the rehearsals requested on a pull request
+	stateCommentMarker = "<!-- pj-rehearse: rehearsal state -->"
+	stateDataPrefix    = "<!-- pj-rehearse-state: "
+	stateDataSuffix    = " -->"
+)
+
+var rehearseCommandRe = regexp.MustCompile(`(?m)^/pj-rehearse(?:[ \t]+(.*?))?[ \t]*$`)
+
+type githubClient interface {
+	IsMember(org, user string) (bool, error)
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	AddLabel(org, repo string, number int, label string) error
+	BotUserChecker() (func(candidate string) bool, error)
+	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	CreateComment(org, repo string, number int, comment string) error
+	EditComment(org, repo string, id int, comment string) error
+}
+
+func helpProvider(_ []prowconfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	pluginHelp := &pluginhelp.PluginHelp{
+		Description: `The pj-rehearse plugin rehearses jobs affected by changes to the CI configuration on request.`,
+	}
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/pj-rehearse <job-name> [<job-name>...]",
+		Description: "Rehearse the named jobs against the head of the pull request",
+		WhoCanUse:   "Members of the organization of the repo.",
+		Examples:    []string{"/pj-rehearse pull-ci-openshift-origin-master-e2e-aws"},
+	})
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/pj-rehearse more",
+		Description: "Rehearse the next batch of affected jobs that were not rehearsed yet",
+		WhoCanUse:   "Members of the organization of the repo.",
+		Examples:    []string{"/pj-rehearse more"},
+	})
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/pj-rehearse skip",
+		Description: fmt.Sprintf("Acknowledge that the pull request does not need more rehearsals by adding the %s label", rehearsalsAckLabel),
+		WhoCanUse:   "Members of the organization of the repo.",
+		Examples:    []string{"/pj-rehearse skip"},
+	})
+	return pluginHelp, nil
+}
+
+type commandKind string
+
+const (
+	commandJobs commandKind = "jobs"
+	commandMore commandKind = "more"
+	commandSkip commandKind = "skip"
+)
+
+type command struct {
+	kind commandKind
+	jobs []string
+}
+
+// parseCommands returns all pj-rehearse commands in the comment
+func parseCommands(body string) []command {
+	var commands []command
+	for _, match := range rehearseCommandRe.FindAllStringSubmatch(body, -1) {
+		args := strings.Fields(match[1])
+		switch {
+		case len(args) == 0:
+			continue
+		case len(args) == 1 && args[0] == string(commandMore):
+			commands = append(commands, command{kind: commandMore})
+		case len(args) == 1 && args[0] == string(commandSkip):
+			commands = append(commands, command{kind: commandSkip})
+		default:
+			commands = append(commands, command{kind: commandJobs, jobs: args})
+		}
+	}
+	return commands
+}
+
+// rehearsalState is tracked in a comment on the pull request
+type rehearsalState struct {
+	// Head is the revision of the pull request the rehearsals ran against
+	Head string `json:"head,omitempty"`
+	// Rehearsed holds the names of the jobs rehearsed against the head
+	Rehearsed []string `json:"rehearsed,omitempty"`
+	// SkippedBy is the user that acknowledged no more rehearsals are needed
+	SkippedBy string `json:"skipped_by,omitempty"`
+}
+
+// forHead returns the state valid for the given revision of the pull request
+func (s rehearsalState) forHead(head string) rehearsalState {
+	if s.Head != head {
+		s.Head = head
+		s.Rehearsed = nil
+	}
+	return s
+}
+
+// record adds the jobs to the rehearsed jobs, keeping them sorted and unique
+func (s *rehearsalState) record(jobs ...string) {
+	s.Rehearsed = sets.NewString(s.Rehearsed...).Insert(jobs...).List()
+}
+
+func (s rehearsalState) render() (string, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal state: %w", err)
+	}
+	var b strings.Builder
+	b.WriteString(stateCommentMarker + "\n")
+	if len(s.Rehearsed) > 0 {
+		fmt.Fprintf(&b, "The following jobs were rehearsed on request against %s:\n\n", s.Head)
+		for _, job := range s.Rehearsed {
+			fmt.Fprintf(&b, "- `%s`\n", job)
+		}
+	} else {
+		fmt.Fprintf(&b, "No jobs were rehearsed on request against %s.\n", s.Head)
+	}
+	if s.SkippedBy != "" {
+		fmt.Fprintf(&b, "\n@%s acknowledged that no more rehearsals are needed.\n", s.SkippedBy)
+	}
+	b.WriteString("\nUse `/pj-rehearse <job-name>` to rehearse specific jobs, `/pj-rehearse more` to rehearse more of the affected jobs and `/pj-rehearse skip` to acknowledge that no more rehearsals are needed.\n")
+	b.WriteString(stateDataPrefix + string(raw) + stateDataSuffix + "\n")
+	return b.String(), nil
+}
+
+// parseState extracts the state from the content of the state comment
+func parseState(body string) (rehearsalState, error) {
+	var state rehearsalState
+	start := strings.Index(body, stateDataPrefix)
+	if start == -1 {
+		return state, fmt.Errorf("no state found in comment")
+	}
+	data := body[start+len(stateDataPrefix):]
+	end := strings.Index(data, stateDataSuffix)
+	if end == -1 {
+		return state, fmt.Errorf("state in comment is not terminated")
+	}
+	if err := json.Unmarshal([]byte(data[:end]), &state); err != nil {
+		return state, fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+	return state, nil
+}
+
+type server struct {
+	ghc githubClient
+	gc  git.ClientFactory
+
+	loadOptions         loadOptions
+	rehearsalLimit      int
+	buildClusterConfigs map[string]*rest.Config
+	pjclient            ctrlruntimeclient.Client
+	dryRun              bool
+
+	// lock serializes the handling of commands, as they modify the state comment
+	lock sync.Mutex
+	// running tracks the rehearsals that are being waited for
+	running sync.WaitGroup
+}
+
+func (s *server) handleIssueComment(l *logrus.Entry, ic github.IssueCommentEvent) {
+	if ic.Action != github.IssueCommentActionCreated || !ic.Issue.IsPullRequest() {
+		return
+	}
+	commands := parseCommands(ic.Comment.Body)
+	if len(commands) == 0 {
+		return
+	}
+
+	org, repo, number, user := ic.Repo.Owner.Login, ic.Repo.Name, ic.Issue.Number, ic.Comment.User.Login
+	logger := l.WithFields(logrus.Fields{
+		github.OrgLogField:  org,
+		github.RepoLogField: repo,
+		github.PrLogField:   number,
+	})
+
+	isMember, err := s.ghc.IsMember(org, user)
+	if err != nil {
+		logger.WithError(err).Warn("couldn't check membership")
+		s.createComment(ic, fmt.Sprintf("could not check whether you are a member of the %s organization: %v", org, err), logger)
+		return
+	}
+	if !isMember {
+		s.createComment(ic, fmt.Sprintf("only [%s](https://github.com/orgs/%s/people) org members may request rehearsals", org, org), logger)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pr, err := s.ghc.GetPullRequest(org, repo, number)
+	if err != nil {
+		logger.WithError(err).Warn("couldn't get pull request")
+		s.createComment(ic, fmt.Sprintf("could not get pull request: %v", err), logger)
+		return
+	}
+	commentID, state, err := s.loadState(org, repo, number)
+	if err != nil {
+		logger.WithError(err).Warn("couldn't load rehearsal state")
+		s.createComment(ic, fmt.Sprintf("could not load the state of rehearsals: %v", err), logger)
+		return
+	}
+	state = state.forHead(pr.Head.SHA)
+
+	for _, cmd := range commands {
+		var message string
+		var err error
+		switch cmd.kind {
+		case commandSkip:
+			err = s.skip(org, repo, number, user, &state)
+		default:
+			message, err = s.rehearse(pr, cmd, &state, logger)
+		}
+		if err != nil {
+			logger.WithError(err).Warn("couldn't handle command")
+			message = err.Error()
+		}
+		if message != "" {
+			s.createComment(ic, message, logger)
+		}
+	}
+
+	if err := s.saveState(org, repo, number, commentID, state); err != nil {
+		logger.WithError(err).Warn("couldn't save rehearsal state")
+	}
+}
+
+func (s *server) createComment(ic github.IssueCommentEvent, message string, logger *logrus.Entry) {
+	comment := fmt.Sprintf("@%s: %s", ic.Comment.User.Login, message)
+	if err := s.ghc.CreateComment(ic.Repo.Owner.Login, ic.Repo.Name, ic.Issue.Number, comment); err != nil {
+		logger.WithError(err).Warn("couldn't create comment")
+	}
+}
+
+// loadState returns the state and the ID of the comment tracking it, or zero if
+// there is no such comment on the pull request yet
+func (s *server) loadState(org, repo string, number int) (int, rehearsalState, error) {
+	isBot, err := s.ghc.BotUserChecker()
+	if err != nil {
+		return 0, rehearsalState{}, fmt.Errorf("failed to get the bot user: %w", err)
+	}
+	comments, err := s.ghc.ListIssueComments(org, repo, number)
+	if err != nil {
+		return 0, rehearsalState{}, fmt.Errorf("failed to list comments: %w", err)
+	}
+	for _, comment := range comments {
+		if isBot(comment.User.Login) && strings.HasPrefix(comment.Body, stateCommentMarker) {
+			state, err := parseState(comment.Body)
+			return comment.ID, state, err
+		}
+	}
+	return 0, rehearsalState{}, nil
+}
+
+func (s *server) saveState(org, repo string, number, commentID int, state rehearsalState) error {
+	comment, err := state.render()
+	if err != nil {
+		return err
+	}
+	if commentID == 0 {
+		return s.ghc.CreateComment(org, repo, number, comment)
+	}
+	return s.ghc.EditComment(org, repo, commentID, comment)
+}
+
+func (s *server) skip(org, repo string, number int, user string, state *rehearsalState) error {
+	if err := s.ghc.AddLabel(org, repo, number, rehearsalsAckLabel); err != nil {
+		return fmt.Errorf("could not add the %s label: %w", rehearsalsAckLabel, err)
+	}
+	state.SkippedBy = user
+	return nil
+}
+
+// invocationID identifies one run of rehearsals, so that the temporary
+// ConfigMaps of runs on the same pull request do not collide, even when they
+// start within the same second.
+func invocationID() string {
+	return fmt.Sprintf("%d-%s", time.Now().Unix(), utilrand.String(5))
+}
+
+// rehearse submits the rehearsals requested by the command against the head of
+// the pull request and records them in the state. The returned message explains
+// which of the requested rehearsals could not be submitted.
+func (s *server) rehearse(pr *github.PullRequest, cmd command, state *rehearsalState, logger *logrus.Entry) (string, error) {
+	org, repo, number := pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number
+	repoClient, err := s.gc.ClientFor(org, repo)
+	if err != nil {
+		return "", fmt.Errorf("could not clone %s/%s: %w", org, repo, err)
+	}
+	defer func() {
+		if err := repoClient.Clean(); err != nil {
+			logger.WithError(err).Error("couldn't clean temporary repo folder")
+		}
+	}()
+	if err := checkoutPullRequest(repoClient, pr); err != nil {
+		return "", fmt.Errorf("could not check out the pull request: %w", err)
+	}
+
+	refs := &pjapi.Refs{
+		Org:     org,
+		Repo:    repo,
+		BaseRef: pr.Base.Ref,
+		BaseSHA: pr.Base.SHA,
+		Pulls:   []pjapi.Pull{{Number: number, Author: pr.User.Login, SHA: pr.Head.SHA}},
+	}
+	releaseRepoPath := repoClient.Directory()
+	buildID := invocationID()
+	c, err := loadChanges(s.loadOptions, releaseRepoPath, pr.Base.SHA, buildID, number, logger)
+	if err != nil {
+		return "", err
+	}
+	loggers := rehearse.Loggers{Job: logger, Debug: logger}
+
+	var presubmits config.Presubmits
+	var periodics config.Periodics
+	var unknown []string
+	switch cmd.kind {
+	case commandJobs:
+		presubmits, periodics, unknown = jobsByName(c.prConfig.Prow, cmd.jobs)
+	case commandMore:
+		affected := c.jobsToRehearse(logger, loggers)
+		presubmits, periodics = affected.presubmits, affected.periodics
+	}
+	imagestreamtags, rehearsals, err := c.configureRehearsals(presubmits, periodics, number, refs, loggers)
+	if err != nil {
+		return "", fmt.Errorf("could not configure rehearsals: %w", err)
+	}
+
+	var message string
+	switch cmd.kind {
+	case commandJobs:
+		configured := sets.NewString()
+		for _, rehearsal := range rehearsals {
+			configured.Insert(sourceJobName(rehearsal.Name, number))
+		}
+		var cannot []string
+		for _, job := range cmd.jobs {
+			if !configured.Has(job) && !sets.NewString(unknown...).Has(job) {
+				cannot = append(cannot, job)
+			}
+		}
+		if len(unknown) > 0 {
+			message += fmt.Sprintf("The following jobs do not exist: %s. ", strings.Join(unknown, ", "))
+		}
+		if len(cannot) > 0 {
+			message += fmt.Sprintf("The following jobs cannot be rehearsed: %s. ", strings.Join(cannot, ", "))
+		}
+	case commandMore:
+		alreadyRehearsed, err := s.rehearsedJobs(c.prConfig.Prow.ProwJobNamespace, number, pr.Head.SHA)
+		if err != nil {
+			return "", fmt.Errorf("could not determine jobs that were already rehearsed: %w", err)
+		}
+		rehearsals = selectMore(rehearsals, number, alreadyRehearsed.Insert(state.Rehearsed...), s.rehearsalLimit)
+		if len(rehearsals) == 0 {
+			message += "All affected jobs were already rehearsed. "
+		}
+	}
+	if len(rehearsals) == 0 {
+		return message + "No jobs were rehearsed.", nil
+	}
+
+	if err := validateRehearsals(c.prConfig.Prow, rehearsals, org, repo); err != nil {
+		return "", fmt.Errorf("rehearsals are invalid: %w", err)
+	}
+	cleanup, err := setupDependencies(rehearsals, number, s.buildClusterConfigs, logger, c.prConfig.Prow.ProwJobNamespace, s.pjclient, c.prConfig.Prow.PodNamespace, c.configUpdaterCfg, releaseRepoPath, s.dryRun, c.templates, c.clusterProfiles, imagestreamtags)
+	if err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
+		return "", fmt.Errorf("could not set up the dependencies of rehearsals: %w", err)
+	}
+
+	for _, rehearsal := range rehearsals {
+		state.record(sourceJobName(rehearsal.Name, number))
+	}
+
+	executor := rehearse.NewExecutor(rehearsals, number, releaseRepoPath, refs, s.dryRun, loggers, s.pjclient, c.prConfig.Prow.ProwJobNamespace)
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		if cleanup != nil {
+			defer cleanup()
+		}
+		// the results are reported by the rehearsals themselves, we only wait
+		// so that the temporary dependencies can be cleaned up
+		if _, err := executor.ExecuteJobs(); err != nil {
+			logger.WithError(err).Warn("Failed to rehearse jobs")
+		}
+	}()
+
+	if message != "" {
+		message += "The remaining jobs are being rehearsed."
+	}
+	return message, nil
+}
+
+// checkoutPullRequest checks out the pull request merged into its base
+func checkoutPullRequest(repoClient git.RepoClient, pr *github.PullRequest) error {
+	if err := repoClient.FetchRef(fmt.Sprintf("pull/%d/head", pr.Number)); err != nil {
+		return err
+	}
+	return repoClient.MergeAndCheckout(pr.Base.SHA, string(github.MergeMerge), pr.Head.SHA)
+}
+
+// jobsByName looks up presubmits and periodics by their names, returning the
+// names that do not match any job
+func jobsByName(prowConfig *prowconfig.Config, names []string) (config.Presubmits, config.Periodics, []string) {
+	requested := sets.NewString(names...)
+	found := sets.NewString()
+	presubmits, periodics := config.Presubmits{}, config.Periodics{}
+	for repo, jobs := range prowConfig.JobConfig.PresubmitsStatic {
+		for _, job := range jobs {
+			if requested.Has(job.Name) {
+				presubmits.Add(repo, job, config.RequestedJob)
+				found.Insert(job.Name)
+			}
+		}
+	}
+	for _, job := range prowConfig.JobConfig.Periodics {
+		if requested.Has(job.Name) {
+			periodics.Add(job, config.RequestedJob)
+			found.Insert(job.Name)
+		}
+	}
+	return presubmits, periodics, requested.Difference(found).List()
+}
+
+// rehearsedJobs returns the names of the jobs that were already rehearsed
+// against the revision of the pull request
+func (s *server) rehearsedJobs(namespace string, number int, head string) (sets.String, error) {
+	var prowJobs pjapi.ProwJobList
+	if err := s.pjclient.List(context.Background(), &prowJobs, ctrlruntimeclient.InNamespace(namespace), ctrlruntimeclient.MatchingLabels{rehearse.Label: strconv.Itoa(number)}); err != nil {
+		return nil, err
+	}
+	rehearsed := sets.NewString()
+	for _, prowJob := range prowJobs.Items {
+		if refs := prowJob.Spec.Refs; refs != nil && len(refs.Pulls) > 0 && refs.Pulls[0].SHA == head {
+			rehearsed.Insert(sourceJobName(prowJob.Spec.Job, number))
+		}
+	}
+	return rehearsed, nil
+}
+
+// selectMore selects up to limit rehearsals of jobs that were not rehearsed yet
+func selectMore(rehearsals []*prowconfig.Presubmit, number int, rehearsed sets.String, limit int) []*prowconfig.Presubmit {
+	sort.Slice(rehearsals, func(i, j int) bool { return rehearsals[i].Name < rehearsals[j].Name })
+	var selected []*prowconfig.Presubmit
+	for _, rehearsal := range rehearsals {
+		if len(selected) == limit {
+			break
+		}
+		if !rehearsed.Has(sourceJobName(rehearsal.Name, number)) {
+			selected = append(selected, rehearsal)
+		}
+	}
+	return selected
+}
+
+// sourceJobName returns the name of the job rehearsed by a rehearsal
+func sourceJobName(rehearsal string, number int) string {
+	return strings.TrimPrefix(rehearsal, fmt.Sprintf("rehearse-%d-", number))
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/github"
+
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+func TestParseCommands(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected []command
+	}{
+		{
+			name: "no command",
+			body: "lgtm\n/test all",
+		},
+		{
+			name: "command without arguments is ignored",
+			body: "/pj-rehearse",
+		},
+		{
+			name:     "more",
+			body:     "/pj-rehearse more",
+			expected: []command{{kind: commandMore}},
+		},
+		{
+			name:     "skip with trailing whitespace",
+			body:     "/pj-rehearse skip  \r",
+			expected: []command{{kind: commandSkip}},
+		},
+		{
+			name:     "jobs",
+			body:     "/pj-rehearse job-a job-b",
+			expected: []command{{kind: commandJobs, jobs: []string{"job-a", "job-b"}}},
+		},
+		{
+			name:     "multiple commands",
+			body:     "please\n/pj-rehearse job-a\n/pj-rehearse more\nthanks",
+			expected: []command{{kind: commandJobs, jobs: []string{"job-a"}}, {kind: commandMore}},
+		},
+		{
+			name: "command not at the start of a line is ignored",
+			body: "use /pj-rehearse more",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, parseCommands(tc.body), cmp.AllowUnexported(command{})); diff != "" {
+				t.Errorf("unexpected commands: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRehearsalState(t *testing.T) {
+	state := rehearsalState{Head: "abc", Rehearsed: []string{"job-a", "job-b"}, SkippedBy: "user"}
+	body, err := state.render()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := parseState(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(state, parsed); diff != "" {
+		t.Errorf("state did not survive a roundtrip: %s", diff)
+	}
+	if diff := cmp.Diff(state, parsed.forHead("abc")); diff != "" {
+		t.Errorf("state for the same head changed: %s", diff)
+	}
+	expected := rehearsalState{Head: "def", SkippedBy: "user"}
+	if diff := cmp.Diff(expected, parsed.forHead("def")); diff != "" {
+		t.Errorf("unexpected state for a new head: %s", diff)
+	}
+	if _, err := parseState("no state here"); err == nil {
+		t.Error("expected an error for a comment without state")
+	}
+}
+
+func TestRehearsalStateRecord(t *testing.T) {
+	state := rehearsalState{Head: "abc", Rehearsed: []string{"job-b"}}
+	state.record("job-c", "job-a")
+	state.record("job-b", "job-a")
+	if diff := cmp.Diff([]string{"job-a", "job-b", "job-c"}, state.Rehearsed); diff != "" {
+		t.Errorf("unexpected rehearsed jobs: %s", diff)
+	}
+}
+
+func TestInvocationID(t *testing.T) {
+	seen := sets.NewString()
+	for i := 0; i < 100; i++ {
+		id := invocationID()
+		if seen.Has(id) {
+			t.Fatalf("invocation ID %s was returned twice", id)
+		}
+		seen.Insert(id)
+		if errs := validation.IsDNS1123Subdomain("rehearse-1-" + id + "-template-name"); len(errs) > 0 {
+			t.Errorf("invocation ID %s cannot be used in object names: %v", id, errs)
+		}
+	}
+}
+
+func presubmitNames(presubmits config.Presubmits) map[string][]string {
+	names := map[string][]string{}
+	for repo, jobs := range presubmits {
+		for _, job := range jobs {
+			names[repo] = append(names[repo], job.Name)
+		}
+	}
+	return names
+}
+
+func TestJobsByName(t *testing.T) {
+	presubmit := prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: "pull-job"}}
+	periodic := prowconfig.Periodic{JobBase: prowconfig.JobBase{Name: "periodic-job"}}
+	prowConfig := &prowconfig.Config{JobConfig: prowconfig.JobConfig{
+		PresubmitsStatic: map[string][]prowconfig.Presubmit{"org/repo": {presubmit, {JobBase: prowconfig.JobBase{Name: "other-job"}}}},
+		Periodics:        []prowconfig.Periodic{periodic},
+	}}
+	presubmits, periodics, unknown := jobsByName(prowConfig, []string{"pull-job", "periodic-job", "missing-job"})
+
+	if diff := cmp.Diff(map[string][]string{"org/repo": {"pull-job"}}, presubmitNames(presubmits)); diff != "" {
+		t.Errorf("unexpected presubmits: %s", diff)
+	}
+	if len(periodics) != 1 || periodics["periodic-job"].Name != "periodic-job" {
+		t.Errorf("unexpected periodics: %v", periodics)
+	}
+	if diff := cmp.Diff([]string{"missing-job"}, unknown); diff != "" {
+		t.Errorf("unexpected unknown jobs: %s", diff)
+	}
+}
+
+func TestSelectMore(t *testing.T) {
+	rehearsal := func(name string) *prowconfig.Presubmit {
+		return &prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: "rehearse-1-" + name, Spec: &v1.PodSpec{}}}
+	}
+	testCases := []struct {
+		name      string
+		rehearsed sets.String
+		limit     int
+		expected  []string
+	}{
+		{
+			name:      "nothing rehearsed yet",
+			rehearsed: sets.NewString(),
+			limit:     2,
+			expected:  []string{"rehearse-1-a", "rehearse-1-b"},
+		},
+		{
+			name:      "rehearsed jobs are skipped",
+			rehearsed: sets.NewString("a", "c"),
+			limit:     2,
+			expected:  []string{"rehearse-1-b", "rehearse-1-d"},
+		},
+		{
+			name:      "everything rehearsed",
+			rehearsed: sets.NewString("a", "b", "c", "d"),
+			limit:     2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rehearsals := []*prowconfig.Presubmit{rehearsal("d"), rehearsal("c"), rehearsal("b"), rehearsal("a")}
+			var names []string
+			for _, job := range selectMore(rehearsals, 1, tc.rehearsed, tc.limit) {
+				names = append(names, job.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected selection: %s", diff)
+			}
+		})
+	}
+}
+
+type fakeGithubClient struct {
+	members  sets.String
+	comments []github.IssueComment
+	labels   []string
+	created  []string
+	edited   map[int]string
+}
+
+func (c *fakeGithubClient) IsMember(_, user string) (bool, error) {
+	return c.members.Has(user), nil
+}
+
+func (c *fakeGithubClient) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
+	return &github.PullRequest{Number: number, Head: github.PullRequestBranch{SHA: "head"}}, nil
+}
+
+func (c *fakeGithubClient) AddLabel(_, _ string, _ int, label string) error {
+	c.labels = append(c.labels, label)
+	return nil
+}
+
+func (c *fakeGithubClient) BotUserChecker() (func(candidate string) bool, error) {
+	return func(candidate string) bool { return candidate == "bot" }, nil
+}
+
+func (c *fakeGithubClient) ListIssueComments(_, _ string, _ int) ([]github.IssueComment, error) {
+	return c.comments, nil
+}
+
+func (c *fakeGithubClient) CreateComment(_, _ string, _ int, comment string) error {
+	c.created = append(c.created, comment)
+	return nil
+}
+
+func (c *fakeGithubClient) EditComment(_, _ string, id int, comment string) error {
+	if c.edited == nil {
+		c.edited = map[int]string{}
+	}
+	c.edited[id] = comment
+	return nil
+}
+
+func TestHandleIssueComment(t *testing.T) {
+	skipped := rehearsalState{Head: "head", SkippedBy: "member"}
+	skippedComment, err := skipped.render()
+	if err != nil {
+		t.Fatalf("failed to render state: %v", err)
+	}
+	previous := rehearsalState{Head: "old", Rehearsed: []string{"job"}}
+	previousComment, err := previous.render()
+	if err != nil {
+		t.Fatalf("failed to render state: %v", err)
+	}
+
+	testCases := []struct {
+		name            string
+		user            string
+		body            string
+		isPullRequest   bool
+		comments        []github.IssueComment
+		expectedLabels  []string
+		expectedCreated []string
+		expectedEdited  map[int]string
+	}{
+		{
+			name:          "comment without command is ignored",
+			user:          "member",
+			body:          "/test all",
+			isPullRequest: true,
+		},
+		{
+			name: "comment on an issue is ignored",
+			user: "member",
+			body: "/pj-rehearse skip",
+		},
+		{
+			name:            "non-member cannot request rehearsals",
+			user:            "stranger",
+			body:            "/pj-rehearse more",
+			isPullRequest:   true,
+			expectedCreated: []string{"@stranger: only [org](https://github.com/orgs/org/people) org members may request rehearsals"},
+		},
+		{
+			name:            "skip creates the state comment",
+			user:            "member",
+			body:            "/pj-rehearse skip",
+			isPullRequest:   true,
+			expectedLabels:  []string{rehearsalsAckLabel},
+			expectedCreated: []string{skippedComment},
+		},
+		{
+			name:          "skip updates the state comment for a new head",
+			user:          "member",
+			body:          "/pj-rehearse skip",
+			isPullRequest: true,
+			comments: []github.IssueComment{
+				{ID: 1, User: github.User{Login: "member"}, Body: "/pj-rehearse skip"},
+				{ID: 2, User: github.User{Login: "bot"}, Body: previousComment},
+			},
+			expectedLabels: []string{rehearsalsAckLabel},
+			expectedEdited: map[int]string{2: skippedComment},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ghc := &fakeGithubClient{members: sets.NewString("member"), comments: tc.comments}
+			s := &server{ghc: ghc}
+			ic := github.IssueCommentEvent{
+				Action:  github.IssueCommentActionCreated,
+				Comment: github.IssueComment{Body: tc.body, User: github.User{Login: tc.user}},
+				Issue:   github.Issue{Number: 1},
+				Repo:    github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+			}
+			if tc.isPullRequest {
+				ic.Issue.PullRequest = &struct{}{}
+			}
+			s.handleIssueComment(logrus.NewEntry(logrus.StandardLogger()), ic)
+			if diff := cmp.Diff(tc.expectedLabels, ghc.labels); diff != "" {
+				t.Errorf("unexpected labels: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedCreated, ghc.created); diff != "" {
+				t.Errorf("unexpected created comments: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedEdited, ghc.edited); diff != "" {
+				t.Errorf("unexpected edited comments: %s", diff)
+			}
+		})
+	}
+}
@@ -3,8 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/prometheus/common/model"
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
@@ -21,7 +26,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/steps"
 )
 
-func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Interface) {
+func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Interface, resources *resourceMutator) {
 	logger := logrus.WithField("component", "admission")
 	logger.Info("Initializing admission webhook server.")
 	health := pjutil.NewHealthOnPort(healthPort)
@@ -34,7 +39,7 @@ func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Int
 		Port:    port,
 		CertDir: certDir,
 	}
-	server.Register("/pods", &webhook.Admission{Handler: &podMutator{logger: logger, client: client, decoder: decoder}})
+	server.Register("/pods", &webhook.Admission{Handler: &podMutator{logger: logger, client: client, decoder: decoder, resources: resources}})
 	logger.Info("Serving admission webhooks.")
 	if err := server.StartStandalone(interrupts.Context(), nil); err != nil {
 		logrus.WithError(err).Fatal("Failed to serve webhooks.")
@@ -42,9 +47,10 @@ func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Int
 }
 
 type podMutator struct {
-	logger  *logrus.Entry
-	client  buildclientv1.BuildV1Interface
-	decoder *admission.Decoder
+	logger    *logrus.Entry
+	client    buildclientv1.BuildV1Interface
+	decoder   *admission.Decoder
+	resources *resourceMutator
 }
 
 func (m *podMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
@@ -55,19 +61,26 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 		logrus.WithError(err).Error("Failed to decode raw object as Pod.")
 		return admission.Errored(http.StatusBadRequest, err)
 	}
-	buildName, isBuildPod := pod.Annotations[buildv1.BuildLabel]
-	if !isBuildPod {
+	buildName, isBuildPod := buildNameFor(pod)
+	_, isCIPod := pod.Labels[steps.LabelMetadataOrg]
+	if !isBuildPod && (!isCIPod || m.resources == nil) {
 		logrus.Trace("Allowing Pod, it is not implementing a Build.")
 		return admission.Allowed("Not a Pod implementing a Build.")
 	}
-	logger := m.logger.WithField("build", buildName)
-	logger.Trace("Handling labels on Pod created for a Build.")
-	build, err := m.client.Builds(pod.Namespace).Get(ctx, buildName, metav1.GetOptions{})
-	if err != nil {
-		logger.WithError(err).Error("Could not get Build for Pod.")
-		return admission.Allowed("Could not get Build for Pod, ignoring.")
+	logger := m.logger.WithField("pod", pod.Name)
+	if isBuildPod {
+		logger = logger.WithField("build", buildName)
+		logger.Trace("Handling labels on Pod created for a Build.")
+		build, err := m.client.Builds(pod.Namespace).Get(ctx, buildName, metav1.GetOptions{})
+		if err != nil {
+			logger.WithError(err).Error("Could not get Build for Pod.")
+			return admission.Allowed("Could not get Build for Pod, ignoring.")
+		}
+		mutatePod(pod, build)
+	}
+	if m.resources != nil {
+		m.resources.mutate(pod, logger)
 	}
-	mutatePod(pod, build)
 
 	marshaledPod, err := json.Marshal(pod)
 	if err != nil {
@@ -90,3 +103,89 @@ func mutatePod(pod *corev1.Pod, build *buildv1.Build) {
 		}
 	}
 }
+
+// ResourceMutationAnnotation records which resources the webhook changed on a
+// Pod and the historical data the changes were based on.
+const ResourceMutationAnnotation = "ci.openshift.io/pod-scaler"
+
+// resourceMutator raises container resource requests to what we have seen
+// the containers use historically, up to the configured ceilings. It never
+// lowers values set by the user.
+type resourceMutator struct {
+	data     *recommendationData
+	ceilings corev1.ResourceList
+}
+
+// buildNameFor returns the name of the Build a Pod implements. The build
+// controller sets the annotation when it creates the Pod.
+func buildNameFor(pod *corev1.Pod) (string, bool) {
+	name, ok := pod.Annotations[buildv1.BuildAnnotation]
+	return name, ok
+}
+
+var invalidMetricLabelCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// metricFor returns the labels the producer sees for a container of the Pod
+// in the metrics it queries: kube-state-metrics exposes Pod labels prefixed
+// with "label_" and with invalid characters replaced.
+func metricFor(pod *corev1.Pod, container string) model.Metric {
+	metric := model.Metric{
+		LabelNamePod:       model.LabelValue(pod.Name),
+		LabelNameContainer: model.LabelValue(container),
+	}
+	for key, value := range pod.Labels {
+		metric[model.LabelName("label_"+invalidMetricLabelCharacters.ReplaceAllString(key, "_"))] = model.LabelValue(value)
+	}
+	if buildName, isBuildPod := buildNameFor(pod); isBuildPod {
+		metric[LabelNameBuild] = model.LabelValue(buildName)
+	}
+	return metric
+}
+
+// metadataFor identifies a container in the same way as the producer does
+// for the metrics it collects. Pods created with a generated name that the
+// producer does not ignore the name of cannot be identified, as their name
+// is not known at admission time.
+func metadataFor(pod *corev1.Pod, container string) FullMetadata {
+	return metadataFromMetric(metricFor(pod, container))
+}
+
+func (m *resourceMutator) mutate(pod *corev1.Pod, logger *logrus.Entry) {
+	var changes []string
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		recommendation, ok := m.data.recommendFor(metadataFor(pod, container.Name))
+		if !ok {
+			continue
+		}
+		for name, recommended := range recommendation.Requests {
+			if ceiling, capped := m.ceilings[name]; capped && recommended.Cmp(ceiling) > 0 {
+				recommended = ceiling
+			}
+			// requests may never exceed limits, and we never lower a limit
+			if limit, limited := container.Resources.Limits[name]; limited && recommended.Cmp(limit) > 0 {
+				recommended = limit
+			}
+			current, set := container.Resources.Requests[name]
+			if set && current.Cmp(recommended) >= 0 {
+				continue
+			}
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = corev1.ResourceList{}
+			}
+			container.Resources.Requests[name] = recommended
+			basis := recommendation.Basis[string(name)]
+			change := fmt.Sprintf("container %s: raised %s request from %s to %s, based on the %.0fth percentile of %d samples", container.Name, name, current.String(), recommended.String(), basis.Percentile*100, basis.Samples)
+			logger.Debug(change)
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+	sort.Strings(changes)
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[ResourceMutationAnnotation] = strings.Join(changes, "\n")
+}
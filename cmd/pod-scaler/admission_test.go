@@ -2,14 +2,18 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
 	"github.com/sirupsen/logrus"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -18,6 +22,7 @@ import (
 	buildv1 "github.com/openshift/api/build/v1"
 	fakebuildv1client "github.com/openshift/client-go/build/clientset/versioned/fake"
 
+	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -192,3 +197,155 @@ func TestMutatePod(t *testing.T) {
 		})
 	}
 }
+
+func TestMutateResources(t *testing.T) {
+	labels := map[string]string{
+		"ci.openshift.io/metadata.org":    "org",
+		"ci.openshift.io/metadata.repo":   "repo",
+		"ci.openshift.io/metadata.branch": "branch",
+		"ci.openshift.io/metadata.target": "e2e",
+		"ci.openshift.io/metadata.step":   "install",
+	}
+	meta := func(container string) FullMetadata {
+		return FullMetadata{
+			Metadata:  api.Metadata{Org: "org", Repo: "repo", Branch: "branch"},
+			Target:    "e2e",
+			Step:      "install",
+			Pod:       "e2e-install",
+			Container: container,
+		}
+	}
+	histogram := func(value float64) *circonusllhist.HistogramWithoutLookups {
+		hist := circonusllhist.New(circonusllhist.NoLookup())
+		if err := hist.RecordValue(value); err != nil {
+			t.Fatalf("failed to record value: %v", err)
+		}
+		return circonusllhist.NewHistogramWithoutLookups(hist)
+	}
+	mutator := &resourceMutator{
+		data: &recommendationData{data: map[string]*CachedQuery{
+			"steps/" + MetricNameCPUUsage: {
+				Data:           map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{1: histogram(2), 2: histogram(20)},
+				DataByMetaData: map[FullMetadata][]model.Fingerprint{meta("test"): {1}, meta("huge"): {2}},
+			},
+		}},
+		ceilings: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")},
+	}
+	recommended, _ := mutator.data.recommendFor(meta("test"))
+	cpu := recommended.Requests[corev1.ResourceCPU]
+
+	var testCases = []struct {
+		name        string
+		container   corev1.Container
+		expected    corev1.ResourceRequirements
+		annotations map[string]string
+	}{
+		{
+			name:      "no data for the container",
+			container: corev1.Container{Name: "other"},
+		},
+		{
+			name:      "request is raised",
+			container: corev1.Container{Name: "test", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}}},
+			expected:  corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: cpu}},
+			annotations: map[string]string{
+				ResourceMutationAnnotation: fmt.Sprintf("container test: raised cpu request from 100m to %s, based on the 80th percentile of 1 samples", cpu.String()),
+			},
+		},
+		{
+			name:      "larger request is not lowered",
+			container: corev1.Container{Name: "test", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}}},
+			expected:  corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}},
+		},
+		{
+			name:      "request is capped by the limit",
+			container: corev1.Container{Name: "test", Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}},
+			expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+			annotations: map[string]string{
+				ResourceMutationAnnotation: "container test: raised cpu request from 0 to 1, based on the 80th percentile of 1 samples",
+			},
+		},
+		{
+			name:      "request is capped by the ceiling",
+			container: corev1.Container{Name: "huge"},
+			expected:  corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")}},
+			annotations: map[string]string{
+				ResourceMutationAnnotation: "container huge: raised cpu request from 0 to 10, based on the 80th percentile of 1 samples",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "e2e-install", Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{testCase.container}},
+			}
+			mutator.mutate(pod, logrus.WithField("test", t.Name()))
+			if diff := cmp.Diff(testCase.expected, pod.Spec.Containers[0].Resources); diff != "" {
+				t.Errorf("got incorrect resources after mutation: %v", diff)
+			}
+			if diff := cmp.Diff(testCase.annotations, pod.Annotations); diff != "" {
+				t.Errorf("got incorrect annotations after mutation: %v", diff)
+			}
+		})
+	}
+}
+
+func TestMetadataFor(t *testing.T) {
+	labels := func(extra map[string]string) map[string]string {
+		labels := map[string]string{
+			"ci.openshift.io/metadata.org":    "org",
+			"ci.openshift.io/metadata.repo":   "repo",
+			"ci.openshift.io/metadata.branch": "branch",
+			"ci.openshift.io/metadata.target": "e2e",
+			"created-by-ci":                   "true",
+		}
+		for k, v := range extra {
+			labels[k] = v
+		}
+		return labels
+	}
+	base := api.Metadata{Org: "org", Repo: "repo", Branch: "branch"}
+	var testCases = []struct {
+		name     string
+		pod      metav1.ObjectMeta
+		expected FullMetadata
+	}{
+		{
+			name:     "step pod",
+			pod:      metav1.ObjectMeta{Name: "e2e-install", Labels: labels(map[string]string{"ci.openshift.io/metadata.step": "install"})},
+			expected: FullMetadata{Metadata: base, Target: "e2e", Step: "install", Pod: "e2e-install", Container: "test"},
+		},
+		{
+			name: "build pod does not differ by target",
+			pod: metav1.ObjectMeta{
+				Name:        "src-build",
+				Labels:      labels(nil),
+				Annotations: map[string]string{buildv1.BuildAnnotation: "src"},
+			},
+			expected: FullMetadata{Metadata: base, Pod: "src-build", Container: "test"},
+		},
+		{
+			name:     "release pod does not differ by target",
+			pod:      metav1.ObjectMeta{Name: "release-latest", Labels: labels(map[string]string{"ci.openshift.io/release": "latest"})},
+			expected: FullMetadata{Metadata: base, Pod: "release-latest", Container: "test"},
+		},
+		{
+			name:     "RPM repo pod with a generated name",
+			pod:      metav1.ObjectMeta{GenerateName: "rpm-repo-5d88b6c7f-", Labels: labels(map[string]string{"app": "rpm-repo"})},
+			expected: FullMetadata{Metadata: base, Container: "test"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := metadataFor(&corev1.Pod{ObjectMeta: testCase.pod}, "test")
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("got incorrect metadata: %v", diff)
+			}
+		})
+	}
+}
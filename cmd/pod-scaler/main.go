@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -14,6 +15,8 @@ import (
 	"google.golang.org/api/option"
 	"gopkg.in/fsnotify.v1"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/transport"
@@ -26,6 +29,7 @@ import (
 	buildclientset "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 
+	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/util"
 )
 
@@ -33,6 +37,7 @@ type options struct {
 	mode string
 	producerOptions
 	consumerOptions
+	recommendOptions
 
 	instrumentationOptions prowflagutil.InstrumentationOptions
 
@@ -51,10 +56,23 @@ type producerOptions struct {
 }
 
 type consumerOptions struct {
-	port   int
-	uiPort int
+	port    int
+	uiPort  int
+	apiPort int
 
 	certDir string
+
+	cpuCeiling    string
+	memoryCeiling string
+}
+
+type recommendOptions struct {
+	org     string
+	repo    string
+	branch  string
+	variant string
+	target  string
+	step    string
 }
 
 func bindOptions(fs *flag.FlagSet) *options {
@@ -66,6 +84,15 @@ func bindOptions(fs *flag.FlagSet) *options {
 	fs.BoolVar(&o.once, "produce-once", false, "Query Prometheus and refresh cached data only once before exiting.")
 	fs.IntVar(&o.port, "port", 0, "Port to serve admission webhooks on.")
 	fs.IntVar(&o.uiPort, "ui-port", 0, "Port to serve frontend on.")
+	fs.IntVar(&o.apiPort, "api-port", 0, "Port to serve the resource recommendation API on.")
+	fs.StringVar(&o.org, "org", "", "Org of the ci-operator configuration to recommend resources for.")
+	fs.StringVar(&o.repo, "repo", "", "Repo of the ci-operator configuration to recommend resources for.")
+	fs.StringVar(&o.branch, "branch", "", "Branch of the ci-operator configuration to recommend resources for.")
+	fs.StringVar(&o.variant, "variant", "", "Variant of the ci-operator configuration to recommend resources for.")
+	fs.StringVar(&o.target, "target", "", "Test or image target to recommend resources for.")
+	fs.StringVar(&o.step, "step", "", "Multi-stage step to recommend resources for, if any.")
+	fs.StringVar(&o.cpuCeiling, "cpu-ceiling", "10", "Maximum CPU request the admission webhook will raise containers to.")
+	fs.StringVar(&o.memoryCeiling, "memory-ceiling", "20Gi", "Maximum memory request the admission webhook will raise containers to.")
 	fs.StringVar(&o.certDir, "serving-cert-dir", "", "Path to directory with serving certificate and key for the admission webhook server.")
 	fs.StringVar(&o.loglevel, "loglevel", "debug", "Logging level.")
 	fs.StringVar(&o.logStyle, "log-style", "json", "Logging style: json or text.")
@@ -98,8 +125,23 @@ func (o *options) validate() error {
 		if o.certDir == "" {
 			return errors.New("--serving-cert-dir is required")
 		}
+		for flag, value := range map[string]string{"cpu-ceiling": o.cpuCeiling, "memory-ceiling": o.memoryCeiling} {
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return fmt.Errorf("--%s is invalid: %w", flag, err)
+			}
+		}
+	case "consumer.api":
+		if o.apiPort == 0 {
+			return errors.New("--api-port is required")
+		}
+	case "recommend":
+		for flag, value := range map[string]string{"org": o.org, "repo": o.repo, "branch": o.branch, "target": o.target} {
+			if value == "" {
+				return fmt.Errorf("--%s is required", flag)
+			}
+		}
 	default:
-		return errors.New("--mode must be either \"producer\", \"consumer.ui\", or \"consumer.admission\"")
+		return errors.New("--mode must be either \"producer\", \"consumer.ui\", \"consumer.admission\", \"consumer.api\", or \"recommend\"")
 	}
 	if o.cacheDir == "" {
 		if o.cacheBucket == "" {
@@ -163,7 +205,12 @@ func main() {
 	case "consumer.ui":
 		// TODO
 	case "consumer.admission":
-		mainAdmission(opts)
+		mainAdmission(opts, cache)
+	case "consumer.api":
+		go serveRecommendations(opts.apiPort, opts.instrumentationOptions.HealthPort, cache)
+	case "recommend":
+		mainRecommend(opts, cache)
+		return
 	}
 	interrupts.WaitForGracefulShutdown()
 }
@@ -209,7 +256,7 @@ func mainProduce(opts *options, cache cache) {
 	go produce(clients, cache, opts.ignoreLatest, opts.once)
 }
 
-func mainAdmission(opts *options) {
+func mainAdmission(opts *options, cache cache) {
 	restConfig, err := rest.InClusterConfig()
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to load in-cluster config.")
@@ -218,5 +265,29 @@ func mainAdmission(opts *options) {
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to construct client.")
 	}
-	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client)
+	resources := &resourceMutator{
+		data: loadRecommendationData(cache, logrus.WithField("component", "admission")),
+		ceilings: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(opts.cpuCeiling),
+			corev1.ResourceMemory: resource.MustParse(opts.memoryCeiling),
+		},
+	}
+	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client, resources)
+}
+
+func mainRecommend(opts *options, cache cache) {
+	data := &recommendationData{}
+	if err := data.load(cache); err != nil {
+		logrus.WithError(err).Fatal("Failed to load cached data.")
+	}
+	recommendations := data.recommend(RecommendationQuery{
+		Metadata: api.Metadata{Org: opts.org, Repo: opts.repo, Branch: opts.branch, Variant: opts.variant},
+		Target:   opts.target,
+		Step:     opts.step,
+	})
+	raw, err := json.MarshalIndent(recommendations, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to marshal recommendations.")
+	}
+	fmt.Println(string(raw))
 }
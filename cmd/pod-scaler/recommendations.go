@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openhistogram/circonusllhist"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/test-infra/prow/interrupts"
+	"k8s.io/test-infra/prow/pjutil"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// recommendationPercentile is the percentile of historical usage that
+	// we recommend resource requests for.
+	recommendationPercentile = 0.8
+	// recommendationBuffer is the factor applied to historical usage to
+	// leave room for variance between executions.
+	recommendationBuffer = 1.2
+)
+
+// RecommendationQuery identifies the containers to recommend resources for.
+// Containers that ran outside of a multi-stage step are identified by an
+// empty step.
+type RecommendationQuery struct {
+	api.Metadata `json:",inline"`
+	Target       string `json:"target"`
+	Step         string `json:"step,omitempty"`
+}
+
+func (q RecommendationQuery) matches(meta FullMetadata) bool {
+	return q.Metadata == meta.Metadata && q.Target == meta.Target && q.Step == meta.Step
+}
+
+// Recommendation holds resources we recommend a container to configure
+// along with the data they are based on.
+type Recommendation struct {
+	Container string                         `json:"container"`
+	Requests  corev1.ResourceList            `json:"requests"`
+	Limits    corev1.ResourceList            `json:"limits,omitempty"`
+	Basis     map[string]RecommendationBasis `json:"basis"`
+}
+
+// RecommendationBasis describes the historical data used to recommend the
+// amount of one resource.
+type RecommendationBasis struct {
+	// Percentile is the percentile of historical usage used for the request.
+	Percentile float64 `json:"percentile"`
+	// Samples is the number of samples in the historical usage.
+	Samples uint64 `json:"samples"`
+}
+
+// recommend determines resources for every container whose metadata matches,
+// from the CPU and memory usage data.
+func recommend(matches func(FullMetadata) bool, cpu, memory *CachedQuery) []Recommendation {
+	byContainer := map[string]*Recommendation{}
+	for _, metric := range []struct {
+		name     corev1.ResourceName
+		data     *CachedQuery
+		quantity func(float64) *resource.Quantity
+		limit    bool
+	}{
+		{
+			name: corev1.ResourceCPU,
+			data: cpu,
+			quantity: func(cores float64) *resource.Quantity {
+				return resource.NewMilliQuantity(int64(cores*1000), resource.DecimalSI)
+			},
+		},
+		{
+			name: corev1.ResourceMemory,
+			data: memory,
+			quantity: func(bytes float64) *resource.Quantity {
+				return resource.NewQuantity(int64(bytes), resource.BinarySI)
+			},
+			// processes exceeding memory limits are killed, so we only
+			// recommend a limit that accommodates every historical execution
+			limit: true,
+		},
+	} {
+		if metric.data == nil {
+			continue
+		}
+		histograms := map[string]*circonusllhist.Histogram{}
+		for meta, fingerprints := range metric.data.DataByMetaData {
+			if !matches(meta) {
+				continue
+			}
+			hist, ok := histograms[meta.Container]
+			if !ok {
+				hist = circonusllhist.New(circonusllhist.NoLookup())
+				histograms[meta.Container] = hist
+			}
+			for _, fingerprint := range fingerprints {
+				if data, ok := metric.data.Data[fingerprint]; ok {
+					hist.Merge(data.Histogram())
+				}
+			}
+		}
+		for container, hist := range histograms {
+			if hist.Count() == 0 {
+				continue
+			}
+			recommendation, ok := byContainer[container]
+			if !ok {
+				recommendation = &Recommendation{
+					Container: container,
+					Requests:  corev1.ResourceList{},
+					Basis:     map[string]RecommendationBasis{},
+				}
+				byContainer[container] = recommendation
+			}
+			recommendation.Requests[metric.name] = *metric.quantity(hist.ValueAtQuantile(recommendationPercentile) * recommendationBuffer)
+			if metric.limit {
+				if recommendation.Limits == nil {
+					recommendation.Limits = corev1.ResourceList{}
+				}
+				recommendation.Limits[metric.name] = *metric.quantity(hist.Max() * recommendationBuffer)
+			}
+			recommendation.Basis[string(metric.name)] = RecommendationBasis{Percentile: recommendationPercentile, Samples: hist.Count()}
+		}
+	}
+	var recommendations []Recommendation
+	for _, recommendation := range byContainer {
+		recommendations = append(recommendations, *recommendation)
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Container < recommendations[j].Container
+	})
+	return recommendations
+}
+
+// recommendationData holds the cached usage data we recommend resources from.
+type recommendationData struct {
+	lock sync.RWMutex
+	// data holds cached queries by their name, like `steps/<metric>`
+	data map[string]*CachedQuery
+}
+
+// prefixFor determines which queries hold data for containers in the step.
+func prefixFor(step string) string {
+	if step != "" {
+		return "steps"
+	}
+	return "pods"
+}
+
+func (d *recommendationData) recommend(query RecommendationQuery) []Recommendation {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	prefix := prefixFor(query.Step)
+	return recommend(query.matches, d.data[prefix+"/"+MetricNameCPUUsage], d.data[prefix+"/"+MetricNameMemoryWorkingSet])
+}
+
+// recommendFor determines resources for the one container identified by the
+// metadata, if we have any data for it.
+func (d *recommendationData) recommendFor(meta FullMetadata) (Recommendation, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	prefix := prefixFor(meta.Step)
+	recommendations := recommend(func(other FullMetadata) bool {
+		return meta == other
+	}, d.data[prefix+"/"+MetricNameCPUUsage], d.data[prefix+"/"+MetricNameMemoryWorkingSet])
+	if len(recommendations) != 1 {
+		return Recommendation{}, false
+	}
+	return recommendations[0], true
+}
+
+// load reads all cached usage data from the loader.
+func (d *recommendationData) load(loader loader) error {
+	data := map[string]*CachedQuery{}
+	for _, prefix := range []string{"pods", "steps"} {
+		for _, metric := range []string{MetricNameCPUUsage, MetricNameMemoryWorkingSet} {
+			name := prefix + "/" + metric
+			query, err := loadCache(loader, name, logrus.WithField("metric", name))
+			if errors.Is(err, notExist{}) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			data[name] = query
+		}
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.data = data
+	return nil
+}
+
+func queryFromRequest(r *http.Request) (RecommendationQuery, error) {
+	values := r.URL.Query()
+	query := RecommendationQuery{
+		Metadata: api.Metadata{
+			Org:     values.Get("org"),
+			Repo:    values.Get("repo"),
+			Branch:  values.Get("branch"),
+			Variant: values.Get("variant"),
+		},
+		Target: values.Get("target"),
+		Step:   values.Get("step"),
+	}
+	for field, value := range map[string]string{"org": query.Org, "repo": query.Repo, "branch": query.Branch, "target": query.Target} {
+		if value == "" {
+			return query, fmt.Errorf("the %q query parameter is required", field)
+		}
+	}
+	return query, nil
+}
+
+func recommendationHandler(data *recommendationData) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		query, err := queryFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		raw, err := json.Marshal(data.recommend(query))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(raw); err != nil {
+			logrus.WithError(err).Warn("Failed to write response.")
+		}
+	}
+}
+
+// loadRecommendationData loads cached usage data and refreshes it periodically.
+func loadRecommendationData(loader loader, logger *logrus.Entry) *recommendationData {
+	data := &recommendationData{}
+	if err := data.load(loader); err != nil {
+		logger.WithError(err).Fatal("Failed to load cached data.")
+	}
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-interrupts.Context().Done():
+				return
+			case <-ticker.C:
+				if err := data.load(loader); err != nil {
+					logger.WithError(err).Error("Failed to refresh cached data.")
+				}
+			}
+		}
+	}()
+	return data
+}
+
+// serveRecommendations serves the recommendation API.
+func serveRecommendations(port, healthPort int, loader loader) {
+	logger := logrus.WithField("component", "recommendations")
+	data := loadRecommendationData(loader, logger)
+
+	health := pjutil.NewHealthOnPort(healthPort)
+	health.ServeReady()
+	mux := http.NewServeMux()
+	mux.Handle("/api/recommendations", recommendationHandler(data))
+	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: mux}
+	logger.Info("Serving recommendations.")
+	interrupts.ListenAndServe(server, 5*time.Second)
+}
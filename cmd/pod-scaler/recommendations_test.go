@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestRecommend(t *testing.T) {
+	histogram := func(values ...float64) *circonusllhist.HistogramWithoutLookups {
+		hist := circonusllhist.New(circonusllhist.NoLookup())
+		for _, value := range values {
+			if err := hist.RecordValue(value); err != nil {
+				t.Fatalf("failed to record value: %v", err)
+			}
+		}
+		return circonusllhist.NewHistogramWithoutLookups(hist)
+	}
+	meta := func(target, step, container string) FullMetadata {
+		return FullMetadata{
+			Metadata:  api.Metadata{Org: "org", Repo: "repo", Branch: "branch"},
+			Target:    target,
+			Step:      step,
+			Container: container,
+		}
+	}
+	cpu := &CachedQuery{
+		Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+			1: histogram(1, 1, 1, 1),
+			2: histogram(1, 1, 1, 1),
+			3: histogram(10),
+			4: histogram(0.1, 0.1),
+		},
+		DataByMetaData: map[FullMetadata][]model.Fingerprint{
+			meta("e2e", "install", "test"):    {1, 2},
+			meta("e2e", "install", "sidecar"): {4},
+			meta("e2e", "gather", "test"):     {3},
+			meta("unit", "install", "test"):   {3},
+		},
+	}
+	memory := &CachedQuery{
+		Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+			1: histogram(1e9, 1e9, 2e9),
+		},
+		DataByMetaData: map[FullMetadata][]model.Fingerprint{
+			meta("e2e", "install", "test"): {1},
+		},
+	}
+	quantity := func(hist *circonusllhist.HistogramWithoutLookups, q float64, milli bool) resource.Quantity {
+		value := hist.Histogram().ValueAtQuantile(q) * recommendationBuffer
+		if milli {
+			return *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI)
+		}
+		return *resource.NewQuantity(int64(value), resource.BinarySI)
+	}
+	expected := []Recommendation{
+		{
+			Container: "sidecar",
+			Requests:  corev1.ResourceList{corev1.ResourceCPU: quantity(cpu.Data[4], recommendationPercentile, true)},
+			Basis:     map[string]RecommendationBasis{"cpu": {Percentile: recommendationPercentile, Samples: 2}},
+		},
+		{
+			Container: "test",
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    quantity(histogram(1, 1, 1, 1, 1, 1, 1, 1), recommendationPercentile, true),
+				corev1.ResourceMemory: quantity(memory.Data[1], recommendationPercentile, false),
+			},
+			Limits: corev1.ResourceList{corev1.ResourceMemory: quantity(memory.Data[1], 1, false)},
+			Basis: map[string]RecommendationBasis{
+				"cpu":    {Percentile: recommendationPercentile, Samples: 8},
+				"memory": {Percentile: recommendationPercentile, Samples: 3},
+			},
+		},
+	}
+	actual := recommend(RecommendationQuery{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "branch"}, Target: "e2e", Step: "install"}.matches, cpu, memory)
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected recommendations: %s", diff)
+	}
+}
+
+func TestRecommendationHandler(t *testing.T) {
+	data := &recommendationData{data: map[string]*CachedQuery{}}
+	for _, testCase := range []struct {
+		name, method, url string
+		expected          int
+	}{
+		{name: "valid query", method: http.MethodGet, url: "/api/recommendations?org=org&repo=repo&branch=branch&target=e2e&step=install", expected: http.StatusOK},
+		{name: "missing target", method: http.MethodGet, url: "/api/recommendations?org=org&repo=repo&branch=branch", expected: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, url: "/api/recommendations?org=org&repo=repo&branch=branch&target=e2e", expected: http.StatusMethodNotAllowed},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			recommendationHandler(data).ServeHTTP(recorder, httptest.NewRequest(testCase.method, testCase.url, nil))
+			if recorder.Code != testCase.expected {
+				t.Errorf("expected status %d, got %d: %s", testCase.expected, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kataras/tablewriter"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/deprecateregistry"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+type options struct {
+	registryPath  string
+	configPath    string
+	allowlistPath string
+	allowNew      bool
+	prune         bool
+	printStats    bool
+	checks        bool
+
+	help bool
+}
+
+func bindOptions(fs *flag.FlagSet) *options {
+	opt := &options{}
+
+	fs.StringVar(&opt.registryPath, "registry", "", "Path to the step registry directory")
+	fs.StringVar(&opt.configPath, "config", "", "Path to the ci-operator configuration directory")
+	fs.StringVar(&opt.allowlistPath, "allowlist-path", "", "Path to registry deprecation allowlist")
+	fs.BoolVar(&opt.allowNew, "allow-new", false, "If set, add new users of deprecated components to the allowlist instead of failing validation")
+	fs.BoolVar(&opt.prune, "prune", false, "If set, remove from allowlist all jobs that no longer use a deprecated component")
+	fs.BoolVar(&opt.printStats, "stats", false, "If true, print deprecated component usage stats")
+	fs.BoolVar(&opt.checks, "checks", true, "If true (default), validate allowlist for correctness after update")
+
+	return opt
+}
+
+func (o *options) validate() error {
+	for param, value := range map[string]string{
+		"--registry":       o.registryPath,
+		"--config":         o.configPath,
+		"--allowlist-path": o.allowlistPath,
+	} {
+		if value == "" {
+			return fmt.Errorf("mandatory argument %s was not set", param)
+		}
+	}
+
+	return nil
+}
+
+func main() {
+	opt := bindOptions(flag.CommandLine)
+	flag.Parse()
+
+	if opt.help {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	if err := opt.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid parameters")
+	}
+
+	references, chains, workflows, _, _, _, deprecations, err := load.Registry(opt.registryPath, false)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load the step registry")
+	}
+	graph, err := registry.NewGraph(references, chains, workflows)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to build the step registry graph")
+	}
+	byOrgRepo, err := load.FromPathByOrgRepo(opt.configPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load ci-operator configurations")
+	}
+	var configs []api.ReleaseBuildConfiguration
+	for _, byRepo := range byOrgRepo {
+		for _, repoConfigs := range byRepo {
+			configs = append(configs, repoConfigs...)
+		}
+	}
+
+	enforcer, err := deprecateregistry.NewEnforcer(opt.allowlistPath, opt.allowNew)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to initialize registry deprecator")
+	}
+
+	enforcer.ProcessConfigs(graph, workflows, deprecations, configs)
+
+	if opt.prune {
+		enforcer.Prune()
+	}
+
+	if opt.printStats {
+		header, footer, data := enforcer.Stats()
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(header)
+		table.SetFooter(footer)
+		table.AppendBulk(data)
+		table.Render()
+	}
+
+	if opt.checks {
+		if violations := enforcer.Validate(); len(violations) > 0 {
+			fmt.Printf("ERROR: Registry deprecation allowlist has errors:\n")
+			for idx, violation := range violations {
+				fmt.Printf("\nERROR: %d)\n", idx+1)
+				fmt.Printf("%s\n", violation)
+			}
+			fmt.Println()
+			logrus.Fatalf("Registry deprecation allowlist failed validation")
+		}
+	}
+
+	if err := enforcer.SaveAllowlist(opt.allowlistPath); err != nil {
+		logrus.WithError(err).Fatal("Failed to save registry deprecation allowlist")
+	}
+}
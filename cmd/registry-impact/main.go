@@ -0,0 +1,147 @@
+// registry-impact lists everything that would execute a step registry
+// component: the workflows and chains that reach it and the tests in
+// ci-operator configurations, along with the Prow jobs generated for them.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+type options struct {
+	registryPath  string
+	configPath    string
+	componentType string
+	name          string
+	output        string
+}
+
+func gatherOptions() (options, error) {
+	o := options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.registryPath, "registry", "", "Path to the step registry directory.")
+	fs.StringVar(&o.configPath, "config", "", "Path to the ci-operator configuration directory.")
+	fs.StringVar(&o.componentType, "type", registry.Reference.String(), "Type of the registry component: reference, chain or workflow.")
+	fs.StringVar(&o.name, "name", "", "Name of the registry component.")
+	fs.StringVar(&o.output, "output", "text", "Output format, either 'text' or 'json'.")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return options{}, fmt.Errorf("could not parse input: %w", err)
+	}
+	return o, nil
+}
+
+func (o *options) validate() error {
+	if o.registryPath == "" {
+		return errors.New("--registry is required")
+	}
+	if o.configPath == "" {
+		return errors.New("--config is required")
+	}
+	if o.name == "" {
+		return errors.New("--name is required")
+	}
+	if _, err := registry.ParseType(o.componentType); err != nil {
+		return fmt.Errorf("invalid --type: %w", err)
+	}
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("invalid --output %q, expected 'text' or 'json'", o.output)
+	}
+	return nil
+}
+
+func printUsage(out io.Writer, usage registry.Usage) {
+	fmt.Fprintf(out, "%s %s is used by %d tests\n", usage.Type, usage.Name, len(usage.Tests))
+	for _, section := range []struct {
+		title string
+		names []string
+	}{
+		{title: "Workflows", names: usage.Workflows},
+		{title: "Chains", names: usage.Chains},
+	} {
+		if len(section.names) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s reaching it:\n", section.title)
+		for _, name := range section.names {
+			fmt.Fprintf(out, "  %s\n", name)
+		}
+	}
+	if len(usage.TestsByRepo) > 0 {
+		var repos []string
+		for repo := range usage.TestsByRepo {
+			repos = append(repos, repo)
+		}
+		sort.Slice(repos, func(i, j int) bool {
+			if usage.TestsByRepo[repos[i]] == usage.TestsByRepo[repos[j]] {
+				return repos[i] < repos[j]
+			}
+			return usage.TestsByRepo[repos[i]] > usage.TestsByRepo[repos[j]]
+		})
+		fmt.Fprintln(out, "\nTests by repository:")
+		for _, repo := range repos {
+			fmt.Fprintf(out, "  %5d %s\n", usage.TestsByRepo[repo], repo)
+		}
+	}
+	if len(usage.Tests) > 0 {
+		fmt.Fprintln(out, "\nJobs:")
+		for _, test := range usage.Tests {
+			fmt.Fprintf(out, "  %s\n", test.JobName)
+		}
+	}
+}
+
+func main() {
+	o, err := gatherOptions()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to gather options.")
+	}
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options.")
+	}
+	componentType, _ := registry.ParseType(o.componentType)
+
+	references, chains, workflows, _, _, _, _, err := load.Registry(o.registryPath, false)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load the step registry.")
+	}
+	graph, err := registry.NewGraph(references, chains, workflows)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to build the step registry graph.")
+	}
+	node, ok := graph.Lookup(componentType, o.name)
+	if !ok {
+		logrus.Fatalf("No %s named %s in the step registry.", componentType, o.name)
+	}
+	byOrgRepo, err := load.FromPathByOrgRepo(o.configPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load ci-operator configurations.")
+	}
+	var configs []api.ReleaseBuildConfiguration
+	for _, byRepo := range byOrgRepo {
+		for _, repoConfigs := range byRepo {
+			configs = append(configs, repoConfigs...)
+		}
+	}
+
+	usage := registry.UsageOf(node, workflows, configs)
+	if o.output == "json" {
+		raw, err := json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to marshal usage.")
+		}
+		fmt.Println(string(raw))
+		return
+	}
+	printUsage(os.Stdout, usage)
+}
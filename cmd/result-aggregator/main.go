@@ -43,6 +43,8 @@ type options struct {
 	address     string
 	gracePeriod time.Duration
 	passwdFile  string
+	storeFile   string
+	retention   time.Duration
 }
 
 func gatherOptions() (options, error) {
@@ -52,6 +54,8 @@ func gatherOptions() (options, error) {
 	fs.StringVar(&o.address, "address", ":8080", "Address to run server on")
 	fs.DurationVar(&o.gracePeriod, "gracePeriod", time.Second*10, "Grace period for server shutdown")
 	fs.StringVar(&o.passwdFile, "passwd-file", "", "Authenticate against a file. Each line of the file is with the form `<username>:<password>`.")
+	fs.StringVar(&o.storeFile, "store-file", "", "File to persist reported results in. If unset, results are only kept in memory.")
+	fs.DurationVar(&o.retention, "retention", 14*24*time.Hour, "How long to keep reported results for.")
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
 	}
@@ -112,6 +116,8 @@ func loginHandler(validator validator, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user, pass, ok := r.BasicAuth()
 		if !ok || !validator.Validate(user, pass) {
+			// lets browsers prompt for credentials on the failures page
+			w.Header().Set("WWW-Authenticate", `Basic realm="result-aggregator"`)
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
@@ -119,7 +125,7 @@ func loginHandler(validator validator, next http.Handler) http.Handler {
 	})
 }
 
-func handleCIOperatorResult() http.HandlerFunc {
+func handleCIOperatorResult(store *recordStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -141,6 +147,9 @@ func handleCIOperatorResult() http.HandlerFunc {
 		}
 
 		withErrorRate(request)
+		if err := store.add(*request); err != nil {
+			log.WithError(err).Warn("Failed to persist result.")
+		}
 
 		w.WriteHeader(http.StatusOK)
 
@@ -166,7 +175,15 @@ func main() {
 
 	validator := &multi{delegates: []validator{&passwdFile{file: o.passwdFile}}}
 
-	http.Handle("/result", loginHandler(validator, handleCIOperatorResult()))
+	store, err := newRecordStore(o.storeFile, o.retention, time.Now)
+	if err != nil {
+		log.WithError(err).Fatal("failed to load the result store")
+	}
+
+	http.Handle("/result", loginHandler(validator, handleCIOperatorResult(store)))
+	http.Handle("/api/failures", loginHandler(validator, handleFailures(store)))
+	http.Handle("/api/reasons", loginHandler(validator, handleReasons(store)))
+	http.Handle("/failures", loginHandler(validator, handleFailuresPage(store)))
 	metrics.ExposeMetrics("result-aggregator", prowConfig.PushGateway{}, flagutil.DefaultMetricsPort)
 
 	interrupts.ListenAndServe(&http.Server{Addr: o.address}, o.gracePeriod)
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/results"
+)
+
+const defaultWindow = 24 * time.Hour
+
+// filterFromRequest determines which failures to query from the parameters:
+// `job`, `cluster`, `reason` and `window`, a duration counting back from now.
+func filterFromRequest(r *http.Request, now time.Time) (recordFilter, error) {
+	values := r.URL.Query()
+	window := defaultWindow
+	if raw := values.Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return recordFilter{}, fmt.Errorf("invalid window: %w", err)
+		}
+		window = parsed
+	}
+	return recordFilter{
+		JobName: values.Get("job"),
+		Cluster: values.Get("cluster"),
+		State:   results.StateFailed,
+		Reason:  values.Get("reason"),
+		Since:   now.Add(-window),
+	}, nil
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(raw); err != nil {
+		logrus.WithError(err).Warn("Failed to write response.")
+	}
+}
+
+// handleFailures serves the failures matching the query.
+func handleFailures(store *recordStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := filterFromRequest(r, store.now())
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		writeJSON(w, store.query(filter))
+	}
+}
+
+// handleReasons serves the number of failures matching the query for each
+// reason one level below the queried one.
+func handleReasons(store *recordStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := filterFromRequest(r, store.now())
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		writeJSON(w, store.reasons(filter))
+	}
+}
+
+const failuresPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>ci-operator failures</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>ci-operator failures</h1>
+<form method="GET" action="/failures">
+<label>Job <input name="job" value="{{ .Filter.JobName }}"></label>
+<label>Cluster <input name="cluster" value="{{ .Filter.Cluster }}"></label>
+<label>Reason <input name="reason" value="{{ .Filter.Reason }}"></label>
+<label>Window <input name="window" value="{{ .Window }}"></label>
+<input type="submit" value="Filter">
+</form>
+<h2>Reasons{{ if .Filter.Reason }} under <code>{{ .Filter.Reason }}</code>{{ end }}</h2>
+<table>
+<tr><th>Reason</th><th>Failures</th></tr>
+{{ range .Reasons }}<tr><td><a href="{{ $.DrillDown .Reason }}">{{ .Reason }}</a></td><td>{{ .Count }}</td></tr>
+{{ end }}</table>
+<h2>Failures</h2>
+<table>
+<tr><th>Time</th><th>Job</th><th>Type</th><th>Cluster</th><th>Reason</th></tr>
+{{ range .Failures }}<tr><td>{{ .Timestamp.Format "2006-01-02 15:04:05" }}</td><td>{{ .JobName }}</td><td>{{ .Type }}</td><td>{{ .Cluster }}</td><td>{{ .Reason }}</td></tr>
+{{ end }}</table>
+</body>
+</html>
+`
+
+var failuresTemplate = template.Must(template.New("failures").Parse(failuresPage))
+
+type failuresPageData struct {
+	Filter   recordFilter
+	Window   string
+	Reasons  []reasonCount
+	Failures []record
+}
+
+// DrillDown links to the page for a more specific reason.
+func (d failuresPageData) DrillDown(reason string) string {
+	values := url.Values{}
+	values.Set("job", d.Filter.JobName)
+	values.Set("cluster", d.Filter.Cluster)
+	values.Set("window", d.Window)
+	values.Set("reason", reason)
+	return "/failures?" + values.Encode()
+}
+
+// handleFailuresPage serves a page to drill down into failures by reason.
+func handleFailuresPage(store *recordStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := filterFromRequest(r, store.now())
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		data := failuresPageData{
+			Filter:   filter,
+			Window:   defaultWindow.String(),
+			Reasons:  store.reasons(filter),
+			Failures: store.query(filter),
+		}
+		if window := r.URL.Query().Get("window"); window != "" {
+			data.Window = window
+		}
+		w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+		if err := failuresTemplate.Execute(w, data); err != nil {
+			logrus.WithError(err).Warn("Failed to render page.")
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/results"
+)
+
+// record is a result reported to us, as persisted in the store.
+type record struct {
+	Timestamp time.Time `json:"timestamp"`
+	results.Request
+}
+
+// recordFilter selects records in a time window, optionally narrowed down
+// by job, cluster, state and a prefix of the reason hierarchy.
+type recordFilter struct {
+	JobName string
+	Cluster string
+	State   string
+	// Reason matches records whose reason is this or more specific.
+	Reason string
+	Since  time.Time
+	Until  time.Time
+}
+
+func (f recordFilter) matches(r record) bool {
+	if f.JobName != "" && r.JobName != f.JobName {
+		return false
+	}
+	if f.Cluster != "" && r.Cluster != f.Cluster {
+		return false
+	}
+	if f.State != "" && r.State != f.State {
+		return false
+	}
+	if f.Reason != "" && r.Reason != f.Reason && !strings.HasPrefix(r.Reason, f.Reason+":") {
+		return false
+	}
+	if !f.Since.IsZero() && r.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && r.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// reasonCount is the number of records for one level of the reason
+// hierarchy, like `executing_graph:step_failed`.
+type reasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// recordStore keeps reported results in memory, appending them to a file
+// so that they survive restarts. Records older than the retention period
+// are dropped from memory as new ones arrive, and from the file when the
+// store is loaded.
+type recordStore struct {
+	lock      sync.RWMutex
+	records   []record
+	retention time.Duration
+	file      *os.File
+	now       func() time.Time
+}
+
+// newRecordStore loads records from the file, if one is given, and
+// appends new records to it.
+func newRecordStore(path string, retention time.Duration, now func() time.Time) (*recordStore, error) {
+	store := &recordStore{retention: retention, now: now}
+	if path == "" {
+		return store, nil
+	}
+	existing, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not open store: %w", err)
+	}
+	if err == nil {
+		cutoff := store.now().Add(-retention)
+		decoder := json.NewDecoder(existing)
+		for {
+			var r record
+			if err := decoder.Decode(&r); err == io.EOF {
+				break
+			} else if err != nil {
+				existing.Close()
+				return nil, fmt.Errorf("could not parse record in store: %w", err)
+			}
+			if r.Timestamp.After(cutoff) {
+				store.records = append(store.records, r)
+			}
+		}
+		existing.Close()
+	}
+	// rewrite the file to drop records outside of the retention period; the
+	// new file replaces the old one only once it is complete, so that a crash
+	// does not lose the records
+	if store.file, err = ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*"); err != nil {
+		return nil, fmt.Errorf("could not create store: %w", err)
+	}
+	for _, r := range store.records {
+		if err := store.write(r); err != nil {
+			store.discard()
+			return nil, err
+		}
+	}
+	if err := store.file.Sync(); err != nil {
+		store.discard()
+		return nil, fmt.Errorf("could not write store: %w", err)
+	}
+	if err := os.Rename(store.file.Name(), path); err != nil {
+		store.discard()
+		return nil, fmt.Errorf("could not replace store: %w", err)
+	}
+	return store, nil
+}
+
+// discard removes a partially written store file.
+func (s *recordStore) discard() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+func (s *recordStore) write(r record) error {
+	if s.file == nil {
+		return nil
+	}
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("could not marshal record: %w", err)
+	}
+	if _, err := s.file.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("could not write record: %w", err)
+	}
+	return nil
+}
+
+func (s *recordStore) add(request results.Request) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	r := record{Timestamp: s.now(), Request: request}
+	// records are ordered by time, so expired ones are at the front
+	cutoff := r.Timestamp.Add(-s.retention)
+	expired := sort.Search(len(s.records), func(i int) bool {
+		return s.records[i].Timestamp.After(cutoff)
+	})
+	s.records = append(s.records[expired:], r)
+	return s.write(r)
+}
+
+// query returns the matching records, most recent first.
+func (s *recordStore) query(filter recordFilter) []record {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var matching []record
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if filter.matches(s.records[i]) {
+			matching = append(matching, s.records[i])
+		}
+	}
+	return matching
+}
+
+// reasons counts the matching records by the next level of the reason
+// hierarchy below the filter's reason, most frequent first.
+func (s *recordStore) reasons(filter recordFilter) []reasonCount {
+	counts := map[string]int{}
+	depth := 1
+	if filter.Reason != "" {
+		depth = len(strings.Split(filter.Reason, ":")) + 1
+	}
+	for _, r := range s.query(filter) {
+		parts := strings.Split(r.Reason, ":")
+		if len(parts) > depth {
+			parts = parts[:depth]
+		}
+		counts[strings.Join(parts, ":")]++
+	}
+	var ret []reasonCount
+	for reason, count := range counts {
+		ret = append(ret, reasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count == ret[j].Count {
+			return ret[i].Reason < ret[j].Reason
+		}
+		return ret[i].Count > ret[j].Count
+	})
+	return ret
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/results"
+)
+
+func TestRecordStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store, err := newRecordStore(path, 48*time.Hour, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, report := range []struct {
+		age     time.Duration
+		request results.Request
+	}{
+		{age: 72 * time.Hour, request: results.Request{JobName: "old", State: results.StateFailed, Reason: "executing_graph:step_failed", Cluster: "a"}},
+		{age: 36 * time.Hour, request: results.Request{JobName: "job", State: results.StateFailed, Reason: "executing_graph:step_failed:building_image", Cluster: "a"}},
+		{age: 3 * time.Hour, request: results.Request{JobName: "job", State: results.StateSucceeded, Reason: "unknown", Cluster: "a"}},
+		{age: 2 * time.Hour, request: results.Request{JobName: "job", State: results.StateFailed, Reason: "executing_graph:step_failed:utilizing_lease", Cluster: "b"}},
+		{age: time.Hour, request: results.Request{JobName: "other", State: results.StateFailed, Reason: "executing_graph:step_failed:building_image", Cluster: "a"}},
+		{age: 0, request: results.Request{JobName: "other", State: results.StateFailed, Reason: "loading_config", Cluster: "a"}},
+	} {
+		now = time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC).Add(-report.age)
+		if err := store.add(report.request); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// records are reloaded from the file, dropping expired ones
+	reloaded, err := newRecordStore(path, 48*time.Hour, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(store.records, reloaded.records); diff != "" {
+		t.Errorf("reloaded records differ: %s", diff)
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		filter   recordFilter
+		expected []reasonCount
+	}{
+		{
+			name:     "top level reasons",
+			filter:   recordFilter{State: results.StateFailed},
+			expected: []reasonCount{{Reason: "executing_graph", Count: 3}, {Reason: "loading_config", Count: 1}},
+		},
+		{
+			name:     "drill down into a reason",
+			filter:   recordFilter{State: results.StateFailed, Reason: "executing_graph:step_failed"},
+			expected: []reasonCount{{Reason: "executing_graph:step_failed:building_image", Count: 2}, {Reason: "executing_graph:step_failed:utilizing_lease", Count: 1}},
+		},
+		{
+			name:     "by job and cluster in a window",
+			filter:   recordFilter{State: results.StateFailed, JobName: "job", Cluster: "a", Since: now.Add(-24 * time.Hour)},
+			expected: nil,
+		},
+		{
+			name:     "by job",
+			filter:   recordFilter{State: results.StateFailed, JobName: "job"},
+			expected: []reasonCount{{Reason: "executing_graph", Count: 2}},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, store.reasons(testCase.filter)); diff != "" {
+				t.Errorf("unexpected reasons: %s", diff)
+			}
+		})
+	}
+
+	recorder := httptest.NewRecorder()
+	handleFailures(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/failures?job=other&window=2h", nil))
+	if recorder.Code != http.StatusOK || strings.Count(recorder.Body.String(), `"job_name":"other"`) != 2 {
+		t.Errorf("unexpected response %d: %s", recorder.Code, recorder.Body.String())
+	}
+	recorder = httptest.NewRecorder()
+	handleFailuresPage(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/failures?reason=executing_graph", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "executing_graph:step_failed") {
+		t.Errorf("unexpected response %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestRecordStoreLongRecords(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store, err := newRecordStore(path, time.Hour, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := results.Request{JobName: "job", State: results.StateFailed, Reason: "executing_graph:" + strings.Repeat("a", 128*1024)}
+	if err := store.add(long); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := newRecordStore(path, time.Hour, clock)
+	if err != nil {
+		t.Fatalf("could not reload a store with a long record: %v", err)
+	}
+	if diff := cmp.Diff(store.records, reloaded.records); diff != "" {
+		t.Errorf("reloaded records differ: %s", diff)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "store.json" {
+		t.Errorf("expected only the store in the directory, got %v", files)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/getlantern/deepcopy"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/yaml"
 
@@ -101,6 +103,17 @@ type SecretItem struct {
 	Password    string              `json:"password,omitempty"`
 	Notes       string              `json:"notes,omitempty"`
 	Params      map[string][]string `json:"params,omitempty"`
+	// MaxAge is the age after which the fields, attachments and password of the
+	// item are regenerated when rotating secrets. Each of them is regenerated
+	// once it is older than MaxAge. Items without it are only generated when
+	// they do not exist yet.
+	MaxAge *metav1.Duration `json:"max_age,omitempty"`
+}
+
+// Expired determines whether a part of the item, last changed at the given
+// time, is older than the maximum age of the item
+func (si SecretItem) Expired(lastChanged, now time.Time) bool {
+	return si.MaxAge != nil && now.Sub(lastChanged) > si.MaxAge.Duration
 }
 
 func (si SecretItem) generateItemsFromParams() ([]SecretItem, error) {
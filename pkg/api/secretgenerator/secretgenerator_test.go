@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
@@ -28,6 +31,9 @@ func TestLoadConfigFromPath(t *testing.T) {
 		{
 			name: "two parameters with multiple values",
 		},
+		{
+			name: "max age with parameters",
+		},
 	}
 
 	for _, tc := range testcases {
@@ -51,3 +57,37 @@ func TestLoadConfigFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		name        string
+		maxAge      *metav1.Duration
+		lastChanged time.Time
+		expected    bool
+	}{
+		{
+			name:        "no max age",
+			lastChanged: now.AddDate(-1, 0, 0),
+		},
+		{
+			name:        "younger than max age",
+			maxAge:      &metav1.Duration{Duration: 24 * time.Hour},
+			lastChanged: now.Add(-time.Hour),
+		},
+		{
+			name:        "older than max age",
+			maxAge:      &metav1.Duration{Duration: 24 * time.Hour},
+			lastChanged: now.Add(-25 * time.Hour),
+			expected:    true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			item := SecretItem{ItemName: "item", MaxAge: tc.maxAge}
+			if actual := item.Expired(tc.lastChanged, now); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
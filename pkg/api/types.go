@@ -2,7 +2,10 @@ package api
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -662,6 +665,8 @@ type RegistryReference struct {
 	LiteralTestStep `json:",inline"`
 	// Documentation describes what the step being referenced does.
 	Documentation string `json:"documentation,omitempty"`
+	// Deprecation marks the step as deprecated, optional.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
 }
 
 // RegistryChainConfig is the struct that chain references are unmarshalled into.
@@ -682,6 +687,8 @@ type RegistryChain struct {
 	Environment []StepParameter `json:"env,omitempty"`
 	// Leases lists resources that should be acquired for the test.
 	Leases []StepLease `json:"leases,omitempty"`
+	// Deprecation marks the chain as deprecated, optional.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
 }
 
 // RegistryWorkflowConfig is the struct that workflow references are unmarshalled into.
@@ -698,6 +705,42 @@ type RegistryWorkflow struct {
 	Steps MultiStageTestConfiguration `json:"steps,omitempty"`
 	// Documentation describes what the workflow does.
 	Documentation string `json:"documentation,omitempty"`
+	// Deprecation marks the workflow as deprecated, optional.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+}
+
+// DeprecationDateFormat is the format of deprecation removal dates.
+const DeprecationDateFormat = "2006-01-02"
+
+// Deprecation marks a registry component as deprecated. Tests using it keep
+// working but are warned about the deprecation.
+type Deprecation struct {
+	// Message explains why the component is deprecated.
+	Message string `json:"message"`
+	// Replacement names the component to use instead, optional.
+	Replacement string `json:"replacement,omitempty"`
+	// RemovalDate is the date after which the component may be removed,
+	// formatted as YYYY-MM-DD, optional.
+	RemovalDate string `json:"removal_date,omitempty"`
+}
+
+// DeprecatedComponent identifies a deprecated registry component.
+type DeprecatedComponent struct {
+	// Type is the type of the component: reference, chain or workflow.
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Deprecation `json:",inline"`
+}
+
+func (d DeprecatedComponent) String() string {
+	message := fmt.Sprintf("registry %s %s is deprecated: %s", d.Type, d.Name, d.Message)
+	if d.Replacement != "" {
+		message += fmt.Sprintf("; use %s instead", d.Replacement)
+	}
+	if d.RemovalDate != "" {
+		message += fmt.Sprintf("; it will be removed after %s", d.RemovalDate)
+	}
+	return message
 }
 
 // RegistryObserverConfig is the struct that observer configs are unmarshalled into
@@ -726,7 +769,10 @@ type RegistryInfo struct {
 }
 
 // Observer is the configuration for an observer Pod that will run in parallel
-// with a multi-stage test job.
+// with a multi-stage test job. An observer is started with the first step that
+// requests it, or with the first step of the test if it was enabled without a
+// step requesting it, and is sent SIGTERM after the last step has finished.
+// Observer failures are reported but do not fail the test.
 type Observer struct {
 	// Name is the name of this observer
 	Name string `json:"name"`
@@ -795,6 +841,105 @@ type LiteralTestStep struct {
 	// RunAsScript defines if this step should be executed as a script mounted
 	// in the test container instead of being executed directly via bash
 	RunAsScript *bool `json:"run_as_script,omitempty"`
+	// Retry defines if and how this step should be executed again when it fails.
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// When defines the conditions under which this step is executed. The
+	// step is always executed if not set.
+	When *StepCondition `json:"when,omitempty"`
+	// ParallelGroup is the name of the group of steps this step is executed
+	// concurrently with. Steps in a group must be consecutive in a phase, and
+	// the group fails if any of them fails. Set for the steps of a test when
+	// it is resolved, registry references cannot set it.
+	ParallelGroup string `json:"parallel_group,omitempty"`
+}
+
+// MaxRetryAttempts is the largest number of attempts a retry policy may set.
+const MaxRetryAttempts = 10
+
+// MaxRetryBackoff caps the wait time between two attempts of a step.
+const MaxRetryBackoff = time.Hour
+
+// RetryPolicy defines how a failed step is retried. When filters are set,
+// only failures matching all of them are retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of times the step is executed, including
+	// the first execution.
+	Attempts uint `json:"attempts"`
+	// Backoff is how long to wait before the first retry. The wait time is
+	// doubled for every subsequent retry, up to MaxRetryBackoff.
+	Backoff *prowv1.Duration `json:"backoff,omitempty"`
+	// ExitCodes limits retries to failures where the step's process exited
+	// with one of these codes.
+	ExitCodes []int32 `json:"exit_codes,omitempty"`
+	// LogPattern limits retries to failures where the step's output matches
+	// this regular expression.
+	LogPattern string `json:"log_pattern,omitempty"`
+}
+
+// StepOutcome is the result of the steps executed before a step.
+type StepOutcome string
+
+const (
+	// StepOutcomeSuccess executes a step only if all steps before it succeeded.
+	StepOutcomeSuccess StepOutcome = "success"
+	// StepOutcomeFailure executes a step only if a step before it failed. Such
+	// steps are executed even after a failure in the `pre` or `test` phases
+	// stops the remaining steps of the phase.
+	StepOutcomeFailure StepOutcome = "failure"
+)
+
+// StepCondition defines when a step is executed. A step is executed only
+// if all of the conditions set are met. ClusterProfiles and Env are
+// evaluated when the test is resolved, so steps that do not meet them are not
+// part of the resolved test; Outcome is evaluated while the test runs.
+type StepCondition struct {
+	// ClusterProfiles limits the step to tests using one of these cluster profiles.
+	ClusterProfiles []ClusterProfile `json:"cluster_profiles,omitempty"`
+	// Env limits the step to tests where the parameters have one of the listed values.
+	Env []EnvCondition `json:"env,omitempty"`
+	// Outcome limits the step to the outcome of the steps before it.
+	Outcome StepOutcome `json:"outcome,omitempty"`
+}
+
+// EnvCondition matches the value of a parameter of a test.
+type EnvCondition struct {
+	// Name is the name of the parameter.
+	Name string `json:"name"`
+	// Values lists the values that meet the condition. An unset parameter
+	// has the empty value.
+	Values []string `json:"values"`
+}
+
+// RuntimeOnly determines if the condition only has parts that are evaluated
+// while the test runs.
+func (c StepCondition) RuntimeOnly() bool {
+	return len(c.ClusterProfiles) == 0 && len(c.Env) == 0
+}
+
+// MatchesProfile determines if the cluster profile meets the condition.
+func (c StepCondition) MatchesProfile(profile ClusterProfile) bool {
+	if len(c.ClusterProfiles) == 0 {
+		return true
+	}
+	for _, allowed := range c.ClusterProfiles {
+		if allowed == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesOutcome determines if the outcome of the steps executed before
+// the step meets the condition.
+func (c StepCondition) MatchesOutcome(failed bool) bool {
+	switch c.Outcome {
+	case StepOutcomeSuccess:
+		return !failed
+	case StepOutcomeFailure:
+		return failed
+	default:
+		return true
+	}
 }
 
 // StepParameter is a variable set by the test, with an optional default.
@@ -805,6 +950,89 @@ type StepParameter struct {
 	Default *string `json:"default,omitempty"`
 	// Documentation is a textual description of the parameter.
 	Documentation string `json:"documentation,omitempty"`
+	// Type restricts the values the parameter accepts, optional. Values of
+	// untyped parameters are not validated.
+	Type StepParameterType `json:"type,omitempty"`
+	// Values lists the values accepted by a parameter of the `enum` type.
+	Values []string `json:"values,omitempty"`
+	// Pattern is a regular expression that values of a parameter of the
+	// `pattern` type must match in full.
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// StepParameterType determines which values a step parameter accepts.
+type StepParameterType string
+
+const (
+	StepParameterTypeEnum     StepParameterType = "enum"
+	StepParameterTypeBool     StepParameterType = "bool"
+	StepParameterTypeInt      StepParameterType = "int"
+	StepParameterTypePattern  StepParameterType = "pattern"
+	StepParameterTypeDuration StepParameterType = "duration"
+)
+
+// ValidStepParameterTypes are all the known step parameter types.
+var ValidStepParameterTypes = []StepParameterType{
+	StepParameterTypeEnum,
+	StepParameterTypeBool,
+	StepParameterTypeInt,
+	StepParameterTypePattern,
+	StepParameterTypeDuration,
+}
+
+// ValidateValue determines if the parameter accepts the value. Empty values
+// are always accepted, as they are commonly used to leave parameters unset.
+func (p StepParameter) ValidateValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	switch p.Type {
+	case StepParameterTypeEnum:
+		for _, allowed := range p.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q is not one of %s", value, strings.Join(p.Values, ", "))
+	case StepParameterTypeBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("value %q is not a boolean, expected true or false", value)
+		}
+	case StepParameterTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("value %q is not an integer", value)
+		}
+	case StepParameterTypePattern:
+		pattern, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p.Pattern, err)
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("value %q does not match %q", value, p.Pattern)
+		}
+	case StepParameterTypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("value %q is not a duration", value)
+		}
+	}
+	return nil
+}
+
+// AllowedValues describes the values the parameter accepts, if restricted.
+func (p StepParameter) AllowedValues() string {
+	switch p.Type {
+	case StepParameterTypeEnum:
+		return "one of " + strings.Join(p.Values, ", ")
+	case StepParameterTypeBool:
+		return "true or false"
+	case StepParameterTypeInt:
+		return "an integer"
+	case StepParameterTypePattern:
+		return "values matching " + p.Pattern
+	case StepParameterTypeDuration:
+		return "a duration, like 1h30m"
+	}
+	return ""
 }
 
 // CredentialReference defines a secret to mount into a step and where to mount it.
@@ -868,6 +1096,17 @@ type TestStep struct {
 	Reference *string `json:"ref,omitempty"`
 	// Chain is the name of a step chain reference.
 	Chain *string `json:"chain,omitempty"`
+	// When defines the conditions under which the step, or all steps in the
+	// chain, are executed, in addition to their own conditions.
+	When *StepCondition `json:"when,omitempty"`
+	// ParallelGroup is the name of the group of steps this step, or all steps
+	// in the chain, are executed concurrently with. Consecutive steps with the
+	// same group are executed at the same time and the group fails if any of
+	// them fails. Chains cannot contain a parallel group if they are part of
+	// one themselves. The changes the steps make to the files in SHARED_DIR
+	// are merged; if more than one step writes the same file, the content
+	// written by the step finishing last is kept.
+	ParallelGroup string `json:"parallel_group,omitempty"`
 }
 
 // MultiStageTestConfiguration is a flexible configuration mode that allows tighter control over
@@ -907,6 +1146,11 @@ type MultiStageTestConfiguration struct {
 	// DependencyOverrides allows a step to override a dependency with a fully-qualified pullspec. This will probably only ever
 	// be used with rehearsals. Otherwise, the overrides should be passed in as parameters to ci-operator.
 	DependencyOverrides DependencyOverrides `json:"dependency_overrides,omitempty"`
+	// RegistryVersion pins the test to the content hash of a version of the
+	// step registry, so that the test resolves to the same steps as it did
+	// when that version was current. The latest version is used when unset.
+	// Resolving with a local registry fails unless it is at this version.
+	RegistryVersion string `json:"registry_version,omitempty"`
 }
 type DependencyOverrides map[string]string
 
@@ -944,6 +1188,11 @@ type MultiStageTestConfigurationLiteral struct {
 	// DependencyOverrides allows a step to override a dependency with a fully-qualified pullspec. This will probably only ever
 	// be used with rehearsals. Otherwise, the overrides should be passed in as parameters to ci-operator.
 	DependencyOverrides DependencyOverrides `json:"dependency_overrides,omitempty"`
+	// RegistryVersion is the content hash of the version of the step registry
+	// the test was resolved with, if known.
+	RegistryVersion string `json:"registry_version,omitempty"`
+	// Deprecations lists the deprecated registry components the test uses.
+	Deprecations []DeprecatedComponent `json:"deprecations,omitempty"`
 }
 
 // TestEnvironment has the values of parameters for multi-stage tests.
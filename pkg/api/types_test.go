@@ -343,3 +343,31 @@ func TestInputImageTagStepConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestStepParameterValidateValue(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		param     StepParameter
+		value     string
+		expectErr bool
+	}{
+		{name: "untyped accepts anything", param: StepParameter{}, value: "anything"},
+		{name: "empty value is always accepted", param: StepParameter{Type: StepParameterTypeInt}, value: ""},
+		{name: "enum value", param: StepParameter{Type: StepParameterTypeEnum, Values: []string{"ovn", "sdn"}}, value: "ovn"},
+		{name: "enum invalid value", param: StepParameter{Type: StepParameterTypeEnum, Values: []string{"ovn", "sdn"}}, value: "OVN", expectErr: true},
+		{name: "bool", param: StepParameter{Type: StepParameterTypeBool}, value: "false"},
+		{name: "bool typo", param: StepParameter{Type: StepParameterTypeBool}, value: "ture", expectErr: true},
+		{name: "int", param: StepParameter{Type: StepParameterTypeInt}, value: "-3"},
+		{name: "int invalid", param: StepParameter{Type: StepParameterTypeInt}, value: "3.5", expectErr: true},
+		{name: "pattern", param: StepParameter{Type: StepParameterTypePattern, Pattern: "4\\.[0-9]+"}, value: "4.10"},
+		{name: "pattern matches in full only", param: StepParameter{Type: StepParameterTypePattern, Pattern: "4\\.[0-9]+"}, value: "v4.10", expectErr: true},
+		{name: "duration", param: StepParameter{Type: StepParameterTypeDuration}, value: "1h30m"},
+		{name: "duration without unit", param: StepParameter{Type: StepParameterTypeDuration}, value: "90", expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.param.ValidateValue(tc.value); (err != nil) != tc.expectErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
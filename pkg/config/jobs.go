@@ -13,6 +13,7 @@ var (
 	ChangedClusterProfile  SourceType = "changedClusterProfile"
 	ChangedTemplate        SourceType = "changedTemplate"
 	ChangedRegistryContent SourceType = "changedRegistryContent"
+	RequestedJob           SourceType = "requestedJob"
 	Unknown                SourceType = "unknownSource"
 )
 
@@ -37,6 +38,8 @@ func GetSourceType(labels map[string]string) SourceType {
 		return ChangedTemplate
 	case "changedRegistryContent":
 		return ChangedRegistryContent
+	case "requestedJob":
+		return RequestedJob
 	default:
 		return Unknown
 	}
@@ -1,17 +1,23 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/ghodss/yaml"
 	"github.com/sirupsen/logrus"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
 	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/util/gzip"
 	"github.com/openshift/ci-tools/pkg/validation"
@@ -30,6 +36,82 @@ type Prowgen struct {
 	// are private.
 	// This field has no effect if private is not set.
 	Expose bool `json:"expose,omitempty"`
+	// Tests holds customizations of the jobs generated for tests, keyed by
+	// the `as` name of the test. They apply to the test in all branches and
+	// variants. The fields they set take precedence over manual edits of the
+	// generated jobs; manual edits of the other fields are kept.
+	Tests map[string]JobOverrides `json:"tests,omitempty"`
+}
+
+// JobOverrides customizes the job generated for a test. Fields that do not
+// apply to the type of the job are ignored.
+type JobOverrides struct {
+	// AlwaysRun can be set to false to only trigger the presubmit when
+	// requested or when the changes match RunIfChanged or SkipIfOnlyChanged.
+	AlwaysRun *bool `json:"always_run,omitempty"`
+	// RunIfChanged triggers the presubmit only when a changed file matches
+	// this regular expression.
+	RunIfChanged string `json:"run_if_changed,omitempty"`
+	// SkipIfOnlyChanged skips the presubmit when all changed files match
+	// this regular expression.
+	SkipIfOnlyChanged string `json:"skip_if_only_changed,omitempty"`
+	// Optional marks the presubmit as not required for merging.
+	Optional *bool `json:"optional,omitempty"`
+	// Labels are added to the labels of the job.
+	Labels map[string]string `json:"labels,omitempty"`
+	// MaxConcurrency limits the number of concurrent runs of the job.
+	MaxConcurrency *int `json:"max_concurrency,omitempty"`
+	// ReporterConfig configures how the results of the job are reported.
+	ReporterConfig *prowv1.ReporterConfig `json:"reporter_config,omitempty"`
+}
+
+// reservedLabelPrefixes are label prefixes used by the tooling, which cannot
+// be set through overrides.
+var reservedLabelPrefixes = []string{"ci-operator.openshift.io/", "pj-rehearse.openshift.io/", "prowgen.openshift.io/"}
+
+// Validate checks the overrides for the tests in the configuration.
+func (p *Prowgen) Validate() error {
+	var tests []string
+	for test := range p.Tests {
+		tests = append(tests, test)
+	}
+	sort.Strings(tests)
+	var errs []error
+	for _, test := range tests {
+		for _, err := range p.Tests[test].validate() {
+			errs = append(errs, fmt.Errorf("tests.%s: %w", test, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (o JobOverrides) validate() []error {
+	var errs []error
+	if o.RunIfChanged != "" && o.SkipIfOnlyChanged != "" {
+		errs = append(errs, errors.New("run_if_changed and skip_if_only_changed cannot be set together"))
+	}
+	if (o.RunIfChanged != "" || o.SkipIfOnlyChanged != "") && o.AlwaysRun != nil && *o.AlwaysRun {
+		errs = append(errs, errors.New("always_run cannot be set when run_if_changed or skip_if_only_changed is set"))
+	}
+	for _, item := range []struct{ field, value string }{
+		{field: "run_if_changed", value: o.RunIfChanged},
+		{field: "skip_if_only_changed", value: o.SkipIfOnlyChanged},
+	} {
+		if _, err := regexp.Compile(item.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s is not a valid regular expression: %w", item.field, err))
+		}
+	}
+	if o.MaxConcurrency != nil && *o.MaxConcurrency < 0 {
+		errs = append(errs, errors.New("max_concurrency cannot be negative"))
+	}
+	for key := range o.Labels {
+		for _, prefix := range reservedLabelPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				errs = append(errs, fmt.Errorf("label %s cannot be set, labels with the prefix %s are reserved", key, prefix))
+			}
+		}
+	}
+	return errs
 }
 
 func readCiOperatorConfig(configFilePath string, info Info) (*cioperatorapi.ReleaseBuildConfiguration, error) {
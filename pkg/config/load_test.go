@@ -92,3 +92,51 @@ func TestExtractRepoElementsFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestProwgenValidate(t *testing.T) {
+	no, yes := false, true
+	negative := -1
+	testCases := []struct {
+		name     string
+		config   Prowgen
+		expected string
+	}{
+		{
+			name: "valid overrides",
+			config: Prowgen{Tests: map[string]JobOverrides{
+				"unit": {AlwaysRun: &no, RunIfChanged: "^pkg/", Labels: map[string]string{"team": "installer"}},
+				"e2e":  {SkipIfOnlyChanged: `\.md$`, Optional: &yes},
+			}},
+		},
+		{
+			name: "override markers cannot be set",
+			config: Prowgen{Tests: map[string]JobOverrides{
+				"unit": {Labels: map[string]string{"prowgen.openshift.io/override-optional": "true"}},
+			}},
+			expected: "tests.unit: label prowgen.openshift.io/override-optional cannot be set, labels with the prefix prowgen.openshift.io/ are reserved",
+		},
+		{
+			name: "invalid overrides",
+			config: Prowgen{Tests: map[string]JobOverrides{
+				"unit": {AlwaysRun: &yes, RunIfChanged: "^pkg/", SkipIfOnlyChanged: "("},
+				"e2e":  {MaxConcurrency: &negative, Labels: map[string]string{"ci-operator.openshift.io/variant": "foo"}},
+			}},
+			expected: "[tests.e2e: max_concurrency cannot be negative, " +
+				"tests.e2e: label ci-operator.openshift.io/variant cannot be set, labels with the prefix ci-operator.openshift.io/ are reserved, " +
+				"tests.unit: run_if_changed and skip_if_only_changed cannot be set together, " +
+				"tests.unit: always_run cannot be set when run_if_changed or skip_if_only_changed is set, " +
+				"tests.unit: skip_if_only_changed is not a valid regular expression: error parsing regexp: missing closing ): `(`]",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual string
+			if err := tc.config.Validate(); err != nil {
+				actual = err.Error()
+			}
+			if actual != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -92,7 +92,7 @@ func FromConfig(
 		return nil, nil, fmt.Errorf("could not get core client for cluster config: %w", err)
 	}
 
-	podClient := steps.NewPodClient(client, clusterConfig, coreGetter.RESTClient())
+	podClient := steps.NewPodClient(client, clusterConfig, coreGetter.RESTClient(), censor)
 
 	var hiveClient ctrlruntimeclient.WithWatch
 	if hiveKubeconfig != nil {
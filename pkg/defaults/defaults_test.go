@@ -906,7 +906,7 @@ func TestFromConfig(t *testing.T) {
 	}
 	buildClient := steps.NewBuildClient(client, nil)
 	var templateClient steps.TemplateClient
-	podClient := steps.NewPodClient(client, nil, nil)
+	podClient := steps.NewPodClient(client, nil, nil, nil)
 
 	clusterPool := hivev1.ClusterPool{
 		ObjectMeta: meta.ObjectMeta{
@@ -0,0 +1,135 @@
+package deprecateregistry
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/util/gzip"
+)
+
+type allowedJob struct {
+	// unexported fields are never serialized, so they are `false` on read.
+	// We touch `current` when we process configurations so that we can
+	// recognize jobs that no longer use the component later and remove them,
+	// and `newlyAdded` when the job was not in the allowlist before.
+	current    bool
+	newlyAdded bool
+
+	api.Metadata `json:",inline"`
+	Test         string `json:"test"`
+}
+
+type allowedJobs map[string]allowedJob
+
+type deprecatedComponent struct {
+	Type string      `json:"type"`
+	Name string      `json:"name"`
+	Jobs allowedJobs `json:"jobs,omitempty"`
+}
+
+func componentKey(t, name string) string {
+	return fmt.Sprintf("%s/%s", t, name)
+}
+
+func (d *deprecatedComponent) insert(usage registry.TestUsage) {
+	if d.Jobs == nil {
+		d.Jobs = allowedJobs{}
+	}
+	_, existing := d.Jobs[usage.JobName]
+	d.Jobs[usage.JobName] = allowedJob{
+		current:    true,
+		newlyAdded: !existing,
+		Metadata:   usage.Metadata,
+		Test:       usage.Test,
+	}
+}
+
+func (d *deprecatedComponent) prune() {
+	for name, job := range d.Jobs {
+		if !job.current {
+			delete(d.Jobs, name)
+		}
+	}
+}
+
+func (d *deprecatedComponent) newJobs() []string {
+	var jobs []string
+	for name, job := range d.Jobs {
+		if job.newlyAdded {
+			jobs = append(jobs, name)
+		}
+	}
+	return jobs
+}
+
+type allowlist struct {
+	Components map[string]*deprecatedComponent `json:"components"`
+}
+
+func (a *allowlist) insert(t, name string, usage registry.TestUsage) {
+	key := componentKey(t, name)
+	if a.Components == nil {
+		a.Components = map[string]*deprecatedComponent{}
+	}
+	if _, ok := a.Components[key]; !ok {
+		a.Components[key] = &deprecatedComponent{Type: t, Name: name}
+	}
+	a.Components[key].insert(usage)
+}
+
+// prune removes all jobs that no longer use a component, and all components
+// that are no longer deprecated or used.
+func (a *allowlist) prune(deprecations registry.Deprecations) {
+	for key, component := range a.Components {
+		component.prune()
+		t, err := registry.ParseType(component.Type)
+		if err != nil {
+			logrus.WithError(err).Warnf("Removing %s with unknown type from the allowlist", key)
+			delete(a.Components, key)
+			continue
+		}
+		if _, deprecated := deprecations.For(t, component.Name); !deprecated || len(component.Jobs) == 0 {
+			delete(a.Components, key)
+		}
+	}
+}
+
+func loadAllowlist(allowlistPath string) (*allowlist, error) {
+	var allowlist allowlist
+
+	raw, err := gzip.ReadFileMaybeGZIP(allowlistPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		return &allowlist, yaml.Unmarshal(raw, &allowlist)
+	}
+
+	logrus.Warn("registry deprecation allowlist does not exist, will populate a new one")
+	return &allowlist, nil
+}
+
+func (a allowlist) save(path string) error {
+	raw, err := yaml.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, raw, 0644)
+}
+
+func formatJobs(jobs []string) string {
+	var lines []string
+	for _, job := range jobs {
+		lines = append(lines, fmt.Sprintf("- %s", job))
+	}
+	return strings.Join(lines, "\n")
+}
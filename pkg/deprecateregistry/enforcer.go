@@ -0,0 +1,182 @@
+package deprecateregistry
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// Enforcer manages all necessary data to decide if the jobs in
+// openshift/release are allowed to use the deprecated registry components
+// they use (and therefore, if a PR to openshift/release does not add new
+// users of deprecated components)
+type Enforcer struct {
+	deprecations registry.Deprecations
+	allowlist    *allowlist
+	// allowNew adds new users of deprecated components to the allowlist
+	// instead of failing validation
+	allowNew bool
+	now      func() time.Time
+}
+
+// NewEnforcer initializes a new enforcer instance. The enforcer will be
+// initialized with an allowlist from the given location. If the allowlist
+// does not exist, the enforcer will have an empty allowlist.
+func NewEnforcer(allowlistPath string, allowNew bool) (*Enforcer, error) {
+	allowlist, err := loadAllowlist(allowlistPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry deprecation allowlist from %q: %w", allowlistPath, err)
+	}
+
+	return &Enforcer{
+		allowlist: allowlist,
+		allowNew:  allowNew,
+		now:       time.Now,
+	}, nil
+}
+
+// ProcessConfigs finds all tests that execute a deprecated registry component
+// and makes sure they are present in the allowlist.
+func (e *Enforcer) ProcessConfigs(graph registry.NodeByName, workflows registry.WorkflowByName, deprecations registry.Deprecations, configs []api.ReleaseBuildConfiguration) {
+	e.deprecations = deprecations
+	for _, component := range deprecations.All() {
+		t, err := registry.ParseType(component.Type)
+		if err != nil {
+			continue
+		}
+		node, ok := graph.Lookup(t, component.Name)
+		if !ok {
+			logrus.Warnf("Deprecated %s %s does not exist in the registry", component.Type, component.Name)
+			continue
+		}
+		for _, test := range registry.UsageOf(node, workflows, configs).Tests {
+			e.allowlist.insert(component.Type, component.Name, test)
+		}
+	}
+}
+
+// SaveAllowlist dumps the allowlist to the given location
+func (e *Enforcer) SaveAllowlist(path string) error {
+	return e.allowlist.save(path)
+}
+
+// Prune removes all jobs that no longer use a deprecated component from
+// the allowlist
+func (e *Enforcer) Prune() {
+	e.allowlist.prune(e.deprecations)
+}
+
+// Stats lists the number of jobs and repositories using each deprecated
+// component, ordered by the removal date.
+func (e *Enforcer) Stats() (header, footer []string, lines [][]string) {
+	header = []string{"Component", "Type", "Removal Date", "Jobs", "Repositories"}
+	type statsLine struct {
+		api.DeprecatedComponent
+		jobs, repos int
+	}
+	var data []statsLine
+	var sumJobs int
+	for _, component := range e.deprecations.All() {
+		line := statsLine{DeprecatedComponent: component}
+		if allowed, ok := e.allowlist.Components[componentKey(component.Type, component.Name)]; ok {
+			repos := map[string]bool{}
+			for _, job := range allowed.Jobs {
+				repos[fmt.Sprintf("%s/%s", job.Org, job.Repo)] = true
+			}
+			line.jobs, line.repos = len(allowed.Jobs), len(repos)
+		}
+		sumJobs += line.jobs
+		data = append(data, line)
+	}
+
+	sort.SliceStable(data, func(i, j int) bool {
+		// components without a removal date go last
+		switch {
+		case data[i].RemovalDate == data[j].RemovalDate:
+			return data[i].jobs > data[j].jobs
+		case data[i].RemovalDate == "":
+			return false
+		case data[j].RemovalDate == "":
+			return true
+		}
+		return data[i].RemovalDate < data[j].RemovalDate
+	})
+
+	for _, item := range data {
+		lines = append(lines, []string{item.Name, item.Type, item.RemovalDate, strconv.Itoa(item.jobs), strconv.Itoa(item.repos)})
+	}
+
+	footer = []string{fmt.Sprintf("%d components", len(data)), "", "Total", strconv.Itoa(sumJobs), ""}
+	return header, footer, lines
+}
+
+type enforcingFunc func() []error
+
+func (e *Enforcer) noNewUsers() []error {
+	if e.allowNew {
+		return nil
+	}
+	var errs []error
+	for _, component := range e.deprecations.All() {
+		allowed, ok := e.allowlist.Components[componentKey(component.Type, component.Name)]
+		if !ok {
+			continue
+		}
+		jobs := allowed.newJobs()
+		if len(jobs) == 0 {
+			continue
+		}
+		sort.Strings(jobs)
+		errs = append(errs, fmt.Errorf("%s. The following jobs were newly added as its users, please change them to stop using it:\n%s", component.String(), formatJobs(jobs)))
+	}
+	return errs
+}
+
+func (e *Enforcer) noUsersAfterRemoval() []error {
+	var errs []error
+	today := e.now().Format(api.DeprecationDateFormat)
+	for _, component := range e.deprecations.All() {
+		// the format sorts lexically in chronological order
+		if component.RemovalDate == "" || component.RemovalDate >= today {
+			continue
+		}
+		allowed, ok := e.allowlist.Components[componentKey(component.Type, component.Name)]
+		if !ok {
+			continue
+		}
+		var jobs []string
+		for name, job := range allowed.Jobs {
+			if job.current {
+				jobs = append(jobs, name)
+			}
+		}
+		if len(jobs) == 0 {
+			continue
+		}
+		sort.Strings(jobs)
+		errs = append(errs, fmt.Errorf("%s. The removal date has passed but the following jobs still use it:\n%s", component.String(), formatJobs(jobs)))
+	}
+	return errs
+}
+
+// Validate checks that no new jobs use deprecated components and that no
+// jobs use components past their removal date.
+func (e *Enforcer) Validate() []string {
+	checks := []enforcingFunc{
+		e.noNewUsers,
+		e.noUsersAfterRemoval,
+	}
+	var violations []string
+	for _, check := range checks {
+		for _, err := range check() {
+			violations = append(violations, err.Error())
+		}
+	}
+	return violations
+}
@@ -0,0 +1,121 @@
+package deprecateregistry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestEnforcer(t *testing.T) {
+	deprecatedRef, ref, chain, workflow := "deprecated-ref", "ref", "chain", "workflow"
+	references := registry.ReferenceByName{
+		deprecatedRef: {As: deprecatedRef},
+		ref:           {As: ref},
+	}
+	chains := registry.ChainByName{
+		chain: {As: chain, Steps: []api.TestStep{{Reference: &deprecatedRef}}},
+	}
+	workflows := registry.WorkflowByName{
+		workflow: {Test: []api.TestStep{{Chain: &chain}}},
+	}
+	graph, err := registry.NewGraph(references, chains, workflows)
+	if err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	configs := []api.ReleaseBuildConfiguration{{
+		Metadata: metadata,
+		Tests: []api.TestStepConfiguration{
+			{As: "old", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: &workflow}},
+			{As: "new", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &deprecatedRef}}}},
+			{As: "unrelated", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &ref}}}},
+		},
+	}}
+	existing := func() *allowlist {
+		return &allowlist{Components: map[string]*deprecatedComponent{
+			"reference/deprecated-ref": {
+				Type: "reference",
+				Name: deprecatedRef,
+				Jobs: allowedJobs{
+					"pull-ci-org-repo-master-old":  {Metadata: metadata, Test: "old"},
+					"pull-ci-org-repo-master-gone": {Metadata: metadata, Test: "gone"},
+				},
+			},
+		}}
+	}
+	now := func() time.Time { return time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		name               string
+		deprecation        api.Deprecation
+		allowNew           bool
+		prune              bool
+		expectedViolations []string
+		expectedJobs       []string
+	}{
+		{
+			name:        "new user is a violation",
+			deprecation: api.Deprecation{Message: "do not use", Replacement: ref},
+			expectedViolations: []string{`registry reference deprecated-ref is deprecated: do not use; use ref instead. The following jobs were newly added as its users, please change them to stop using it:
+- pull-ci-org-repo-master-new`},
+			expectedJobs: []string{"pull-ci-org-repo-master-gone", "pull-ci-org-repo-master-new", "pull-ci-org-repo-master-old"},
+		},
+		{
+			name:         "new user is allowed when requested",
+			deprecation:  api.Deprecation{Message: "do not use"},
+			allowNew:     true,
+			prune:        true,
+			expectedJobs: []string{"pull-ci-org-repo-master-new", "pull-ci-org-repo-master-old"},
+		},
+		{
+			name:        "users after the removal date are a violation",
+			deprecation: api.Deprecation{Message: "do not use", RemovalDate: "2021-05-31"},
+			allowNew:    true,
+			expectedViolations: []string{`registry reference deprecated-ref is deprecated: do not use; it will be removed after 2021-05-31. The removal date has passed but the following jobs still use it:
+- pull-ci-org-repo-master-new
+- pull-ci-org-repo-master-old`},
+			expectedJobs: []string{"pull-ci-org-repo-master-gone", "pull-ci-org-repo-master-new", "pull-ci-org-repo-master-old"},
+		},
+		{
+			name:         "users before the removal date are allowed",
+			deprecation:  api.Deprecation{Message: "do not use", RemovalDate: "2021-06-01"},
+			allowNew:     true,
+			expectedJobs: []string{"pull-ci-org-repo-master-gone", "pull-ci-org-repo-master-new", "pull-ci-org-repo-master-old"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enforcer := Enforcer{allowlist: existing(), allowNew: tc.allowNew, now: now}
+			deprecations := registry.Deprecations{References: map[string]api.Deprecation{deprecatedRef: tc.deprecation}}
+			enforcer.ProcessConfigs(graph, workflows, deprecations, configs)
+			if tc.prune {
+				enforcer.Prune()
+			}
+			if diff := cmp.Diff(tc.expectedViolations, enforcer.Validate()); diff != "" {
+				t.Errorf("unexpected violations: %s", diff)
+			}
+			var jobs []string
+			for name := range enforcer.allowlist.Components["reference/deprecated-ref"].Jobs {
+				jobs = append(jobs, name)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, jobs, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected allowlisted jobs: %s", diff)
+			}
+		})
+	}
+}
+
+func TestPruneRemovesComponentsNoLongerDeprecated(t *testing.T) {
+	allowlist := &allowlist{Components: map[string]*deprecatedComponent{
+		"chain/chain": {Type: "chain", Name: "chain", Jobs: allowedJobs{"job": {current: true}}},
+	}}
+	allowlist.prune(registry.Deprecations{})
+	if len(allowlist.Components) != 0 {
+		t.Errorf("expected the component to be pruned, got %v", allowlist.Components)
+	}
+}
@@ -0,0 +1,216 @@
+package jobruntimeanalyzer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kataras/tablewriter"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// StepTiming describes when a step in the graph ran, relative to when it
+// could have run.
+type StepTiming struct {
+	Name         string    `json:"name"`
+	Dependencies []string  `json:"dependencies,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	// RunnableAt is when the last dependency of the step finished, or when
+	// the job started for steps without dependencies.
+	RunnableAt time.Time     `json:"runnable_at"`
+	Duration   time.Duration `json:"duration"`
+	// Idle is the time between the step becoming runnable and starting.
+	Idle     time.Duration `json:"idle"`
+	Critical bool          `json:"critical"`
+}
+
+// GraphAnalysis summarizes the execution of a ci-operator step graph.
+type GraphAnalysis struct {
+	JobURL    string        `json:"job_url"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	// CriticalPath lists the chain of steps, ordered by execution, that
+	// determined the duration of the job.
+	CriticalPath    []string     `json:"critical_path"`
+	PeakParallelism int          `json:"peak_parallelism"`
+	Steps           []StepTiming `json:"steps"`
+}
+
+// analyzeGraph rebuilds the dependency graph of the steps that ran and finds
+// the critical path through it. Steps that never started or finished are not
+// taken into account.
+func analyzeGraph(jobURL string, graph api.CIOperatorStepGraph) GraphAnalysis {
+	analysis := GraphAnalysis{JobURL: jobURL}
+	byName := map[string]*StepTiming{}
+	var start, end time.Time
+	for _, step := range graph {
+		if step.StartedAt == nil || step.FinishedAt == nil {
+			continue
+		}
+		timing := &StepTiming{
+			Name:         step.StepName,
+			Dependencies: step.Dependencies,
+			StartedAt:    *step.StartedAt,
+			FinishedAt:   *step.FinishedAt,
+			Duration:     step.FinishedAt.Sub(*step.StartedAt),
+		}
+		byName[step.StepName] = timing
+		if start.IsZero() || timing.StartedAt.Before(start) {
+			start = timing.StartedAt
+		}
+		if timing.FinishedAt.After(end) {
+			end = timing.FinishedAt
+		}
+	}
+	if len(byName) == 0 {
+		return analysis
+	}
+	analysis.StartedAt = start
+	analysis.Duration = end.Sub(start)
+
+	// latestDependency returns the dependency that finished last, which is
+	// the one that made the step runnable
+	latestDependency := func(timing *StepTiming) *StepTiming {
+		var latest *StepTiming
+		for _, name := range timing.Dependencies {
+			if dependency, ok := byName[name]; ok && (latest == nil || dependency.FinishedAt.After(latest.FinishedAt)) {
+				latest = dependency
+			}
+		}
+		return latest
+	}
+
+	var last *StepTiming
+	for _, timing := range byName {
+		timing.RunnableAt = start
+		if dependency := latestDependency(timing); dependency != nil {
+			timing.RunnableAt = dependency.FinishedAt
+		}
+		if timing.StartedAt.After(timing.RunnableAt) {
+			timing.Idle = timing.StartedAt.Sub(timing.RunnableAt)
+		}
+		if last == nil || timing.FinishedAt.After(last.FinishedAt) {
+			last = timing
+		}
+	}
+	for step := last; step != nil; step = latestDependency(step) {
+		step.Critical = true
+		analysis.CriticalPath = append([]string{step.Name}, analysis.CriticalPath...)
+	}
+
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	var events []event
+	for _, timing := range byName {
+		analysis.Steps = append(analysis.Steps, *timing)
+		events = append(events, event{at: timing.StartedAt, delta: 1}, event{at: timing.FinishedAt, delta: -1})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			// a step finishing at the same time another starts does
+			// not overlap with it
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+	var running int
+	for _, e := range events {
+		running += e.delta
+		if running > analysis.PeakParallelism {
+			analysis.PeakParallelism = running
+		}
+	}
+	sort.Slice(analysis.Steps, func(i, j int) bool {
+		if analysis.Steps[i].StartedAt.Equal(analysis.Steps[j].StartedAt) {
+			return analysis.Steps[i].Name < analysis.Steps[j].Name
+		}
+		return analysis.Steps[i].StartedAt.Before(analysis.Steps[j].StartedAt)
+	})
+	return analysis
+}
+
+func printGraphAnalysis(out io.Writer, analysis GraphAnalysis) {
+	_, _ = fmt.Fprintf(out, "Step graph for %s\n", analysis.JobURL)
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"step", "runnable after", "idle", "runtime", "critical"})
+	for _, step := range analysis.Steps {
+		var critical string
+		if step.Critical {
+			critical = "*"
+		}
+		table.Append([]string{step.Name, step.RunnableAt.Sub(analysis.StartedAt).String(), step.Idle.String(), step.Duration.String(), critical})
+	}
+	table.SetFooter([]string{"total", "", "", analysis.Duration.String(), "peak parallelism: " + strconv.Itoa(analysis.PeakParallelism)})
+	table.Render()
+}
+
+// StepComparison holds the runtime of a step in each of the compared jobs.
+type StepComparison struct {
+	Name      string           `json:"name"`
+	Durations []*time.Duration `json:"durations"`
+	// Regression is the largest increase in runtime over the first job.
+	Regression time.Duration `json:"regression"`
+}
+
+// compareGraphs compares step runtimes of jobs with those of the first one,
+// ordered by the largest regression first.
+func compareGraphs(analyses []GraphAnalysis) []StepComparison {
+	var names []string
+	byName := map[string]*StepComparison{}
+	for i, analysis := range analyses {
+		for _, step := range analysis.Steps {
+			comparison, ok := byName[step.Name]
+			if !ok {
+				comparison = &StepComparison{Name: step.Name, Durations: make([]*time.Duration, len(analyses))}
+				byName[step.Name] = comparison
+				names = append(names, step.Name)
+			}
+			duration := step.Duration
+			comparison.Durations[i] = &duration
+		}
+	}
+	var comparisons []StepComparison
+	for _, name := range names {
+		comparison := byName[name]
+		if baseline := comparison.Durations[0]; baseline != nil {
+			for _, duration := range comparison.Durations[1:] {
+				if duration != nil && *duration-*baseline > comparison.Regression {
+					comparison.Regression = *duration - *baseline
+				}
+			}
+		}
+		comparisons = append(comparisons, *comparison)
+	}
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return comparisons[i].Regression > comparisons[j].Regression
+	})
+	return comparisons
+}
+
+func printComparison(out io.Writer, analyses []GraphAnalysis, comparisons []StepComparison) {
+	_, _ = fmt.Fprintf(out, "Step runtimes compared to %s\n", analyses[0].JobURL)
+	header := []string{"step"}
+	for i := range analyses {
+		header = append(header, fmt.Sprintf("job %d", i+1))
+	}
+	table := tablewriter.NewWriter(out)
+	table.SetHeader(append(header, "regression"))
+	for _, comparison := range comparisons {
+		row := []string{comparison.Name}
+		for _, duration := range comparison.Durations {
+			if duration == nil {
+				row = append(row, "-")
+			} else {
+				row = append(row, duration.String())
+			}
+		}
+		table.Append(append(row, comparison.Regression.String()))
+	}
+	table.Render()
+}
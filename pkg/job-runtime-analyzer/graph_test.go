@@ -0,0 +1,66 @@
+package jobruntimeanalyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestAnalyzeGraph(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	step := func(name string, from, to time.Duration, dependencies ...string) api.CIOperatorStepDetails {
+		startedAt, finishedAt := start.Add(from), start.Add(to)
+		return api.CIOperatorStepDetails{CIOperatorStepDetailInfo: api.CIOperatorStepDetailInfo{
+			StepName:     name,
+			Dependencies: dependencies,
+			StartedAt:    &startedAt,
+			FinishedAt:   &finishedAt,
+		}}
+	}
+	graph := api.CIOperatorStepGraph{
+		step("src", 0, 10*time.Minute),
+		step("bin", 12*time.Minute, 20*time.Minute, "src"),
+		step("images", 10*time.Minute, 15*time.Minute, "src"),
+		step("e2e", 25*time.Minute, 60*time.Minute, "bin", "images"),
+		step("unit", 20*time.Minute, 30*time.Minute, "bin"),
+		{CIOperatorStepDetailInfo: api.CIOperatorStepDetailInfo{StepName: "never-ran", Dependencies: []string{"e2e"}}},
+	}
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	expected := GraphAnalysis{
+		JobURL:          "url",
+		StartedAt:       start,
+		Duration:        time.Hour,
+		CriticalPath:    []string{"src", "bin", "e2e"},
+		PeakParallelism: 2,
+		Steps: []StepTiming{
+			{Name: "src", StartedAt: at(0), FinishedAt: at(10 * time.Minute), RunnableAt: at(0), Duration: 10 * time.Minute, Critical: true},
+			{Name: "images", Dependencies: []string{"src"}, StartedAt: at(10 * time.Minute), FinishedAt: at(15 * time.Minute), RunnableAt: at(10 * time.Minute), Duration: 5 * time.Minute},
+			{Name: "bin", Dependencies: []string{"src"}, StartedAt: at(12 * time.Minute), FinishedAt: at(20 * time.Minute), RunnableAt: at(10 * time.Minute), Duration: 8 * time.Minute, Idle: 2 * time.Minute, Critical: true},
+			{Name: "unit", Dependencies: []string{"bin"}, StartedAt: at(20 * time.Minute), FinishedAt: at(30 * time.Minute), RunnableAt: at(20 * time.Minute), Duration: 10 * time.Minute},
+			{Name: "e2e", Dependencies: []string{"bin", "images"}, StartedAt: at(25 * time.Minute), FinishedAt: at(60 * time.Minute), RunnableAt: at(20 * time.Minute), Duration: 35 * time.Minute, Idle: 5 * time.Minute, Critical: true},
+		},
+	}
+	if diff := cmp.Diff(expected, analyzeGraph("url", graph)); diff != "" {
+		t.Errorf("unexpected analysis: %s", diff)
+	}
+}
+
+func TestCompareGraphs(t *testing.T) {
+	analyses := []GraphAnalysis{
+		{Steps: []StepTiming{{Name: "src", Duration: time.Minute}, {Name: "e2e", Duration: time.Hour}}},
+		{Steps: []StepTiming{{Name: "src", Duration: 2 * time.Minute}, {Name: "e2e", Duration: 2 * time.Hour}}},
+		{Steps: []StepTiming{{Name: "src", Duration: 5 * time.Minute}, {Name: "unit", Duration: time.Minute}}},
+	}
+	duration := func(d time.Duration) *time.Duration { return &d }
+	expected := []StepComparison{
+		{Name: "e2e", Durations: []*time.Duration{duration(time.Hour), duration(2 * time.Hour), nil}, Regression: time.Hour},
+		{Name: "src", Durations: []*time.Duration{duration(time.Minute), duration(2 * time.Minute), duration(5 * time.Minute)}, Regression: 4 * time.Minute},
+		{Name: "unit", Durations: []*time.Duration{nil, nil, duration(time.Minute)}},
+	}
+	if diff := cmp.Diff(expected, compareGraphs(analyses)); diff != "" {
+		t.Errorf("unexpected comparison: %s", diff)
+	}
+}
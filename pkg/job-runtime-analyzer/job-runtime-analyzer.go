@@ -32,23 +32,68 @@ func podJSONURL(baseJobURL string) string {
 	return strings.Join([]string{baseJobURL, "artifacts/build-resources/pods.json"}, "/")
 }
 
-func Run(baseJobURL string) error {
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// report is the machine-readable output of the analysis.
+type report struct {
+	Jobs       []GraphAnalysis  `json:"jobs"`
+	Comparison []StepComparison `json:"comparison,omitempty"`
+}
+
+// Run analyzes the given jobs and, if there are several, compares their step
+// runtimes with those of the first one.
+func Run(jobURLs []string, output string) error {
+	var analyses []GraphAnalysis
+	for _, jobURL := range jobURLs {
+		analysis, err := analyzeJob(jobURL, output == OutputTable)
+		if err != nil {
+			return fmt.Errorf("failed to analyze job %s: %w", jobURL, err)
+		}
+		analyses = append(analyses, analysis)
+	}
+	var comparison []StepComparison
+	if len(analyses) > 1 {
+		comparison = compareGraphs(analyses)
+	}
+
+	switch output {
+	case OutputJSON:
+		raw, err := json.MarshalIndent(report{Jobs: analyses, Comparison: comparison}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		_, _ = fmt.Printf("%s\n", raw)
+	default:
+		for _, analysis := range analyses {
+			printGraphAnalysis(os.Stdout, analysis)
+		}
+		if len(comparison) > 0 {
+			printComparison(os.Stdout, analyses, comparison)
+		}
+	}
+	return nil
+}
+
+func analyzeJob(baseJobURL string, printPods bool) (GraphAnalysis, error) {
 	stepGraphRaw, err := fetchFromURL(api.StepGraphJSONURL(baseJobURL))
 	if err != nil {
-		return fmt.Errorf("failed to fetch step graph json document: %w", err)
+		return GraphAnalysis{}, fmt.Errorf("failed to fetch step graph json document: %w", err)
 	}
 	stepGraph := api.CIOperatorStepGraph{}
 	if err := json.Unmarshal(stepGraphRaw, &stepGraph); err != nil {
-		return fmt.Errorf("failed to unmarshal step graph: %w", err)
+		return GraphAnalysis{}, fmt.Errorf("failed to unmarshal step graph: %w", err)
 	}
 	rawPodList, err := fetchFromURL(podJSONURL(baseJobURL))
 	if err != nil {
-		return fmt.Errorf("failed to fetch pod json: %w", err)
+		return GraphAnalysis{}, fmt.Errorf("failed to fetch pod json: %w", err)
 	}
 
 	podList := corev1.PodList{}
 	if err := json.Unmarshal(rawPodList, &podList); err != nil {
-		return fmt.Errorf("failed to unmarshal pod list: %w", err)
+		return GraphAnalysis{}, fmt.Errorf("failed to unmarshal pod list: %w", err)
 	}
 	podList = filterPods(podList, stepGraph)
 
@@ -73,13 +118,15 @@ func Run(baseJobURL string) error {
 
 	runtimesByContainer, err := runtimesByContainer(runtimes)
 	if err != nil {
-		return fmt.Errorf("failed to calculate runtimes by container: %w", err)
+		return GraphAnalysis{}, fmt.Errorf("failed to calculate runtimes by container: %w", err)
 	}
 
-	printRuntimes("All runtimes", runtimes)
-	printRuntimeByContainer(runtimesByContainer)
+	if printPods {
+		printRuntimes("All runtimes", runtimes)
+		printRuntimeByContainer(runtimesByContainer)
+	}
 
-	return nil
+	return analyzeGraph(baseJobURL, stepGraph), nil
 }
 
 func printRuntimes(title string, data []*podContainerRuntime, footers ...[]string) {
@@ -32,8 +32,31 @@ const (
 	PresubmitPrefix        = "pull"
 	PostsubmitPrefix       = "branch"
 	PeriodicPrefix         = "periodic"
+	// ProwgenOverrideLabelPrefix prefixes the labels marking the fields of a
+	// job that are customized in the prowgen configuration. Manual edits of
+	// those fields are not kept, while edits of other fields still are.
+	ProwgenOverrideLabelPrefix = "prowgen.openshift.io/override-"
+	ProwgenOverrideValue       = "true"
 )
 
+// Fields of a job that can be customized in the prowgen configuration.
+// The trigger of a presubmit is made up of always_run, run_if_changed and
+// skip_if_only_changed, which are only valid together, so they are
+// overridden as one.
+const (
+	ProwgenOverrideTrigger        = "trigger"
+	ProwgenOverrideOptional       = "optional"
+	ProwgenOverrideLabels         = "labels"
+	ProwgenOverrideMaxConcurrency = "max-concurrency"
+	ProwgenOverrideReporterConfig = "reporter-config"
+)
+
+// ProwgenOverrideLabel is the label marking the field as customized in the
+// prowgen configuration.
+func ProwgenOverrideLabel(field string) string {
+	return ProwgenOverrideLabelPrefix + field
+}
+
 // Info describes the metadata for a Prow job configuration file
 type Info struct {
 	Org    string
@@ -414,11 +437,17 @@ func mergeJobConfig(destination, source *prowconfig.JobConfig, allJobs sets.Stri
 func mergePresubmits(old, new *prowconfig.Presubmit) prowconfig.Presubmit {
 	merged := *new
 
-	merged.AlwaysRun = old.AlwaysRun
-	merged.RunIfChanged = old.RunIfChanged
-	merged.SkipIfOnlyChanged = old.SkipIfOnlyChanged
-	merged.Optional = old.Optional
-	merged.MaxConcurrency = old.MaxConcurrency
+	if keepsManualValue(old.JobBase, new.JobBase, ProwgenOverrideTrigger) {
+		merged.AlwaysRun = old.AlwaysRun
+		merged.RunIfChanged = old.RunIfChanged
+		merged.SkipIfOnlyChanged = old.SkipIfOnlyChanged
+	}
+	if keepsManualValue(old.JobBase, new.JobBase, ProwgenOverrideOptional) {
+		merged.Optional = old.Optional
+	}
+	if keepsManualValue(old.JobBase, new.JobBase, ProwgenOverrideMaxConcurrency) {
+		merged.MaxConcurrency = old.MaxConcurrency
+	}
 	merged.SkipReport = old.SkipReport
 	if old.Cluster != "" {
 		merged.Cluster = old.Cluster
@@ -433,7 +462,7 @@ func mergePresubmits(old, new *prowconfig.Presubmit) prowconfig.Presubmit {
 func mergePostsubmits(old, new *prowconfig.Postsubmit) prowconfig.Postsubmit {
 	merged := *new
 
-	if _, ok := merged.Labels[cioperatorapi.PromotionJobLabelKey]; !ok {
+	if _, ok := merged.Labels[cioperatorapi.PromotionJobLabelKey]; !ok && keepsManualValue(old.JobBase, new.JobBase, ProwgenOverrideMaxConcurrency) {
 		merged.MaxConcurrency = old.MaxConcurrency
 	}
 	if old.Cluster != "" {
@@ -449,8 +478,12 @@ func mergePostsubmits(old, new *prowconfig.Postsubmit) prowconfig.Postsubmit {
 func mergePeriodics(old, new *prowconfig.Periodic) prowconfig.Periodic {
 	merged := *new
 
-	merged.MaxConcurrency = old.MaxConcurrency
-	merged.ReporterConfig = old.ReporterConfig
+	if keepsManualValue(old.JobBase, new.JobBase, ProwgenOverrideMaxConcurrency) {
+		merged.MaxConcurrency = old.MaxConcurrency
+	}
+	if keepsManualValue(old.JobBase, new.JobBase, ProwgenOverrideReporterConfig) {
+		merged.ReporterConfig = old.ReporterConfig
+	}
 	if old.Cluster != "" {
 		merged.Cluster = old.Cluster
 	}
@@ -458,6 +491,20 @@ func mergePeriodics(old, new *prowconfig.Periodic) prowconfig.Periodic {
 	return merged
 }
 
+// isOverridden determines if the field of the job is customized in the
+// prowgen configuration, which then takes precedence over manual edits.
+func isOverridden(job prowconfig.JobBase, field string) bool {
+	return job.Labels[ProwgenOverrideLabel(field)] == ProwgenOverrideValue
+}
+
+// keepsManualValue determines if the field of the old job holds a manual edit
+// that should survive regeneration. Fields overridden in the old job hold the
+// previous override rather than a manual edit, so when the override is removed
+// the field is reset to the generated value.
+func keepsManualValue(old, new prowconfig.JobBase, field string) bool {
+	return !isOverridden(new, field) && !isOverridden(old, field)
+}
+
 // sortConfigFields sorts array fields inside of job configurations so
 // that their serialized form is stable and deterministic
 func sortConfigFields(jobConfig *prowconfig.JobConfig) {
@@ -407,6 +407,106 @@ func TestMergePresubmits(t *testing.T) {
 				RerunCommand:        "something",
 			},
 		},
+		{
+			name: "fields overridden in the prowgen config are taken from the new job",
+			old: &prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name:           "pull-ci-super-duper",
+					MaxConcurrency: 10,
+					Cluster:        "somewhere",
+				},
+				AlwaysRun: true,
+				Reporter:  prowconfig.Reporter{SkipReport: true},
+				Optional:  true,
+			},
+			new: &prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name: "pull-ci-super-duper",
+					Labels: map[string]string{
+						ProwgenOverrideLabel(ProwgenOverrideTrigger):        ProwgenOverrideValue,
+						ProwgenOverrideLabel(ProwgenOverrideMaxConcurrency): ProwgenOverrideValue,
+					},
+					MaxConcurrency: 2,
+				},
+				RegexpChangeMatcher: prowconfig.RegexpChangeMatcher{RunIfChanged: "^pkg/"},
+			},
+			expected: prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name: "pull-ci-super-duper",
+					Labels: map[string]string{
+						ProwgenOverrideLabel(ProwgenOverrideTrigger):        ProwgenOverrideValue,
+						ProwgenOverrideLabel(ProwgenOverrideMaxConcurrency): ProwgenOverrideValue,
+					},
+					MaxConcurrency: 2,
+					Cluster:        "somewhere",
+				},
+				Reporter:            prowconfig.Reporter{SkipReport: true},
+				RegexpChangeMatcher: prowconfig.RegexpChangeMatcher{RunIfChanged: "^pkg/"},
+				Optional:            true,
+			},
+		},
+		{
+			name: "overriding only labels keeps manual edits of other fields",
+			old: &prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name:           "pull-ci-super-duper",
+					Labels:         map[string]string{"team": "old"},
+					MaxConcurrency: 10,
+				},
+				RegexpChangeMatcher: prowconfig.RegexpChangeMatcher{RunIfChanged: "^docs/"},
+				Optional:            true,
+			},
+			new: &prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name: "pull-ci-super-duper",
+					Labels: map[string]string{
+						"team": "new",
+						ProwgenOverrideLabel(ProwgenOverrideLabels): ProwgenOverrideValue,
+					},
+				},
+				AlwaysRun: true,
+			},
+			expected: prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name: "pull-ci-super-duper",
+					Labels: map[string]string{
+						"team": "new",
+						ProwgenOverrideLabel(ProwgenOverrideLabels): ProwgenOverrideValue,
+					},
+					MaxConcurrency: 10,
+				},
+				RegexpChangeMatcher: prowconfig.RegexpChangeMatcher{RunIfChanged: "^docs/"},
+				Optional:            true,
+			},
+		},
+		{
+			name: "fields whose override was removed are reset to the generated values",
+			old: &prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name: "pull-ci-super-duper",
+					Labels: map[string]string{
+						ProwgenOverrideLabel(ProwgenOverrideTrigger):        ProwgenOverrideValue,
+						ProwgenOverrideLabel(ProwgenOverrideMaxConcurrency): ProwgenOverrideValue,
+					},
+					MaxConcurrency: 2,
+				},
+				RegexpChangeMatcher: prowconfig.RegexpChangeMatcher{RunIfChanged: "^pkg/"},
+				Optional:            true,
+			},
+			new: &prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name: "pull-ci-super-duper",
+				},
+				AlwaysRun: true,
+			},
+			expected: prowconfig.Presubmit{
+				JobBase: prowconfig.JobBase{
+					Name: "pull-ci-super-duper",
+				},
+				AlwaysRun: true,
+				Optional:  true,
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -0,0 +1,165 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FlakeProperty is the name of the test suite property recording each test
+// that failed before passing in the same run.
+const FlakeProperty = "flake"
+
+// IsResultFile determines if the file is a jUnit result, named `junit*.xml`.
+func IsResultFile(name string) bool {
+	return strings.HasPrefix(name, "junit") && filepath.Ext(name) == ".xml"
+}
+
+// Collect finds all `junit*.xml` files in the subdirectories of the root and
+// merges them into one suite per step. ci-operator copies the results of each
+// multi-stage test step to `<test>/<step>/`, so files are grouped by the first
+// two directories of their path relative to the root and the suite is named
+// `<test>/<step>`. Files directly in the root are ignored, as those are
+// written by ci-operator itself. Test cases that ran more than once in a step
+// are deduplicated, see Deduplicate.
+func Collect(root string) ([]*TestSuite, error) {
+	byStep := map[string]*TestSuite{}
+	if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !IsResultFile(info.Name()) {
+			return nil
+		}
+		dir, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if dir == "." {
+			return nil
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", path, err)
+		}
+		suites, err := parse(raw)
+		if err != nil {
+			return fmt.Errorf("could not parse %s: %w", path, err)
+		}
+		stepDir := strings.SplitN(filepath.ToSlash(dir), "/", 3)
+		if len(stepDir) > 2 {
+			stepDir = stepDir[:2]
+		}
+		key := strings.Join(stepDir, "/")
+		suite, ok := byStep[key]
+		if !ok {
+			suite = &TestSuite{Name: key}
+			byStep[key] = suite
+		}
+		for _, parsed := range suites {
+			mergeInto(suite, parsed)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range byStep {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var merged []*TestSuite
+	for _, key := range keys {
+		suite := byStep[key]
+		Deduplicate(suite)
+		merged = append(merged, suite)
+	}
+	return merged, nil
+}
+
+// mergeInto adds the suite to the children of the parent. Suites with the
+// same name, as written when a step retries its tests, are merged into one
+// so that the results of all attempts can be compared.
+func mergeInto(parent, suite *TestSuite) {
+	for _, child := range parent.Children {
+		if child.Name == suite.Name {
+			child.Properties = append(child.Properties, suite.Properties...)
+			child.TestCases = append(child.TestCases, suite.TestCases...)
+			for _, nested := range suite.Children {
+				mergeInto(child, nested)
+			}
+			return
+		}
+	}
+	parent.Children = append(parent.Children, suite)
+}
+
+// parse reads either a collection of suites or one suite.
+func parse(raw []byte) ([]*TestSuite, error) {
+	var suites TestSuites
+	if err := xml.Unmarshal(raw, &suites); err == nil {
+		return suites.Suites, nil
+	}
+	var suite TestSuite
+	if err := xml.Unmarshal(raw, &suite); err != nil {
+		return nil, err
+	}
+	return []*TestSuite{&suite}, nil
+}
+
+// Deduplicate collapses test cases with the same name in each suite into one.
+// Later results take precedence, so a test that failed and then passed is
+// reported as passing, with the failure recorded in its output and the test
+// recorded as a flake in the suite's properties. The counts of every suite
+// are updated to include the test cases of its children.
+func Deduplicate(suite *TestSuite) {
+	var order []string
+	byName := map[string][]*TestCase{}
+	for _, testCase := range suite.TestCases {
+		if _, seen := byName[testCase.Name]; !seen {
+			order = append(order, testCase.Name)
+		}
+		byName[testCase.Name] = append(byName[testCase.Name], testCase)
+	}
+	suite.TestCases = nil
+	for _, name := range order {
+		runs := byName[name]
+		last := runs[len(runs)-1]
+		if last.FailureOutput == nil && last.SkipMessage == nil {
+			var failures []*TestCase
+			for _, run := range runs[:len(runs)-1] {
+				if run.FailureOutput != nil {
+					failures = append(failures, run)
+				}
+			}
+			if len(failures) > 0 {
+				flaked := failures[len(failures)-1].FailureOutput
+				last.SystemOut = strings.TrimSpace(fmt.Sprintf("Test failed %d time(s) before passing, last failure: %s\n%s\n\n%s", len(failures), flaked.Message, flaked.Output, last.SystemOut))
+				suite.Properties = append(suite.Properties, &TestSuiteProperty{Name: FlakeProperty, Value: name})
+			}
+		}
+		suite.TestCases = append(suite.TestCases, last)
+	}
+
+	suite.NumTests, suite.NumFailed, suite.NumSkipped = 0, 0, 0
+	for _, testCase := range suite.TestCases {
+		suite.NumTests++
+		switch {
+		case testCase.FailureOutput != nil:
+			suite.NumFailed++
+		case testCase.SkipMessage != nil:
+			suite.NumSkipped++
+		}
+	}
+	for _, child := range suite.Children {
+		Deduplicate(child)
+		suite.NumTests += child.NumTests
+		suite.NumFailed += child.NumFailed
+		suite.NumSkipped += child.NumSkipped
+	}
+}
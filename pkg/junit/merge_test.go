@@ -0,0 +1,79 @@
+package junit
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		// written by ci-operator itself
+		"junit_operator.xml": `<testsuites><testsuite name="operator"><testcase name="ignored"/></testsuite></testsuites>`,
+		"e2e/test/junit_e2e_1.xml": `<testsuites><testsuite name="openshift-tests" tests="3" failures="2">
+<testcase name="flaky"><failure message="timed out">first attempt</failure></testcase>
+<testcase name="broken"><failure message="broken">always</failure></testcase>
+<testcase name="stable"/>
+</testsuite></testsuites>`,
+		"e2e/test/retry/junit_e2e_2.xml": `<testsuites><testsuite name="openshift-tests" tests="2" failures="1">
+<testcase name="flaky"/>
+<testcase name="broken"><failure message="broken">still</failure></testcase>
+</testsuite></testsuites>`,
+		"e2e/setup/junit_install.xml": `<testsuite name="install" tests="99"><testcase name="install"/><testcase name="install"/><testcase name="optional"><skipped message="not needed"/></testcase></testsuite>`,
+		"e2e/setup/build-log.txt":     "not a result",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actual, err := Collect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []*TestSuite{
+		{
+			Name:       "e2e/setup",
+			NumTests:   2,
+			NumSkipped: 1,
+			Children: []*TestSuite{{
+				Name:       "install",
+				NumTests:   2,
+				NumSkipped: 1,
+				TestCases: []*TestCase{
+					{Name: "install"},
+					{Name: "optional", SkipMessage: &SkipMessage{Message: "not needed"}},
+				},
+			}},
+		},
+		{
+			Name:      "e2e/test",
+			NumTests:  3,
+			NumFailed: 1,
+			Children: []*TestSuite{{
+				Name:       "openshift-tests",
+				NumTests:   3,
+				NumFailed:  1,
+				Properties: []*TestSuiteProperty{{Name: FlakeProperty, Value: "flaky"}},
+				TestCases: []*TestCase{
+					{Name: "flaky", SystemOut: "Test failed 1 time(s) before passing, last failure: timed out\nfirst attempt"},
+					{Name: "broken", FailureOutput: &FailureOutput{Message: "broken", Output: "still"}},
+					{Name: "stable"},
+				},
+			}},
+		},
+	}
+	if diff := cmp.Diff(expected, actual, cmpopts.IgnoreTypes(xml.Name{})); diff != "" {
+		t.Errorf("unexpected suites: %s", diff)
+	}
+}
@@ -0,0 +1,210 @@
+package lease
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/boskos/common"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// localLeaseExpiry is how long a lease in a local file is held without a
+	// heartbeat before it is considered abandoned and free to be acquired.
+	localLeaseExpiry = 5 * time.Minute
+	// localPollInterval is how often acquisition is retried while there are
+	// no free resources of the requested type.
+	localPollInterval = 5 * time.Second
+)
+
+// LocalResources is the content of the file used by the local lease backend.
+// Users declare the leasable resources, while the leases are maintained by
+// the clients sharing the file.
+type LocalResources struct {
+	// Resources declares the leasable resources, by type.
+	Resources []LocalResourceType `json:"resources"`
+	// Leases holds the currently leased resources, by name.
+	Leases map[string]LocalLease `json:"leases,omitempty"`
+}
+
+// LocalResourceType declares resources of one type. Resources are either
+// named explicitly or a number of them is generated as `<type>-<index>`.
+type LocalResourceType struct {
+	Type  string   `json:"type"`
+	Names []string `json:"names,omitempty"`
+	Count int      `json:"count,omitempty"`
+}
+
+// LocalLease records the holder of a resource.
+type LocalLease struct {
+	Type    string    `json:"type"`
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+func (r *LocalResources) names(rtype string) ([]string, bool) {
+	for _, t := range r.Resources {
+		if t.Type != rtype {
+			continue
+		}
+		names := append([]string{}, t.Names...)
+		for i := 0; i < t.Count; i++ {
+			names = append(names, fmt.Sprintf("%s-%d", t.Type, i))
+		}
+		return names, true
+	}
+	return nil, false
+}
+
+// NewLocalClient creates a client that leases resources declared in a local
+// file with the specified owner, without the need for a lease server. The
+// file may be shared by any number of processes on the same host.
+func NewLocalClient(owner, path string, retries int, acquireTimeout time.Duration) (Client, error) {
+	randId = func() string {
+		return strconv.Itoa(rand.Int())
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("could not access lease file: %w", err)
+	}
+	return newClient(&localClient{owner: owner, path: path, now: time.Now, interval: localPollInterval}, retries, acquireTimeout), nil
+}
+
+type localClient struct {
+	owner    string
+	path     string
+	now      func() time.Time
+	interval time.Duration
+}
+
+// update applies a modification to the lease file while holding an exclusive
+// lock on it. Leases that expired are dropped before `f` is called.
+func (c *localClient) update(f func(*LocalResources) error) error {
+	file, err := os.OpenFile(c.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("could not open lease file: %w", err)
+	}
+	// closing the file releases the lock
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("could not lock lease file: %w", err)
+	}
+	raw, err := ioutil.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("could not read lease file: %w", err)
+	}
+	var resources LocalResources
+	if err := yaml.Unmarshal(raw, &resources); err != nil {
+		return fmt.Errorf("could not parse lease file: %w", err)
+	}
+	now := c.now()
+	for name, lease := range resources.Leases {
+		if now.After(lease.Expires) {
+			delete(resources.Leases, name)
+		}
+	}
+	if resources.Leases == nil {
+		resources.Leases = map[string]LocalLease{}
+	}
+	if err := f(&resources); err != nil {
+		return err
+	}
+	if raw, err = yaml.Marshal(resources); err != nil {
+		return fmt.Errorf("could not serialize lease file: %w", err)
+	}
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("could not truncate lease file: %w", err)
+	}
+	if _, err := file.WriteAt(raw, 0); err != nil {
+		return fmt.Errorf("could not write lease file: %w", err)
+	}
+	return nil
+}
+
+func (c *localClient) AcquireWaitWithPriority(ctx context.Context, rtype, _, _, _ string) (*common.Resource, error) {
+	for {
+		var acquired string
+		if err := c.update(func(r *LocalResources) error {
+			names, ok := r.names(rtype)
+			if !ok {
+				return fmt.Errorf("unknown resource type %q", rtype)
+			}
+			for _, name := range names {
+				if _, leased := r.Leases[name]; !leased {
+					acquired = name
+					r.Leases[name] = LocalLease{Type: rtype, Owner: c.owner, Expires: c.now().Add(localLeaseExpiry)}
+					return nil
+				}
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if acquired != "" {
+			return &common.Resource{Name: acquired, Type: rtype, State: leasedState, Owner: c.owner}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ErrNotFound
+		case <-time.After(c.interval):
+		}
+	}
+}
+
+func (c *localClient) UpdateOne(name, _ string, _ *common.UserData) error {
+	return c.update(func(r *LocalResources) error {
+		lease, ok := r.Leases[name]
+		if !ok || lease.Owner != c.owner {
+			return fmt.Errorf("resource %q is not leased by %s", name, c.owner)
+		}
+		lease.Expires = c.now().Add(localLeaseExpiry)
+		r.Leases[name] = lease
+		return nil
+	})
+}
+
+func (c *localClient) ReleaseOne(name, _ string) error {
+	return c.update(func(r *LocalResources) error {
+		if lease, ok := r.Leases[name]; !ok || lease.Owner != c.owner {
+			return fmt.Errorf("resource %q is not leased by %s", name, c.owner)
+		}
+		delete(r.Leases, name)
+		return nil
+	})
+}
+
+func (c *localClient) ReleaseAll(_ string) error {
+	return c.update(func(r *LocalResources) error {
+		for name, lease := range r.Leases {
+			if lease.Owner == c.owner {
+				delete(r.Leases, name)
+			}
+		}
+		return nil
+	})
+}
+
+func (c *localClient) Metric(rtype string) (common.Metric, error) {
+	metric := common.NewMetric(rtype)
+	err := c.update(func(r *LocalResources) error {
+		names, ok := r.names(rtype)
+		if !ok {
+			return fmt.Errorf("unknown resource type %q", rtype)
+		}
+		for _, name := range names {
+			if lease, leased := r.Leases[name]; leased {
+				metric.Current[leasedState]++
+				metric.Owners[lease.Owner]++
+			} else {
+				metric.Current[freeState]++
+			}
+		}
+		return nil
+	})
+	return metric, err
+}
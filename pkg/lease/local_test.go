@@ -0,0 +1,91 @@
+package lease
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+func TestLocalClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leases.yaml")
+	if err := ioutil.WriteFile(path, []byte(`resources:
+- type: aws-quota-slice
+  count: 2
+- type: gcp-quota-slice
+  names:
+  - us-east1
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	newLocal := func(owner string) *localClient {
+		return &localClient{owner: owner, path: path, now: func() time.Time { return now }, interval: time.Millisecond}
+	}
+	ctx := context.Background()
+	first, second := newClient(newLocal("first"), 0, time.Second), newClient(newLocal("second"), 0, 10*time.Millisecond)
+
+	var cancelled bool
+	names, err := first.Acquire("aws-quota-slice", 2, ctx, func() { cancelled = true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"aws-quota-slice-0", "aws-quota-slice-1"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("wrong leases: %v", diff.ObjectDiff(names, expected))
+	}
+	if _, err := second.Acquire("aws-quota-slice", 1, ctx, nil); err != ErrNotFound {
+		t.Fatalf("expected acquisition to time out, got %v", err)
+	}
+	if _, err := second.Acquire("azure-quota-slice", 1, ctx, nil); err == nil {
+		t.Fatal("expected acquisition of an unknown type to fail")
+	}
+	metrics, err := second.Metrics("aws-quota-slice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Metrics{Leased: 2}); metrics != expected {
+		t.Fatalf("wrong metrics: %v", diff.ObjectDiff(metrics, expected))
+	}
+	if err := first.Release("aws-quota-slice-0"); err != nil {
+		t.Fatal(err)
+	}
+	if names, err = second.Acquire("aws-quota-slice", 1, ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"aws-quota-slice-0"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("wrong leases: %v", diff.ObjectDiff(names, expected))
+	}
+
+	// a heartbeat extends the lease, which otherwise expires
+	now = now.Add(localLeaseExpiry / 2)
+	if err := second.Heartbeat(); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(localLeaseExpiry / 2).Add(time.Second)
+	if names, err = newClient(newLocal("third"), 0, time.Second).Acquire("aws-quota-slice", 1, ctx, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"aws-quota-slice-1"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("wrong leases: %v", diff.ObjectDiff(names, expected))
+	}
+	if err := first.Heartbeat(); err == nil {
+		t.Fatal("expected heartbeat for an expired lease to fail")
+	}
+	if !cancelled {
+		t.Fatal("cancel function not called")
+	}
+
+	if _, err := second.ReleaseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if metrics, err = second.Metrics("aws-quota-slice"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Metrics{Free: 1, Leased: 1}); metrics != expected {
+		t.Fatalf("wrong metrics: %v", diff.ObjectDiff(metrics, expected))
+	}
+}
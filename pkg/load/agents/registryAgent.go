@@ -1,7 +1,11 @@
 package agents
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -21,9 +25,18 @@ type RegistryAgent interface {
 	ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error)
 	GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata)
 	GetGeneration() int
+	// GetVersion returns the content hash of the registry currently loaded.
+	GetVersion() string
+	// GetDeprecations returns the deprecated components of the registry.
+	GetDeprecations() registry.Deprecations
 	registry.Resolver
 }
 
+// maxCachedSnapshots is the number of older versions of the registry kept in
+// memory to resolve tests pinned to them. It only applies when snapshots are
+// persisted, as versions evicted from memory could not be loaded otherwise.
+const maxCachedSnapshots = 10
+
 type registryAgent struct {
 	lock          *sync.RWMutex
 	resolver      registry.Resolver
@@ -36,6 +49,18 @@ type registryAgent struct {
 	workflows     registry.WorkflowByName
 	documentation map[string]string
 	metadata      api.RegistryMetadata
+	deprecations  registry.Deprecations
+	version       string
+	// snapshotDir holds snapshots of every version of the registry
+	// that was loaded, if set
+	snapshotDir string
+
+	snapshotLock sync.Mutex
+	// snapshots holds resolvers for older versions of the registry
+	snapshots map[string]registry.Resolver
+	// snapshotsUsed orders the cached versions from the least to the
+	// most recently used one
+	snapshotsUsed []string
 }
 
 var registryReloadTimeMetric = prometheus.NewHistogram(
@@ -57,6 +82,9 @@ type RegistryAgentOptions struct {
 	// FlatRegistry describes if the registry is flat, which means org/repo/branch info can not be inferred
 	// from the filepath. Defaults to true.
 	FlatRegistry *bool
+	// SnapshotDir is the directory to persist snapshots of the registry in,
+	// so that tests can be resolved with older versions after a restart.
+	SnapshotDir string
 }
 
 type RegistryAgentOption func(*RegistryAgentOptions)
@@ -73,6 +101,14 @@ func WithRegistryFlat(v bool) RegistryAgentOption {
 	}
 }
 
+// WithRegistrySnapshots persists snapshots of every loaded version of the
+// registry in the directory.
+func WithRegistrySnapshots(dir string) RegistryAgentOption {
+	return func(o *RegistryAgentOptions) {
+		o.SnapshotDir = dir
+	}
+}
+
 // NewRegistryAgent returns a RegistryAgent interface that automatically reloads when
 // the registry is changed on disk.
 func NewRegistryAgent(registryPath string, opts ...RegistryAgentOption) (RegistryAgent, error) {
@@ -87,7 +123,7 @@ func NewRegistryAgent(registryPath string, opts ...RegistryAgentOption) (Registr
 		opt.FlatRegistry = utilpointer.BoolPtr(true)
 	}
 
-	a := &registryAgent{registryPath: registryPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric, flatRegistry: *opt.FlatRegistry}
+	a := &registryAgent{registryPath: registryPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric, flatRegistry: *opt.FlatRegistry, snapshotDir: opt.SnapshotDir, snapshots: map[string]registry.Resolver{}}
 	// Load config once so we fail early if that doesn't work and are ready as soon as we return
 	if err := a.loadRegistry(); err != nil {
 		return nil, fmt.Errorf("failed to load registry: %w", err)
@@ -103,9 +139,7 @@ func (a *registryAgent) recordError(label string) {
 
 // ResolveConfig uses the registryAgent's resolver to resolve a provided ReleaseBuildConfiguration
 func (a *registryAgent) ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
-	a.lock.RLock()
-	defer a.lock.RUnlock()
-	return registry.ResolveConfig(a.resolver, config)
+	return registry.ResolveConfig(a, config)
 }
 
 func (a *registryAgent) GetGeneration() int {
@@ -114,6 +148,18 @@ func (a *registryAgent) GetGeneration() int {
 	return a.generation
 }
 
+func (a *registryAgent) GetVersion() string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.version
+}
+
+func (a *registryAgent) GetDeprecations() registry.Deprecations {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.deprecations
+}
+
 func (a *registryAgent) GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata) {
 	return a.references, a.chains, a.workflows, a.documentation, a.metadata
 }
@@ -124,17 +170,33 @@ func (a *registryAgent) loadRegistry() error {
 		a.lock.Lock()
 		defer a.lock.Unlock()
 		startTime := time.Now()
-		references, chains, workflows, documentation, metadata, observers, err := load.Registry(a.registryPath, a.flatRegistry)
+		references, chains, workflows, documentation, metadata, observers, deprecations, err := load.Registry(a.registryPath, a.flatRegistry)
 		if err != nil {
 			a.recordError("failed to load ci-operator registry")
 			return time.Duration(0), fmt.Errorf("failed to load ci-operator registry (%w)", err)
 		}
+		snapshot := registry.Snapshot{References: references, Chains: chains, Workflows: workflows, Observers: observers, Deprecations: deprecations}
+		version, err := snapshot.Version()
+		if err != nil {
+			a.recordError("failed to determine registry version")
+			return time.Duration(0), err
+		}
+		if err := a.saveSnapshot(version, snapshot); err != nil {
+			// we can still serve the current version
+			a.recordError("failed to save registry snapshot")
+			logrus.WithError(err).Error("Failed to save registry snapshot.")
+		}
+		if a.resolver != nil && a.version != version {
+			a.cacheSnapshot(a.version, a.resolver)
+		}
 		a.references = references
 		a.chains = chains
 		a.workflows = workflows
 		a.documentation = documentation
 		a.metadata = metadata
-		a.resolver = registry.NewResolver(references, chains, workflows, observers)
+		a.deprecations = deprecations
+		a.resolver = registry.NewResolver(references, chains, workflows, observers, deprecations)
+		a.version = version
 		a.generation++
 		return time.Since(startTime), nil
 	}()
@@ -142,10 +204,119 @@ func (a *registryAgent) loadRegistry() error {
 		return err
 	}
 	configReloadTimeMetric.Observe(duration.Seconds())
-	logrus.WithField("duration", duration).Info("Registry reloaded")
+	logrus.WithFields(logrus.Fields{"duration": duration, "version": a.GetVersion()}).Info("Registry reloaded")
 	return nil
 }
 
+// Resolve resolves the test with the version of the registry it is pinned
+// to, or the current one, recording the version used in the result.
 func (a *registryAgent) Resolve(name string, config api.MultiStageTestConfiguration) (api.MultiStageTestConfigurationLiteral, error) {
-	return a.resolver.Resolve(name, config)
+	resolver, version, err := a.resolverFor(config.RegistryVersion)
+	if err != nil {
+		return api.MultiStageTestConfigurationLiteral{}, err
+	}
+	config.RegistryVersion = ""
+	literal, err := resolver.Resolve(name, config)
+	if err != nil {
+		return literal, err
+	}
+	literal.RegistryVersion = version
+	return literal, nil
+}
+
+func (a *registryAgent) resolverFor(version string) (registry.Resolver, string, error) {
+	a.lock.RLock()
+	current, currentVersion := a.resolver, a.version
+	a.lock.RUnlock()
+	if version == "" || version == currentVersion {
+		return current, currentVersion, nil
+	}
+	if err := registry.ValidateVersion(version); err != nil {
+		return nil, "", err
+	}
+
+	a.snapshotLock.Lock()
+	resolver, cached := a.snapshots[version]
+	if cached {
+		a.useSnapshot(version)
+	}
+	a.snapshotLock.Unlock()
+	if cached {
+		return resolver, version, nil
+	}
+	if a.snapshotDir == "" {
+		return nil, "", fmt.Errorf("registry version %s is not available", version)
+	}
+	raw, err := ioutil.ReadFile(a.snapshotPath(version))
+	if os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("registry version %s is not available", version)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("could not read registry version %s: %w", version, err)
+	}
+	var snapshot registry.Snapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, "", fmt.Errorf("could not parse registry version %s: %w", version, err)
+	}
+	resolver = registry.NewResolver(snapshot.References, snapshot.Chains, snapshot.Workflows, snapshot.Observers, snapshot.Deprecations)
+	a.cacheSnapshot(version, resolver)
+	return resolver, version, nil
+}
+
+// cacheSnapshot keeps the resolver for the version in memory. When snapshots
+// are persisted, the least recently used version is evicted once more than
+// maxCachedSnapshots are cached; without them, every version is kept, as
+// evicted versions could not be resolved anymore.
+func (a *registryAgent) cacheSnapshot(version string, resolver registry.Resolver) {
+	a.snapshotLock.Lock()
+	defer a.snapshotLock.Unlock()
+	a.snapshots[version] = resolver
+	a.useSnapshot(version)
+	if a.snapshotDir == "" {
+		return
+	}
+	for len(a.snapshotsUsed) > maxCachedSnapshots {
+		delete(a.snapshots, a.snapshotsUsed[0])
+		a.snapshotsUsed = a.snapshotsUsed[1:]
+	}
+}
+
+// useSnapshot marks the cached version as the most recently used one. It
+// must be called with the snapshot lock held.
+func (a *registryAgent) useSnapshot(version string) {
+	for i, used := range a.snapshotsUsed {
+		if used == version {
+			a.snapshotsUsed = append(a.snapshotsUsed[:i], a.snapshotsUsed[i+1:]...)
+			break
+		}
+	}
+	a.snapshotsUsed = append(a.snapshotsUsed, version)
+}
+
+func (a *registryAgent) snapshotPath(version string) string {
+	return filepath.Join(a.snapshotDir, version+".json")
+}
+
+// saveSnapshot persists the version of the registry, unless it already is.
+func (a *registryAgent) saveSnapshot(version string, snapshot registry.Snapshot) error {
+	if a.snapshotDir == "" {
+		return nil
+	}
+	path := a.snapshotPath(version)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("could not marshal registry snapshot: %w", err)
+	}
+	// write to a temporary file first so readers never see partial snapshots
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, raw, 0644); err != nil {
+		return fmt.Errorf("could not write registry snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not write registry snapshot: %w", err)
+	}
+	return nil
 }
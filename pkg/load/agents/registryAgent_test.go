@@ -0,0 +1,162 @@
+package agents
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestResolvePinnedVersion(t *testing.T) {
+	snapshotFor := func(commands string) (registry.Snapshot, string) {
+		snapshot := registry.Snapshot{
+			References: registry.ReferenceByName{"step": {As: "step", From: "src", Commands: commands}},
+			Chains:     registry.ChainByName{},
+			Workflows:  registry.WorkflowByName{},
+		}
+		version, err := snapshot.Version()
+		if err != nil {
+			t.Fatalf("failed to determine version: %v", err)
+		}
+		return snapshot, version
+	}
+	old, oldVersion := snapshotFor("echo old")
+	current, currentVersion := snapshotFor("echo new")
+	if oldVersion == currentVersion {
+		t.Fatalf("expected different content to produce different versions, got %s for both", oldVersion)
+	}
+
+	agent := &registryAgent{
+		lock:         &sync.RWMutex{},
+		errorMetrics: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test"}, []string{"error"}),
+		snapshotDir:  t.TempDir(),
+		snapshots:    map[string]registry.Resolver{},
+		resolver:     registry.NewResolver(current.References, current.Chains, current.Workflows, current.Observers, current.Deprecations),
+		version:      currentVersion,
+	}
+	if err := agent.saveSnapshot(oldVersion, old); err != nil {
+		t.Fatalf("failed to save snapshot: %v", err)
+	}
+
+	reference := "step"
+	for _, tc := range []struct {
+		name             string
+		version          string
+		expectedCommands string
+		expectedVersion  string
+		expectedErr      bool
+	}{
+		{
+			name:             "unpinned test resolves with the current version",
+			expectedCommands: "echo new",
+			expectedVersion:  currentVersion,
+		},
+		{
+			name:             "test pinned to current version",
+			version:          currentVersion,
+			expectedCommands: "echo new",
+			expectedVersion:  currentVersion,
+		},
+		{
+			name:             "test pinned to an older version resolves with its snapshot",
+			version:          oldVersion,
+			expectedCommands: "echo old",
+			expectedVersion:  oldVersion,
+		},
+		{
+			name:        "unknown version",
+			version:     "0000000000000000000000000000000000000000000000000000000000000000",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid version",
+			version:     "../../etc/passwd",
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			literal, err := agent.Resolve("test", api.MultiStageTestConfiguration{
+				Test:            []api.TestStep{{Reference: &reference}},
+				RegistryVersion: tc.version,
+			})
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(tc.expectedCommands, literal.Test[0].Commands); diff != "" {
+				t.Errorf("unexpected commands: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedVersion, literal.RegistryVersion); diff != "" {
+				t.Errorf("unexpected version: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCacheSnapshot(t *testing.T) {
+	versionFor := func(i int) string {
+		return fmt.Sprintf("%064d", i)
+	}
+	for _, tc := range []struct {
+		name        string
+		snapshotDir string
+		expected    sets.String
+	}{
+		{
+			name:        "least recently used versions are evicted when snapshots are persisted",
+			snapshotDir: "snapshots",
+			// version 0 was used after caching all others, so versions
+			// 1 and 2 are the least recently used ones
+			expected: func() sets.String {
+				expected := sets.NewString(versionFor(0))
+				for i := 3; i < maxCachedSnapshots+2; i++ {
+					expected.Insert(versionFor(i))
+				}
+				return expected
+			}(),
+		},
+		{
+			name: "nothing is evicted without persisted snapshots",
+			expected: func() sets.String {
+				expected := sets.NewString()
+				for i := 0; i < maxCachedSnapshots+2; i++ {
+					expected.Insert(versionFor(i))
+				}
+				return expected
+			}(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &registryAgent{
+				lock:        &sync.RWMutex{},
+				snapshotDir: tc.snapshotDir,
+				snapshots:   map[string]registry.Resolver{},
+			}
+			for i := 0; i < maxCachedSnapshots; i++ {
+				agent.cacheSnapshot(versionFor(i), registry.NewResolver(nil, nil, nil, nil, registry.Deprecations{}))
+			}
+			if _, _, err := agent.resolverFor(versionFor(0)); err != nil {
+				t.Fatalf("failed to resolve cached version: %v", err)
+			}
+			for i := maxCachedSnapshots; i < maxCachedSnapshots+2; i++ {
+				agent.cacheSnapshot(versionFor(i), registry.NewResolver(nil, nil, nil, nil, registry.Deprecations{}))
+			}
+			cached := sets.NewString()
+			for version := range agent.snapshots {
+				cached.Insert(version)
+			}
+			if diff := cmp.Diff(tc.expected.List(), cached.List()); diff != "" {
+				t.Errorf("unexpected cached versions: %s", diff)
+			}
+		})
+	}
+}
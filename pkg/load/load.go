@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
@@ -174,11 +175,26 @@ func Config(path, unresolvedPath, registryPath string, info *ResolverInfo) (*api
 		return nil, fmt.Errorf("invalid configuration: %w\nvalue:\n%s", err, raw)
 	}
 	if registryPath != "" {
-		refs, chains, workflows, _, _, observers, err := Registry(registryPath, false)
+		refs, chains, workflows, _, _, observers, deprecations, err := Registry(registryPath, false)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load registry: %w", err)
 		}
-		configSpec, err = registry.ResolveConfig(registry.NewResolver(refs, chains, workflows, observers), configSpec)
+		// The local registry only has one version, so tests pinned to any
+		// other version cannot be resolved as requested.
+		snapshot := registry.Snapshot{References: refs, Chains: chains, Workflows: workflows, Observers: observers}
+		version, err := snapshot.Version()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine registry version: %w", err)
+		}
+		for _, test := range configSpec.Tests {
+			if test.MultiStageTestConfiguration == nil || test.MultiStageTestConfiguration.RegistryVersion == "" {
+				continue
+			}
+			if pinned := test.MultiStageTestConfiguration.RegistryVersion; pinned != version {
+				return nil, fmt.Errorf("test %s is pinned to registry version %s, but the registry in %s is version %s", test.As, pinned, registryPath, version)
+			}
+		}
+		configSpec, err = registry.ResolveConfig(registry.NewResolver(refs, chains, workflows, observers, deprecations), configSpec)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve configuration: %w", err)
 		}
@@ -268,12 +284,27 @@ func literalConfigFromResolver(raw []byte, address string) (*api.ReleaseBuildCon
 
 // Registry takes the path to a registry config directory and returns the full set of references, chains,
 // and workflows that the registry's Resolver needs to resolve a user's MultiStageTestConfiguration
-func Registry(root string, flat bool) (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata, registry.ObserverByName, error) {
+func Registry(root string, flat bool) (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata, registry.ObserverByName, registry.Deprecations, error) {
 	references := registry.ReferenceByName{}
 	chains := registry.ChainByName{}
 	workflows := registry.WorkflowByName{}
 	observers := registry.ObserverByName{}
 	documentation := map[string]string{}
+	deprecations := registry.Deprecations{
+		References: map[string]api.Deprecation{},
+		Chains:     map[string]api.Deprecation{},
+		Workflows:  map[string]api.Deprecation{},
+	}
+	addDeprecation := func(byName map[string]api.Deprecation, name string, deprecation *api.Deprecation) error {
+		if deprecation == nil {
+			return nil
+		}
+		if err := validateDeprecation(*deprecation); err != nil {
+			return fmt.Errorf("invalid deprecation of %s: %w", name, err)
+		}
+		byName[name] = *deprecation
+		return nil
+	}
 	metadata := api.RegistryMetadata{}
 	err := filepath.WalkDir(root, func(path string, info fs.DirEntry, err error) error {
 		if info != nil && strings.HasPrefix(info.Name(), "..") {
@@ -311,7 +342,7 @@ func Registry(root string, flat bool) (registry.ReferenceByName, registry.ChainB
 				}
 			}
 			if strings.HasSuffix(path, RefSuffix) {
-				name, doc, ref, err := loadReference(raw, dir, prefix, flat)
+				name, doc, ref, deprecation, err := loadReference(raw, dir, prefix, flat)
 				if err != nil {
 					return fmt.Errorf("failed to load registry file %s: %w", path, err)
 				}
@@ -321,6 +352,9 @@ func Registry(root string, flat bool) (registry.ReferenceByName, registry.ChainB
 				if strings.TrimSuffix(filepath.Base(path), RefSuffix) != name {
 					return fmt.Errorf("filename %s does not match name of reference; filename should be %s", filepath.Base(path), fmt.Sprint(prefix, RefSuffix))
 				}
+				if err := addDeprecation(deprecations.References, name, deprecation); err != nil {
+					return fmt.Errorf("failed to load registry file %s: %w", path, err)
+				}
 				references[name] = ref
 				documentation[name] = doc
 			} else if strings.HasSuffix(path, ChainSuffix) {
@@ -335,11 +369,15 @@ func Registry(root string, flat bool) (registry.ReferenceByName, registry.ChainB
 				if strings.TrimSuffix(filepath.Base(path), ChainSuffix) != chain.Chain.As {
 					return fmt.Errorf("filename %s does not match name of chain; filename should be %s", filepath.Base(path), fmt.Sprint(prefix, ChainSuffix))
 				}
+				if err := addDeprecation(deprecations.Chains, chain.Chain.As, chain.Chain.Deprecation); err != nil {
+					return fmt.Errorf("failed to load registry file %s: %w", path, err)
+				}
 				documentation[chain.Chain.As] = chain.Chain.Documentation
 				chain.Chain.Documentation = ""
+				chain.Chain.Deprecation = nil
 				chains[chain.Chain.As] = chain.Chain
 			} else if strings.HasSuffix(path, WorkflowSuffix) {
-				name, doc, workflow, err := loadWorkflow(raw)
+				name, doc, workflow, deprecation, err := loadWorkflow(raw)
 				if err != nil {
 					return fmt.Errorf("failed to load registry file %s: %w", path, err)
 				}
@@ -349,6 +387,9 @@ func Registry(root string, flat bool) (registry.ReferenceByName, registry.ChainB
 				if strings.TrimSuffix(filepath.Base(path), WorkflowSuffix) != name {
 					return fmt.Errorf("filename %s does not match name of workflow; filename should be %s", filepath.Base(path), fmt.Sprint(prefix, WorkflowSuffix))
 				}
+				if err := addDeprecation(deprecations.Workflows, name, deprecation); err != nil {
+					return fmt.Errorf("failed to load registry file %s: %w", path, err)
+				}
 				workflows[name] = workflow
 				documentation[name] = doc
 			} else if strings.HasSuffix(path, MetadataSuffix) {
@@ -394,54 +435,69 @@ func Registry(root string, flat bool) (registry.ReferenceByName, registry.ChainB
 		return nil
 	})
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, registry.Deprecations{}, err
 	}
 	// create graph to verify that there are no cycles
 	if _, err = registry.NewGraph(references, chains, workflows); err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, registry.Deprecations{}, err
 	}
 	err = registry.Validate(references, chains, workflows, observers)
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, registry.Deprecations{}, err
 	}
-	// validate the integrity of each reference
+	// validate the integrity of each reference and the parameters of chains
 	var validationErrors []error
 	for _, r := range references {
 		if err := validation.IsValidReference(r); err != nil {
 			validationErrors = append(validationErrors, err...)
 		}
 	}
+	for _, c := range chains {
+		validationErrors = append(validationErrors, validation.IsValidChain(c)...)
+	}
 	if len(validationErrors) > 0 {
-		return nil, nil, nil, nil, nil, nil, utilerrors.NewAggregate(validationErrors)
+		return nil, nil, nil, nil, nil, nil, registry.Deprecations{}, utilerrors.NewAggregate(validationErrors)
+	}
+	return references, chains, workflows, documentation, metadata, observers, deprecations, nil
+}
+
+func validateDeprecation(deprecation api.Deprecation) error {
+	if deprecation.Message == "" {
+		return errors.New("a message is required")
+	}
+	if deprecation.RemovalDate != "" {
+		if _, err := time.Parse(api.DeprecationDateFormat, deprecation.RemovalDate); err != nil {
+			return fmt.Errorf("removal date must be formatted as YYYY-MM-DD: %w", err)
+		}
 	}
-	return references, chains, workflows, documentation, metadata, observers, nil
+	return nil
 }
 
-func loadReference(bytes []byte, baseDir, prefix string, flat bool) (string, string, api.LiteralTestStep, error) {
+func loadReference(bytes []byte, baseDir, prefix string, flat bool) (string, string, api.LiteralTestStep, *api.Deprecation, error) {
 	step := api.RegistryReferenceConfig{}
 	err := yaml.UnmarshalStrict(bytes, &step)
 	if err != nil {
-		return "", "", api.LiteralTestStep{}, err
+		return "", "", api.LiteralTestStep{}, nil, err
 	}
 	if !flat && step.Reference.Commands != fmt.Sprintf("%s%s", prefix, CommandsSuffix) {
-		return "", "", api.LiteralTestStep{}, fmt.Errorf("reference %s has invalid command file path; command should be set to %s", step.Reference.As, fmt.Sprintf("%s%s", prefix, CommandsSuffix))
+		return "", "", api.LiteralTestStep{}, nil, fmt.Errorf("reference %s has invalid command file path; command should be set to %s", step.Reference.As, fmt.Sprintf("%s%s", prefix, CommandsSuffix))
 	}
 	command, err := gzip.ReadFileMaybeGZIP(filepath.Join(baseDir, step.Reference.Commands))
 	if err != nil {
-		return "", "", api.LiteralTestStep{}, err
+		return "", "", api.LiteralTestStep{}, nil, err
 	}
 	step.Reference.Commands = string(command)
-	return step.Reference.As, step.Reference.Documentation, step.Reference.LiteralTestStep, nil
+	return step.Reference.As, step.Reference.Documentation, step.Reference.LiteralTestStep, step.Reference.Deprecation, nil
 }
 
-func loadWorkflow(bytes []byte) (string, string, api.MultiStageTestConfiguration, error) {
+func loadWorkflow(bytes []byte) (string, string, api.MultiStageTestConfiguration, *api.Deprecation, error) {
 	workflow := api.RegistryWorkflowConfig{}
 	err := yaml.UnmarshalStrict(bytes, &workflow)
 	if err != nil {
-		return "", "", api.MultiStageTestConfiguration{}, err
+		return "", "", api.MultiStageTestConfiguration{}, nil, err
 	}
 	if workflow.Workflow.Steps.Workflow != nil {
-		return "", "", api.MultiStageTestConfiguration{}, errors.New("workflows cannot contain other workflows")
+		return "", "", api.MultiStageTestConfiguration{}, nil, errors.New("workflows cannot contain other workflows")
 	}
-	return workflow.Workflow.As, workflow.Workflow.Documentation, workflow.Workflow.Steps, nil
+	return workflow.Workflow.As, workflow.Workflow.Documentation, workflow.Workflow.Steps, workflow.Workflow.Deprecation, nil
 }
@@ -3,6 +3,7 @@ package load
 import (
 	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/ghodss/yaml"
@@ -891,7 +893,7 @@ func TestRegistry(t *testing.T) {
 	)
 
 	for _, testCase := range testCases {
-		references, chains, workflows, _, _, observers, err := Registry(testCase.registryDir, testCase.flatRegistry)
+		references, chains, workflows, _, _, observers, _, err := Registry(testCase.registryDir, testCase.flatRegistry)
 		if err == nil && testCase.expectedError == true {
 			t.Errorf("%s: got no error when error was expected", testCase.name)
 		}
@@ -936,12 +938,148 @@ func TestRegistry(t *testing.T) {
 	if err := ioutil.WriteFile(filepath.Join(path, deprovisionGatherRef), fileData, 0664); err != nil {
 		t.Fatalf("failed to populate temp reference file: %v", err)
 	}
-	_, _, _, _, _, _, err = Registry(temp, false)
+	_, _, _, _, _, _, _, err = Registry(temp, false)
 	if err == nil {
 		t.Error("got no error when expecting error on incorrect reference name")
 	}
 }
 
+func TestRegistryDeprecations(t *testing.T) {
+	testCases := []struct {
+		name          string
+		chain         string
+		expected      registry.Deprecations
+		expectedError bool
+	}{
+		{
+			name: "deprecations are separated from the components",
+			chain: `chain:
+  as: chain
+  steps:
+  - ref: step
+  deprecation:
+    message: chain is going away
+    replacement: step
+    removal_date: "2021-12-31"
+`,
+			expected: registry.Deprecations{
+				References: map[string]api.Deprecation{"step": {Message: "step is going away"}},
+				Chains:     map[string]api.Deprecation{"chain": {Message: "chain is going away", Replacement: "step", RemovalDate: "2021-12-31"}},
+				Workflows:  map[string]api.Deprecation{},
+			},
+		},
+		{
+			name: "invalid removal date is an error",
+			chain: `chain:
+  as: chain
+  steps:
+  - ref: step
+  deprecation:
+    message: chain is going away
+    removal_date: "31/12/2021"
+`,
+			expectedError: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range map[string]string{
+				"step-ref.yaml": `ref:
+  as: step
+  from: base
+  commands: step-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+  deprecation:
+    message: step is going away
+`,
+				"step-commands.sh": "true",
+				"chain-chain.yaml": testCase.chain,
+			} {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+			_, chains, _, _, _, _, deprecations, err := Registry(dir, true)
+			if (err != nil) != testCase.expectedError {
+				t.Fatalf("expected error: %t, got: %v", testCase.expectedError, err)
+			}
+			if testCase.expectedError {
+				return
+			}
+			if diff := cmp.Diff(testCase.expected, deprecations); diff != "" {
+				t.Errorf("unexpected deprecations: %s", diff)
+			}
+			if chains["chain"].Deprecation != nil {
+				t.Error("deprecation was not removed from the chain")
+			}
+		})
+	}
+}
+
+func TestRegistryParameterDefaults(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stepEnv       string
+		chainEnv      string
+		expectedError string
+	}{
+		{
+			name:     "valid defaults",
+			stepEnv:  "  - name: TEST\n    type: bool\n    default: \"true\"\n",
+			chainEnv: "  - name: TEST\n    type: bool\n    default: \"false\"\n",
+		},
+		{
+			name:          "invalid default of a step",
+			stepEnv:       "  - name: TEST\n    type: bool\n    default: ture\n",
+			expectedError: `step.env[0]: invalid value for TEST: value "ture" is not a boolean, expected true or false`,
+		},
+		{
+			name:          "invalid default of a chain",
+			stepEnv:       "  - name: TEST\n",
+			chainEnv:      "  - name: TEST\n    type: enum\n    values:\n    - a\n    default: b\n",
+			expectedError: `chain.env[0]: invalid value for TEST: value "b" is not one of a`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range map[string]string{
+				"step-ref.yaml": `ref:
+  as: step
+  from: base
+  commands: step-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+  env:
+` + testCase.stepEnv,
+				"step-commands.sh": "true",
+				"chain-chain.yaml": `chain:
+  as: chain
+  steps:
+  - ref: step
+  env:
+` + testCase.chainEnv,
+			} {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+			_, _, _, _, _, _, _, err := Registry(dir, true)
+			var actual string
+			if err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedError, actual); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
 func TestPartitionByRepo(t *testing.T) {
 	var testCases = []struct {
 		name   string
@@ -1053,3 +1191,48 @@ func sortByOrgRepoSlices(in *ByOrgRepo) error {
 
 	return utilerrors.NewAggregate(errs)
 }
+
+func TestConfigWithPinnedRegistryVersion(t *testing.T) {
+	registryDir := "../../test/multistage-registry/registry"
+	refs, chains, workflows, _, _, observers, _, err := Registry(registryDir, false)
+	if err != nil {
+		t.Fatalf("failed to load registry: %v", err)
+	}
+	version, err := registry.Snapshot{References: refs, Chains: chains, Workflows: workflows, Observers: observers}.Version()
+	if err != nil {
+		t.Fatalf("failed to determine registry version: %v", err)
+	}
+	for _, tc := range []struct {
+		name          string
+		version       string
+		expectedError bool
+	}{
+		{name: "unpinned test resolves"},
+		{name: "test pinned to the local version resolves", version: version},
+		{name: "test pinned to another version is rejected", version: strings.Repeat("a", 64), expectedError: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := fmt.Sprintf(`resources:
+  '*':
+    requests:
+      cpu: 10m
+tests:
+- as: e2e
+  steps:
+    workflow: ipi
+    registry_version: %q
+`, tc.version)
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			_, err := Config(path, "", registryDir, nil)
+			if err == nil && tc.expectedError {
+				t.Error("expected an error, but got none")
+			}
+			if err != nil && !tc.expectedError {
+				t.Errorf("expected no error, but got one: %v", err)
+			}
+		})
+	}
+}
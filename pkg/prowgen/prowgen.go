@@ -184,6 +184,9 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 			if element.Cluster != "" {
 				periodic.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
 			}
+			if overrides, ok := info.Config.Tests[element.As]; ok {
+				applyOverrides(&periodic.JobBase, overrides)
+			}
 			periodics = append(periodics, *periodic)
 		} else if element.Postsubmit {
 			postsubmit := generatePostsubmitForTest(element.As, info, podSpec, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
@@ -191,6 +194,9 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 			if element.Cluster != "" {
 				postsubmit.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
 			}
+			if overrides, ok := info.Config.Tests[element.As]; ok {
+				applyOverrides(&postsubmit.JobBase, overrides)
+			}
 			postsubmits[orgrepo] = append(postsubmits[orgrepo], *postsubmit)
 		} else {
 			presubmit := *generatePresubmitForTest(element.As, info, podSpec, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
@@ -201,6 +207,9 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 			if element.Cluster != "" {
 				presubmit.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
 			}
+			if overrides, ok := info.Config.Tests[element.As]; ok {
+				applyPresubmitOverrides(&presubmit, overrides)
+			}
 			presubmits[orgrepo] = append(presubmits[orgrepo], presubmit)
 		}
 	}
@@ -275,6 +284,48 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 	}
 }
 
+// applyOverrides customizes the job as requested in the prowgen
+// configuration and marks the customized fields, so they are not replaced
+// with earlier manual edits when the job is written.
+func applyOverrides(job *prowconfig.JobBase, overrides config.JobOverrides) {
+	if len(overrides.Labels) > 0 {
+		for key, value := range overrides.Labels {
+			job.Labels[key] = value
+		}
+		markOverridden(job, jc.ProwgenOverrideLabels)
+	}
+	if overrides.MaxConcurrency != nil {
+		job.MaxConcurrency = *overrides.MaxConcurrency
+		markOverridden(job, jc.ProwgenOverrideMaxConcurrency)
+	}
+	if overrides.ReporterConfig != nil {
+		job.ReporterConfig = overrides.ReporterConfig
+		markOverridden(job, jc.ProwgenOverrideReporterConfig)
+	}
+}
+
+func applyPresubmitOverrides(presubmit *prowconfig.Presubmit, overrides config.JobOverrides) {
+	applyOverrides(&presubmit.JobBase, overrides)
+	if overrides.RunIfChanged != "" || overrides.SkipIfOnlyChanged != "" {
+		presubmit.AlwaysRun = false
+		presubmit.RunIfChanged = overrides.RunIfChanged
+		presubmit.SkipIfOnlyChanged = overrides.SkipIfOnlyChanged
+		markOverridden(&presubmit.JobBase, jc.ProwgenOverrideTrigger)
+	}
+	if overrides.AlwaysRun != nil {
+		presubmit.AlwaysRun = *overrides.AlwaysRun
+		markOverridden(&presubmit.JobBase, jc.ProwgenOverrideTrigger)
+	}
+	if overrides.Optional != nil {
+		presubmit.Optional = *overrides.Optional
+		markOverridden(&presubmit.JobBase, jc.ProwgenOverrideOptional)
+	}
+}
+
+func markOverridden(job *prowconfig.JobBase, field string) {
+	job.Labels[jc.ProwgenOverrideLabel(field)] = jc.ProwgenOverrideValue
+}
+
 func generateCiOperatorPodSpec(info *ProwgenInfo, secrets []*cioperatorapi.Secret, targets []string, additionalArgs ...string) *corev1.PodSpec {
 	for _, arg := range additionalArgs {
 		if !strings.HasPrefix(arg, "--") {
@@ -8,7 +8,9 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	corev1 "k8s.io/api/core/v1"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	prowconfig "k8s.io/test-infra/prow/config"
+	utilpointer "k8s.io/utils/pointer"
 
 	ciop "github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/config"
@@ -373,6 +375,7 @@ var (
 )
 
 func TestGenerateJobs(t *testing.T) {
+	maxConcurrency := 5
 	tests := []struct {
 		id       string
 		keep     bool
@@ -556,6 +559,34 @@ func TestGenerateJobs(t *testing.T) {
 				Branch: "branch",
 			}},
 		},
+		{
+			id: "overrides from the prowgen config",
+			config: &ciop.ReleaseBuildConfiguration{
+				Tests: []ciop.TestStepConfiguration{
+					{As: "unit", ContainerTestConfiguration: &ciop.ContainerTestConfiguration{From: "bin"}},
+					{As: "e2e", ContainerTestConfiguration: &ciop.ContainerTestConfiguration{From: "bin"}},
+					{As: "nightly", Cron: &cron, ContainerTestConfiguration: &ciop.ContainerTestConfiguration{From: "bin"}},
+				},
+			},
+			repoInfo: &ProwgenInfo{
+				Metadata: ciop.Metadata{
+					Org:    "organization",
+					Repo:   "repository",
+					Branch: "branch",
+				},
+				Config: config.Prowgen{Tests: map[string]config.JobOverrides{
+					"e2e": {
+						RunIfChanged:   "^pkg/",
+						Optional:       utilpointer.BoolPtr(true),
+						Labels:         map[string]string{"team": "installer"},
+						MaxConcurrency: &maxConcurrency,
+					},
+					"nightly": {
+						ReporterConfig: &prowv1.ReporterConfig{Slack: &prowv1.SlackReporterConfig{Channel: "#nightly"}},
+					},
+				}},
+			},
+		},
 		{
 			id: "cluster label for postsubmit",
 			config: &ciop.ReleaseBuildConfiguration{
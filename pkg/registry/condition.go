@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// evaluateConditions checks the parts of the conditions of a step that can be
+// evaluated when the test is resolved, and determines the outcome the step
+// still depends on at runtime. Conditions are only checked when a test is
+// resolved, as the parameters and cluster profile are not known otherwise.
+func (s *stack) evaluateConditions(conditions ...*api.StepCondition) (met bool, outcome api.StepOutcome, err error) {
+	met = true
+	for _, condition := range conditions {
+		if condition == nil {
+			continue
+		}
+		switch condition.Outcome {
+		case "", api.StepOutcomeSuccess, api.StepOutcomeFailure:
+		default:
+			return false, "", fmt.Errorf("invalid outcome %q in condition, expected %q or %q", condition.Outcome, api.StepOutcomeSuccess, api.StepOutcomeFailure)
+		}
+		if outcome != "" && condition.Outcome != "" && outcome != condition.Outcome {
+			return false, "", fmt.Errorf("conflicting outcomes %q and %q in conditions", outcome, condition.Outcome)
+		}
+		if condition.Outcome != "" {
+			outcome = condition.Outcome
+		}
+		if s.partial {
+			continue
+		}
+		if !condition.MatchesProfile(s.clusterProfile) {
+			met = false
+		}
+		for _, env := range condition.Env {
+			var value string
+			if v := s.resolve(env.Name); v != nil {
+				value = *v
+			}
+			var matches bool
+			for _, allowed := range env.Values {
+				if allowed == value {
+					matches = true
+					break
+				}
+			}
+			if !matches {
+				met = false
+			}
+		}
+	}
+	return met, outcome, nil
+}
+
+// withOutcome limits the resolved steps to the outcome of the steps before
+// them.
+func withOutcome(steps []api.LiteralTestStep, outcome api.StepOutcome) ([]api.LiteralTestStep, error) {
+	if outcome == "" {
+		return steps, nil
+	}
+	for i := range steps {
+		if steps[i].When != nil && steps[i].When.Outcome != outcome {
+			return nil, fmt.Errorf("step/%s: conflicting outcomes %q and %q in conditions", steps[i].As, steps[i].When.Outcome, outcome)
+		}
+		steps[i].When = &api.StepCondition{Outcome: outcome}
+	}
+	return steps, nil
+}
@@ -0,0 +1,71 @@
+package registry
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// Deprecations holds the deprecations of registry components by their name.
+type Deprecations struct {
+	References map[string]api.Deprecation `json:"references,omitempty"`
+	Chains     map[string]api.Deprecation `json:"chains,omitempty"`
+	Workflows  map[string]api.Deprecation `json:"workflows,omitempty"`
+}
+
+func (d Deprecations) byType(t Type) map[string]api.Deprecation {
+	switch t {
+	case Workflow:
+		return d.Workflows
+	case Chain:
+		return d.Chains
+	default:
+		return d.References
+	}
+}
+
+// For returns the deprecation of the component, if it is deprecated.
+func (d Deprecations) For(t Type, name string) (api.DeprecatedComponent, bool) {
+	deprecation, ok := d.byType(t)[name]
+	return api.DeprecatedComponent{Type: t.String(), Name: name, Deprecation: deprecation}, ok
+}
+
+// All lists every deprecated component, ordered by type and name.
+func (d Deprecations) All() []api.DeprecatedComponent {
+	var all []api.DeprecatedComponent
+	for _, t := range []Type{Reference, Chain, Workflow} {
+		byName := d.byType(t)
+		var names []string
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			all = append(all, api.DeprecatedComponent{Type: t.String(), Name: name, Deprecation: byName[name]})
+		}
+	}
+	return all
+}
+
+// deprecatedComponents lists the deprecated components among the ones a test
+// executes, in the order they are first used. Components excluded by the
+// conditions of the test are not recorded as used, so they are not listed.
+func (r *registry) deprecatedComponents(workflow *string, used []component) []api.DeprecatedComponent {
+	var deprecated []api.DeprecatedComponent
+	seen := map[Type]sets.String{Reference: sets.NewString(), Chain: sets.NewString(), Workflow: sets.NewString()}
+	if workflow != nil {
+		used = append([]component{{t: Workflow, name: *workflow}}, used...)
+	}
+	for _, c := range used {
+		if seen[c.t].Has(c.name) {
+			continue
+		}
+		seen[c.t].Insert(c.name)
+		if component, ok := r.deprecations.For(c.t, c.name); ok {
+			deprecated = append(deprecated, component)
+		}
+	}
+	return deprecated
+}
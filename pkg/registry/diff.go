@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// Difference describes one field that differs between two resolved tests.
+type Difference struct {
+	// Path is the path to the field in the serialized test, like
+	// `test[0].commands`.
+	Path string `json:"path"`
+	// Current is the value of the field with the current registry, unset if
+	// the field is not present.
+	Current interface{} `json:"current,omitempty"`
+	// Candidate is the value of the field with the candidate registry, unset
+	// if the field is not present.
+	Candidate interface{} `json:"candidate,omitempty"`
+}
+
+// DiffLiterals determines the fields that differ between two resolved tests,
+// ordered by their path.
+func DiffLiterals(current, candidate api.MultiStageTestConfigurationLiteral) ([]Difference, error) {
+	var before, after interface{}
+	for _, item := range []struct {
+		literal api.MultiStageTestConfigurationLiteral
+		into    *interface{}
+	}{{literal: current, into: &before}, {literal: candidate, into: &after}} {
+		raw, err := json.Marshal(item.literal)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal test: %w", err)
+		}
+		if err := json.Unmarshal(raw, item.into); err != nil {
+			return nil, fmt.Errorf("could not unmarshal test: %w", err)
+		}
+	}
+	return diffValues("", before, after), nil
+}
+
+func diffValues(path string, before, after interface{}) []Difference {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			return diffMaps(path, b, a)
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			return diffSlices(path, b, a)
+		}
+	}
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []Difference{{Path: path, Current: before, Candidate: after}}
+}
+
+func diffMaps(path string, before, after map[string]interface{}) []Difference {
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	var sorted []string
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	var differences []Difference
+	for _, key := range sorted {
+		child := key
+		if path != "" {
+			child = path + "." + key
+		}
+		differences = append(differences, diffValues(child, before[key], after[key])...)
+	}
+	return differences
+}
+
+func diffSlices(path string, before, after []interface{}) []Difference {
+	length := len(before)
+	if len(after) > length {
+		length = len(after)
+	}
+	var differences []Difference
+	for i := 0; i < length; i++ {
+		var b, a interface{}
+		if i < len(before) {
+			b = before[i]
+		}
+		if i < len(after) {
+			a = after[i]
+		}
+		differences = append(differences, diffValues(fmt.Sprintf("%s[%d]", path, i), b, a)...)
+	}
+	return differences
+}
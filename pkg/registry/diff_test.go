@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestDiffLiterals(t *testing.T) {
+	base := func() api.MultiStageTestConfigurationLiteral {
+		return api.MultiStageTestConfigurationLiteral{
+			ClusterProfile: api.ClusterProfileAWS,
+			Test: []api.LiteralTestStep{
+				{As: "step", From: "src", Commands: "make test"},
+			},
+		}
+	}
+	testCases := []struct {
+		name      string
+		candidate func(*api.MultiStageTestConfigurationLiteral)
+		expected  []Difference
+	}{
+		{
+			name:      "no changes",
+			candidate: func(*api.MultiStageTestConfigurationLiteral) {},
+		},
+		{
+			name: "changed field",
+			candidate: func(l *api.MultiStageTestConfigurationLiteral) {
+				l.Test[0].Commands = "make e2e"
+			},
+			expected: []Difference{{Path: "test[0].commands", Current: "make test", Candidate: "make e2e"}},
+		},
+		{
+			name: "added step and changed profile",
+			candidate: func(l *api.MultiStageTestConfigurationLiteral) {
+				l.ClusterProfile = api.ClusterProfileGCP
+				l.Post = []api.LiteralTestStep{{As: "post", From: "src", Commands: "true"}}
+			},
+			expected: []Difference{
+				{Path: "cluster_profile", Current: "aws", Candidate: "gcp"},
+				{Path: "post", Candidate: []interface{}{
+					map[string]interface{}{"as": "post", "from": "src", "commands": "true", "resources": map[string]interface{}{}},
+				}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			candidate := base()
+			tc.candidate(&candidate)
+			differences, err := DiffLiterals(base(), candidate)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, differences); diff != "" {
+				t.Errorf("unexpected differences: %s", diff)
+			}
+		})
+	}
+}
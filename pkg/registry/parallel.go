@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// withParallelGroup adds the resolved steps to a parallel group. Groups
+// cannot be nested, so steps that are part of another group are rejected.
+func withParallelGroup(steps []api.LiteralTestStep, group string) ([]api.LiteralTestStep, error) {
+	if group == "" {
+		return steps, nil
+	}
+	for i := range steps {
+		if steps[i].ParallelGroup != "" && steps[i].ParallelGroup != group {
+			return nil, fmt.Errorf("step/%s: parallel group %q cannot be nested in parallel group %q", steps[i].As, steps[i].ParallelGroup, group)
+		}
+		steps[i].ParallelGroup = group
+	}
+	return steps, nil
+}
+
+// checkParallelGroups ensures the steps of each parallel group are
+// consecutive, as the steps in between would otherwise have to run both
+// before and after the group.
+func checkParallelGroups(steps []api.LiteralTestStep) error {
+	done := map[string]bool{}
+	var current string
+	for _, step := range steps {
+		if step.ParallelGroup == current {
+			continue
+		}
+		if current != "" {
+			done[current] = true
+		}
+		current = step.ParallelGroup
+		if done[current] {
+			return fmt.Errorf("steps in parallel group %q must be consecutive, but step %s is separated from the rest of the group", current, step.As)
+		}
+	}
+	return nil
+}
@@ -22,7 +22,7 @@ type ObserverByName map[string]api.Observer
 // A superset of this validation is performed later when actual test
 // configurations are resolved.
 func Validate(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName) error {
-	reg := registry{stepsByName, chainsByName, workflowsByName, observersByName}
+	reg := registry{stepsByName, chainsByName, workflowsByName, observersByName, Deprecations{}}
 	var ret []error
 	for k := range chainsByName {
 		if _, err := reg.process([]api.TestStep{{Chain: &k}}, sets.NewString(), stackForChain()); err != nil {
@@ -49,14 +49,16 @@ type registry struct {
 	chainsByName    ChainByName
 	workflowsByName WorkflowByName
 	observersByName ObserverByName
+	deprecations    Deprecations
 }
 
-func NewResolver(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName) Resolver {
+func NewResolver(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName, deprecations Deprecations) Resolver {
 	return &registry{
 		stepsByName:     stepsByName,
 		chainsByName:    chainsByName,
 		workflowsByName: workflowsByName,
 		observersByName: observersByName,
+		deprecations:    deprecations,
 	}
 }
 
@@ -102,20 +104,31 @@ func (r *registry) Resolve(name string, config api.MultiStageTestConfiguration)
 		DependencyOverrides:      config.DependencyOverrides,
 	}
 	stack := stackForTest(name, config.Environment, config.Dependencies)
+	stack.clusterProfile = config.ClusterProfile
+	stack.components = &[]component{}
 	if config.Workflow != nil {
 		stack.push(stackRecordForTest("workflow/"+*config.Workflow, nil, nil))
 	}
 	pre, errs := r.process(config.Pre, sets.NewString(), stack)
 	expandedFlow.Pre = append(expandedFlow.Pre, pre...)
 	resolveErrors = append(resolveErrors, errs...)
+	if err := checkParallelGroups(pre); err != nil {
+		resolveErrors = append(resolveErrors, stack.errorf("pre: %v", err))
+	}
 
 	test, errs := r.process(config.Test, sets.NewString(), stack)
 	expandedFlow.Test = append(expandedFlow.Test, test...)
 	resolveErrors = append(resolveErrors, errs...)
+	if err := checkParallelGroups(test); err != nil {
+		resolveErrors = append(resolveErrors, stack.errorf("test: %v", err))
+	}
 
 	post, errs := r.process(config.Post, sets.NewString(), stack)
 	expandedFlow.Post = append(expandedFlow.Post, post...)
 	resolveErrors = append(resolveErrors, errs...)
+	if err := checkParallelGroups(post); err != nil {
+		resolveErrors = append(resolveErrors, stack.errorf("post: %v", err))
+	}
 	resolveErrors = append(resolveErrors, stack.checkUnused(&stack.records[0])...)
 
 	observerNames := sets.NewString()
@@ -134,6 +147,7 @@ func (r *registry) Resolve(name string, config api.MultiStageTestConfiguration)
 		observers = append(observers, observer)
 	}
 	expandedFlow.Observers = observers
+	expandedFlow.Deprecations = r.deprecatedComponents(config.Workflow, *stack.components)
 	if resolveErrors != nil {
 		return api.MultiStageTestConfigurationLiteral{}, utilerrors.NewAggregate(resolveErrors)
 	}
@@ -212,7 +226,7 @@ func (r *registry) process(steps []api.TestStep, seen sets.String, stack stack)
 			step, err := r.processStep(&step, seen, stack)
 			errs = append(errs, err...)
 			if err == nil {
-				ret = append(ret, step)
+				ret = append(ret, step...)
 			}
 		}
 	}
@@ -228,32 +242,75 @@ func (r *registry) processChain(step *api.TestStep, seen sets.String, stack stac
 	rec := stackRecordForStep("chain/"+name, chain.Environment, nil)
 	stack.push(rec)
 	defer stack.pop()
+	met, outcome, conditionErr := stack.evaluateConditions(step.When)
+	if conditionErr != nil {
+		return nil, []error{stack.errorf("%v", conditionErr)}
+	}
+	mark := stack.use(Chain, name)
+	// parameters are resolved even if the condition is not met, so that
+	// the ones only used by the chain are not reported as unused
 	ret, err := r.process(chain.Steps, seen, stack)
 	err = append(err, stack.checkUnused(&rec)...)
+	if !met {
+		for _, step := range ret {
+			seen.Delete(step.As)
+		}
+		stack.forget(mark)
+		return nil, err
+	}
+	ret, conditionErr = withOutcome(ret, outcome)
+	if conditionErr != nil {
+		err = append(err, stack.errorf("%v", conditionErr))
+	}
+	if ret, conditionErr = withParallelGroup(ret, step.ParallelGroup); conditionErr != nil {
+		err = append(err, stack.errorf("%v", conditionErr))
+	} else if groupErr := checkParallelGroups(ret); groupErr != nil {
+		err = append(err, stack.errorf("%v", groupErr))
+	}
 	return ret, err
 }
 
-func (r *registry) processStep(step *api.TestStep, seen sets.String, stack stack) (ret api.LiteralTestStep, err []error) {
+func (r *registry) processStep(step *api.TestStep, seen sets.String, stack stack) ([]api.LiteralTestStep, []error) {
+	var ret api.LiteralTestStep
 	if ref := step.Reference; ref != nil {
 		var ok bool
 		ret, ok = r.stepsByName[*ref]
 		if !ok {
-			return api.LiteralTestStep{}, []error{stack.errorf("invalid step reference: %s", *ref)}
+			return nil, []error{stack.errorf("invalid step reference: %s", *ref)}
 		}
 	} else if step.LiteralTestStep != nil {
 		ret = *step.LiteralTestStep
 	} else {
-		return api.LiteralTestStep{}, []error{stack.errorf("encountered TestStep where both `Reference` and `LiteralTestStep` are nil")}
+		return nil, []error{stack.errorf("encountered TestStep where both `Reference` and `LiteralTestStep` are nil")}
+	}
+	met, outcome, err := stack.evaluateConditions(step.When, ret.When)
+	if err != nil {
+		return nil, []error{stack.errorf("step/%s: %v", ret.As, err)}
 	}
-	if seen.Has(ret.As) {
-		return api.LiteralTestStep{}, []error{stack.errorf("duplicate name: %s", ret.As)}
+	ret.When = nil
+	if outcome != "" {
+		ret.When = &api.StepCondition{Outcome: outcome}
+	}
+	if step.ParallelGroup != "" {
+		ret.ParallelGroup = step.ParallelGroup
+	}
+	if met {
+		if seen.Has(ret.As) {
+			return nil, []error{stack.errorf("duplicate name: %s", ret.As)}
+		}
+		seen.Insert(ret.As)
+		if step.Reference != nil {
+			stack.use(Reference, *step.Reference)
+		}
 	}
-	seen.Insert(ret.As)
 	var errs []error
 	if ret.Environment != nil {
 		env := make([]api.StepParameter, 0, len(ret.Environment))
 		for _, e := range ret.Environment {
 			if v := stack.resolve(e.Name); v != nil {
+				if err := e.ValidateValue(*v); err != nil {
+					errs = append(errs, stack.errorf("step/%s: parameter %s: %v", ret.As, e.Name, err))
+				}
 				e.Default = v
 			} else if e.Default == nil && !stack.partial {
 				errs = append(errs, stack.errorf("step/%s: unresolved parameter: %s", ret.As, e.Name))
@@ -272,7 +329,12 @@ func (r *registry) processStep(step *api.TestStep, seen sets.String, stack stack
 		}
 		ret.Dependencies = deps
 	}
-	return ret, errs
+	if !met {
+		// parameters are resolved even if the condition is not met, so
+		// that the ones only used by this step are not reported as unused
+		return nil, errs
+	}
+	return []api.LiteralTestStep{ret}, errs
 }
 
 // ResolveConfig uses a resolver to resolve an entire ci-operator config
@@ -704,7 +704,7 @@ func TestResolve(t *testing.T) {
 			if !reflect.DeepEqual(err, utilerrors.NewAggregate([]error{testCase.expectedValidationErr})) {
 				t.Errorf("got incorrect validation error: %s", cmp.Diff(err, testCase.expectedValidationErr))
 			}
-			ret, err := NewResolver(testCase.stepMap, testCase.chainMap, testCase.workflowMap, testCase.observerMap).Resolve("test", testCase.config)
+			ret, err := NewResolver(testCase.stepMap, testCase.chainMap, testCase.workflowMap, testCase.observerMap, Deprecations{}).Resolve("test", testCase.config)
 			if !reflect.DeepEqual(err, utilerrors.NewAggregate([]error{testCase.expectedErr})) {
 				t.Errorf("got incorrect error: %s", cmp.Diff(err, testCase.expectedErr))
 			}
@@ -734,6 +734,7 @@ func TestResolveParameters(t *testing.T) {
 	defaultWorkflow := "workflow"
 	defaultTest := "test"
 	defaultEmpty := ""
+	timeout := "1h30m"
 	workflows := WorkflowByName{
 		workflow: api.MultiStageTestConfiguration{
 			Test:         []api.TestStep{{Chain: &grandGrandParent}},
@@ -967,9 +968,34 @@ func TestResolveParameters(t *testing.T) {
 			}},
 		},
 		err: errors.New("test/test: step/step: unresolved parameter: UNRESOLVED"),
+	}, {
+		name: "typed parameter with invalid value",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{
+				LiteralTestStep: &api.LiteralTestStep{
+					As:          "step",
+					Environment: []api.StepParameter{{Name: "FIPS_ENABLED", Default: &defaultEmpty, Type: api.StepParameterTypeBool}},
+				},
+			}},
+			Environment: api.TestEnvironment{"FIPS_ENABLED": "ture"},
+		},
+		err: errors.New(`test/test: step/step: parameter FIPS_ENABLED: value "ture" is not a boolean, expected true or false`),
+	}, {
+		name: "typed parameter with valid value",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{
+				LiteralTestStep: &api.LiteralTestStep{
+					As:          "step",
+					Environment: []api.StepParameter{{Name: "TIMEOUT", Default: &defaultEmpty, Type: api.StepParameterTypeDuration}},
+				},
+			}},
+			Environment: api.TestEnvironment{"TIMEOUT": "1h30m"},
+		},
+		expectedParams: [][]api.StepParameter{{{Name: "TIMEOUT", Default: &timeout, Type: api.StepParameterTypeDuration}}},
+		expectedDeps:   [][]api.StepDependency{nil},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
-			ret, err := NewResolver(refs, chains, workflows, observers).Resolve("test", tc.test)
+			ret, err := NewResolver(refs, chains, workflows, observers, Deprecations{}).Resolve("test", tc.test)
 			if tc.err != nil {
 				if err == nil {
 					t.Fatal("unexpected success")
@@ -1084,7 +1110,7 @@ func TestResolveLeases(t *testing.T) {
 		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
-			ret, err := NewResolver(refs, chains, workflows, ObserverByName{}).Resolve("test", tc.test)
+			ret, err := NewResolver(refs, chains, workflows, ObserverByName{}, Deprecations{}).Resolve("test", tc.test)
 			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
 				t.Errorf("unexpected error: %v", diff)
 			}
@@ -1094,3 +1120,253 @@ func TestResolveLeases(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveDeprecations(t *testing.T) {
+	ref, deprecatedRef, chain, deprecatedChain, workflow := "ref", "deprecated-ref", "chain", "deprecated-chain", "workflow"
+	refs := ReferenceByName{
+		ref:           {As: ref, From: "base", Commands: "true"},
+		deprecatedRef: {As: deprecatedRef, From: "base", Commands: "true"},
+	}
+	chains := ChainByName{
+		chain:           {As: chain, Steps: []api.TestStep{{Reference: &deprecatedRef}, {Reference: &ref}}},
+		deprecatedChain: {As: deprecatedChain, Steps: []api.TestStep{{Reference: &ref}}},
+	}
+	onAWS := &api.StepCondition{ClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS}}
+	workflows := WorkflowByName{
+		workflow: {Test: []api.TestStep{{Reference: &ref}}},
+	}
+	deprecations := Deprecations{
+		References: map[string]api.Deprecation{deprecatedRef: {Message: "do not use", Replacement: ref}},
+		Chains:     map[string]api.Deprecation{deprecatedChain: {Message: "chain is going away"}},
+		Workflows:  map[string]api.Deprecation{workflow: {Message: "workflow is going away", RemovalDate: "2021-12-31"}},
+	}
+	for _, tc := range []struct {
+		name     string
+		test     api.MultiStageTestConfiguration
+		expected []api.DeprecatedComponent
+	}{{
+		name: "nothing deprecated",
+		test: api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &ref}}},
+	}, {
+		name: "deprecated reference in a chain is reported once",
+		test: api.MultiStageTestConfiguration{
+			Pre:  []api.TestStep{{Chain: &chain}},
+			Test: []api.TestStep{{Reference: &deprecatedRef}},
+		},
+		expected: []api.DeprecatedComponent{
+			{Type: "reference", Name: deprecatedRef, Deprecation: api.Deprecation{Message: "do not use", Replacement: ref}},
+		},
+	}, {
+		name: "components excluded by conditions are not reported",
+		test: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileGCP,
+			Pre:            []api.TestStep{{Chain: &chain, When: onAWS}},
+			Test:           []api.TestStep{{Reference: &deprecatedRef, When: onAWS}, {Chain: &deprecatedChain, When: onAWS}},
+		},
+	}, {
+		name: "components included by conditions are reported",
+		test: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileAWS,
+			Test:           []api.TestStep{{Reference: &deprecatedRef, When: onAWS}, {Chain: &deprecatedChain, When: onAWS}},
+		},
+		expected: []api.DeprecatedComponent{
+			{Type: "reference", Name: deprecatedRef, Deprecation: api.Deprecation{Message: "do not use", Replacement: ref}},
+			{Type: "chain", Name: deprecatedChain, Deprecation: api.Deprecation{Message: "chain is going away"}},
+		},
+	}, {
+		name: "deprecated workflow",
+		test: api.MultiStageTestConfiguration{Workflow: &workflow},
+		expected: []api.DeprecatedComponent{
+			{Type: "workflow", Name: workflow, Deprecation: api.Deprecation{Message: "workflow is going away", RemovalDate: "2021-12-31"}},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := NewResolver(refs, chains, workflows, ObserverByName{}, deprecations).Resolve("test", tc.test)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, ret.Deprecations); diff != "" {
+				t.Errorf("unexpected deprecations: %v", diff)
+			}
+		})
+	}
+}
+
+func TestResolveConditions(t *testing.T) {
+	install, upgrade, gather, chain := "install", "upgrade", "gather", "chain"
+	refs := ReferenceByName{
+		install: {As: install, From: "base", Commands: "true"},
+		upgrade: {
+			As: upgrade, From: "base", Commands: "true",
+			Environment: []api.StepParameter{{Name: "UPGRADE"}},
+			When:        &api.StepCondition{Env: []api.EnvCondition{{Name: "UPGRADE", Values: []string{"true"}}}},
+		},
+		gather: {As: gather, From: "base", Commands: "true", When: &api.StepCondition{Outcome: api.StepOutcomeFailure}},
+	}
+	chains := ChainByName{
+		chain: {As: chain, Steps: []api.TestStep{{Reference: &install}, {Reference: &upgrade}}},
+	}
+	literal := func(name string, outcome api.StepOutcome) api.LiteralTestStep {
+		ret := refs[name]
+		ret.When = nil
+		if outcome != "" {
+			ret.When = &api.StepCondition{Outcome: outcome}
+		}
+		return ret
+	}
+	for _, tc := range []struct {
+		name          string
+		test          api.MultiStageTestConfiguration
+		expected      []api.LiteralTestStep
+		expectedError string
+	}{{
+		name: "step not matching the cluster profile is dropped",
+		test: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileGCP,
+			Test: []api.TestStep{
+				{Reference: &install},
+				{Reference: &gather, When: &api.StepCondition{ClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS}}},
+			},
+		},
+		expected: []api.LiteralTestStep{literal(install, "")},
+	}, {
+		name: "step matching the cluster profile keeps its outcome",
+		test: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileAWS,
+			Test: []api.TestStep{
+				{Reference: &install},
+				{Reference: &gather, When: &api.StepCondition{ClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS}}},
+			},
+		},
+		expected: []api.LiteralTestStep{literal(install, ""), literal(gather, api.StepOutcomeFailure)},
+	}, {
+		name: "step not matching the environment is dropped without unused parameters",
+		test: api.MultiStageTestConfiguration{
+			Environment: api.TestEnvironment{"UPGRADE": "false"},
+			Test:        []api.TestStep{{Chain: &chain}},
+		},
+		expected: []api.LiteralTestStep{literal(install, "")},
+	}, {
+		name: "step matching the environment",
+		test: api.MultiStageTestConfiguration{
+			Environment: api.TestEnvironment{"UPGRADE": "true"},
+			Test:        []api.TestStep{{Chain: &chain}},
+		},
+		expected: []api.LiteralTestStep{
+			literal(install, ""),
+			func() api.LiteralTestStep {
+				ret := literal(upgrade, "")
+				value := "true"
+				ret.Environment = []api.StepParameter{{Name: "UPGRADE", Default: &value}}
+				return ret
+			}(),
+		},
+	}, {
+		name: "outcome of a chain applies to all of its steps",
+		test: api.MultiStageTestConfiguration{
+			Environment: api.TestEnvironment{"UPGRADE": "false"},
+			Test:        []api.TestStep{{Chain: &chain, When: &api.StepCondition{Outcome: api.StepOutcomeSuccess}}},
+		},
+		expected: []api.LiteralTestStep{literal(install, api.StepOutcomeSuccess)},
+	}, {
+		name: "conflicting outcomes",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{Reference: &gather, When: &api.StepCondition{Outcome: api.StepOutcomeSuccess}}},
+		},
+		expectedError: `test/test: step/gather: conflicting outcomes "success" and "failure" in conditions`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := NewResolver(refs, chains, WorkflowByName{}, ObserverByName{}, Deprecations{}).Resolve("test", tc.test)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, ret.Test); diff != "" {
+				t.Errorf("unexpected steps: %v", diff)
+			}
+		})
+	}
+}
+
+func TestResolveParallelGroups(t *testing.T) {
+	suiteA, suiteB, setup, suites, grouped := "suite-a", "suite-b", "setup", "suites", "grouped"
+	refs := ReferenceByName{
+		suiteA: {As: suiteA, From: "base", Commands: "true"},
+		suiteB: {As: suiteB, From: "base", Commands: "true"},
+		setup:  {As: setup, From: "base", Commands: "true"},
+	}
+	chains := ChainByName{
+		suites:  {As: suites, Steps: []api.TestStep{{Reference: &suiteA}, {Reference: &suiteB}}},
+		grouped: {As: grouped, Steps: []api.TestStep{{Reference: &suiteA, ParallelGroup: "inner"}, {Reference: &suiteB, ParallelGroup: "inner"}}},
+	}
+	literal := func(name, group string) api.LiteralTestStep {
+		ret := refs[name]
+		ret.ParallelGroup = group
+		return ret
+	}
+	for _, tc := range []struct {
+		name          string
+		test          api.MultiStageTestConfiguration
+		expected      []api.LiteralTestStep
+		expectedError string
+	}{{
+		name: "references in a group",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{
+				{Reference: &setup},
+				{Reference: &suiteA, ParallelGroup: "conformance"},
+				{Reference: &suiteB, ParallelGroup: "conformance"},
+			},
+		},
+		expected: []api.LiteralTestStep{literal(setup, ""), literal(suiteA, "conformance"), literal(suiteB, "conformance")},
+	}, {
+		name: "all steps of a chain join the group",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{Chain: &suites, ParallelGroup: "conformance"}, {Reference: &setup}},
+		},
+		expected: []api.LiteralTestStep{literal(suiteA, "conformance"), literal(suiteB, "conformance"), literal(setup, "")},
+	}, {
+		name: "group defined in a chain",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{Chain: &grouped}},
+		},
+		expected: []api.LiteralTestStep{literal(suiteA, "inner"), literal(suiteB, "inner")},
+	}, {
+		name: "nested groups",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{Chain: &grouped, ParallelGroup: "outer"}},
+		},
+		expectedError: `test/test: chain/grouped: step/suite-a: parallel group "inner" cannot be nested in parallel group "outer"`,
+	}, {
+		name: "steps of a group are not consecutive",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{
+				{Reference: &suiteA, ParallelGroup: "conformance"},
+				{Reference: &setup},
+				{Reference: &suiteB, ParallelGroup: "conformance"},
+			},
+		},
+		expectedError: `test/test: test: steps in parallel group "conformance" must be consecutive, but step suite-b is separated from the rest of the group`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := NewResolver(refs, chains, WorkflowByName{}, ObserverByName{}, Deprecations{}).Resolve("test", tc.test)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, ret.Test); diff != "" {
+				t.Errorf("unexpected steps: %v", diff)
+			}
+		})
+	}
+}
@@ -12,6 +12,18 @@ import (
 type stack struct {
 	records []stackRecord
 	partial bool
+	// clusterProfile is the cluster profile of the test, used to evaluate
+	// step conditions
+	clusterProfile api.ClusterProfile
+	// components records the registry components the test executes, in the
+	// order they are used, if set
+	components *[]component
+}
+
+// component identifies a registry component.
+type component struct {
+	t    Type
+	name string
 }
 
 func stackForChain() stack {
@@ -29,6 +41,25 @@ func stackForTest(name string, env api.TestEnvironment, deps api.TestDependencie
 	return stack{records: []stackRecord{stackRecordForTest("test/"+name, env, deps)}}
 }
 
+// use records that the test executes the component and returns a mark that
+// forget accepts.
+func (s *stack) use(t Type, name string) int {
+	if s.components == nil {
+		return 0
+	}
+	mark := len(*s.components)
+	*s.components = append(*s.components, component{t: t, name: name})
+	return mark
+}
+
+// forget drops the components recorded since the mark, as they are not
+// executed after all.
+func (s *stack) forget(mark int) {
+	if s.components != nil {
+		*s.components = (*s.components)[:mark]
+	}
+}
+
 func (s *stack) push(r stackRecord) {
 	s.records = append(s.records, r)
 }
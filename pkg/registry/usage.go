@@ -0,0 +1,143 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/jobconfig"
+)
+
+func (t Type) String() string {
+	return nodeTypes[t]
+}
+
+// ParseType determines the type of registry element from its name.
+func ParseType(name string) (Type, error) {
+	for t, typeName := range nodeTypes {
+		if typeName == name {
+			return Type(t), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown registry component type %q, expected one of %v", name, nodeTypes)
+}
+
+// Lookup finds the node for the registry element of the type with the name.
+func (g NodeByName) Lookup(t Type, name string) (Node, bool) {
+	var node Node
+	var ok bool
+	switch t {
+	case Workflow:
+		node, ok = g.Workflows[name]
+	case Chain:
+		node, ok = g.Chains[name]
+	case Reference:
+		node, ok = g.References[name]
+	}
+	return node, ok
+}
+
+// TestUsage identifies a test that executes a registry element.
+type TestUsage struct {
+	api.Metadata `json:",inline"`
+	Test         string `json:"test"`
+	// JobName is the name of the Prow job generated for the test.
+	JobName string `json:"job_name"`
+}
+
+// Usage lists everything that executes a registry element, either directly
+// or through other elements.
+type Usage struct {
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	Workflows []string    `json:"workflows,omitempty"`
+	Chains    []string    `json:"chains,omitempty"`
+	Tests     []TestUsage `json:"tests,omitempty"`
+	// TestsByRepo holds the number of tests using the element in each
+	// `org/repo`.
+	TestsByRepo map[string]int `json:"tests_by_repo,omitempty"`
+}
+
+// UsageOf determines the workflows and chains that reach the node, as well as
+// the tests in the ci-operator configurations that would execute it.
+func UsageOf(node Node, workflows WorkflowByName, configs []api.ReleaseBuildConfiguration) Usage {
+	usage := Usage{Name: node.Name(), Type: node.Type().String()}
+	reaching := map[Type]sets.String{
+		Workflow:  sets.NewString(),
+		Chain:     sets.NewString(),
+		Reference: sets.NewString(),
+	}
+	for _, ancestor := range node.Ancestors() {
+		reaching[ancestor.Type()].Insert(ancestor.Name())
+	}
+	usage.Workflows = reaching[Workflow].List()
+	usage.Chains = reaching[Chain].List()
+	// tests using a workflow that reaches the node may override the phase
+	// that does, so we look for the node in the steps of the tests instead
+	reaching[Workflow] = sets.NewString()
+	reaching[node.Type()].Insert(node.Name())
+
+	for _, config := range configs {
+		for _, test := range config.Tests {
+			if test.MultiStageTestConfiguration == nil || !uses(*test.MultiStageTestConfiguration, workflows, reaching) {
+				continue
+			}
+			prefix := jobconfig.PresubmitPrefix
+			switch {
+			case test.Postsubmit:
+				prefix = jobconfig.PostsubmitPrefix
+			case test.Cron != nil || test.Interval != nil:
+				prefix = jobconfig.PeriodicPrefix
+			}
+			usage.Tests = append(usage.Tests, TestUsage{
+				Metadata: config.Metadata,
+				Test:     test.As,
+				JobName:  config.Metadata.JobName(prefix, test.As),
+			})
+			if usage.TestsByRepo == nil {
+				usage.TestsByRepo = map[string]int{}
+			}
+			usage.TestsByRepo[fmt.Sprintf("%s/%s", config.Metadata.Org, config.Metadata.Repo)]++
+		}
+	}
+	sort.Slice(usage.Tests, func(i, j int) bool {
+		return usage.Tests[i].JobName < usage.Tests[j].JobName
+	})
+	return usage
+}
+
+// uses determines if the test executes any of the registry elements. Phases
+// of the workflow that the test overrides are not taken into account, as the
+// test does not execute them.
+func uses(test api.MultiStageTestConfiguration, workflows WorkflowByName, reaching map[Type]sets.String) bool {
+	pre, steps, post := test.Pre, test.Test, test.Post
+	if test.Workflow != nil {
+		if reaching[Workflow].Has(*test.Workflow) {
+			return true
+		}
+		if workflow, ok := workflows[*test.Workflow]; ok {
+			if pre == nil {
+				pre = workflow.Pre
+			}
+			if steps == nil {
+				steps = workflow.Test
+			}
+			if post == nil {
+				post = workflow.Post
+			}
+		}
+	}
+	for _, phase := range [][]api.TestStep{pre, steps, post} {
+		for _, step := range phase {
+			if step.Reference != nil && reaching[Reference].Has(*step.Reference) {
+				return true
+			}
+			if step.Chain != nil && reaching[Chain].Has(*step.Chain) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestUsageOf(t *testing.T) {
+	str := func(s string) *string { return &s }
+	references := ReferenceByName{
+		"install": {As: "install"},
+		"gather":  {As: "gather"},
+	}
+	chains := ChainByName{
+		"install-chain": {As: "install-chain", Steps: []api.TestStep{{Reference: str("install")}}},
+		"outer-chain":   {As: "outer-chain", Steps: []api.TestStep{{Chain: str("install-chain")}}},
+	}
+	workflows := WorkflowByName{
+		"e2e":    {Pre: []api.TestStep{{Chain: str("outer-chain")}}, Post: []api.TestStep{{Reference: str("gather")}}},
+		"gather": {Post: []api.TestStep{{Reference: str("gather")}}},
+	}
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	configs := []api.ReleaseBuildConfiguration{
+		{
+			Metadata: metadata,
+			Tests: []api.TestStepConfiguration{
+				{As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: str("e2e")}},
+				{
+					As: "e2e-overridden",
+					MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+						Workflow: str("e2e"),
+						Pre:      []api.TestStep{{Reference: str("gather")}},
+					},
+				},
+				{As: "unit", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}},
+			},
+		},
+		{
+			Metadata: api.Metadata{Org: "org", Repo: "other", Branch: "master", Variant: "nightly"},
+			Tests: []api.TestStepConfiguration{
+				{
+					As:                          "install",
+					Cron:                        str("@daily"),
+					MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Chain: str("install-chain")}}},
+				},
+			},
+		},
+	}
+	graph, err := NewGraph(references, chains, workflows)
+	if err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name          string
+		componentType Type
+		component     string
+		expected      Usage
+	}{
+		{
+			name:          "reference used through nested chains and a workflow",
+			componentType: Reference,
+			component:     "install",
+			expected: Usage{
+				Name:      "install",
+				Type:      "reference",
+				Workflows: []string{"e2e"},
+				Chains:    []string{"install-chain", "outer-chain"},
+				Tests: []TestUsage{
+					{Metadata: api.Metadata{Org: "org", Repo: "other", Branch: "master", Variant: "nightly"}, Test: "install", JobName: "periodic-ci-org-other-master-nightly-install"},
+					{Metadata: metadata, Test: "e2e", JobName: "pull-ci-org-repo-master-e2e"},
+				},
+				TestsByRepo: map[string]int{"org/repo": 1, "org/other": 1},
+			},
+		},
+		{
+			name:          "reference used directly and in several workflows",
+			componentType: Reference,
+			component:     "gather",
+			expected: Usage{
+				Name:      "gather",
+				Type:      "reference",
+				Workflows: []string{"e2e", "gather"},
+				Tests: []TestUsage{
+					{Metadata: metadata, Test: "e2e", JobName: "pull-ci-org-repo-master-e2e"},
+					{Metadata: metadata, Test: "e2e-overridden", JobName: "pull-ci-org-repo-master-e2e-overridden"},
+				},
+				TestsByRepo: map[string]int{"org/repo": 2},
+			},
+		},
+		{
+			name:          "workflow",
+			componentType: Workflow,
+			component:     "e2e",
+			expected: Usage{
+				Name: "e2e",
+				Type: "workflow",
+				Tests: []TestUsage{
+					{Metadata: metadata, Test: "e2e", JobName: "pull-ci-org-repo-master-e2e"},
+					{Metadata: metadata, Test: "e2e-overridden", JobName: "pull-ci-org-repo-master-e2e-overridden"},
+				},
+				TestsByRepo: map[string]int{"org/repo": 2},
+			},
+		},
+		{
+			name:          "unused workflow",
+			componentType: Workflow,
+			component:     "gather",
+			expected:      Usage{Name: "gather", Type: "workflow"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			node, ok := graph.Lookup(tc.componentType, tc.component)
+			if !ok {
+				t.Fatalf("no %s named %s", tc.componentType, tc.component)
+			}
+			if diff := cmp.Diff(tc.expected, UsageOf(node, workflows, configs), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected usage: %s", diff)
+			}
+		})
+	}
+}
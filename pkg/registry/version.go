@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Snapshot holds everything needed to resolve configurations with one
+// version of the registry.
+type Snapshot struct {
+	References ReferenceByName `json:"references"`
+	Chains     ChainByName     `json:"chains"`
+	Workflows  WorkflowByName  `json:"workflows"`
+	Observers  ObserverByName  `json:"observers,omitempty"`
+	// Deprecations do not change how tests resolve, so they are not
+	// part of the version.
+	Deprecations Deprecations `json:"deprecations,omitempty"`
+}
+
+// Version determines the content hash identifying this version of the
+// registry. The hash covers the references, chains, workflows and observers,
+// so any change that could alter how a test resolves changes the version.
+func (s Snapshot) Version() (string, error) {
+	// maps are marshalled with sorted keys, so the output is stable
+	raw, err := json.Marshal(struct {
+		References ReferenceByName `json:"references"`
+		Chains     ChainByName     `json:"chains"`
+		Workflows  WorkflowByName  `json:"workflows"`
+		Observers  ObserverByName  `json:"observers,omitempty"`
+	}{
+		References: s.References,
+		Chains:     s.Chains,
+		Workflows:  s.Workflows,
+		Observers:  s.Observers,
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not marshal registry: %w", err)
+	}
+	hash := sha256.Sum256(raw)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+var versionPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidateVersion ensures the version looks like one determined by Version.
+func ValidateVersion(version string) error {
+	if !versionPattern.MatchString(version) {
+		return fmt.Errorf("invalid registry version %q: must be a hex-encoded SHA256 hash", version)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestSnapshotVersion(t *testing.T) {
+	snapshot := Snapshot{
+		References: ReferenceByName{"a": {As: "a", Commands: "a"}, "b": {As: "b", Commands: "b"}},
+		Chains:     ChainByName{"chain": {As: "chain"}},
+		Workflows:  WorkflowByName{"workflow": {ClusterProfile: api.ClusterProfileAWS}},
+	}
+	version, err := snapshot.Version()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateVersion(version); err != nil {
+		t.Errorf("version is not valid: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, err := snapshot.Version()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if again != version {
+			t.Fatalf("version is not stable: %s != %s", again, version)
+		}
+	}
+	// deprecations do not alter how tests resolve
+	snapshot.Deprecations = Deprecations{References: map[string]api.Deprecation{"a": {Message: "unused"}}}
+	if withDeprecations, err := snapshot.Version(); err != nil || withDeprecations != version {
+		t.Errorf("expected deprecations not to change the version, got %s, %v", withDeprecations, err)
+	}
+	snapshot.Observers = ObserverByName{"observer": {Name: "observer"}}
+	withObservers, err := snapshot.Version()
+	if err != nil || withObservers == version {
+		t.Errorf("expected observers to change the version, got %s, %v", withObservers, err)
+	}
+	version = withObservers
+	snapshot.References["a"] = api.LiteralTestStep{As: "a", Commands: "changed"}
+	if changed, err := snapshot.Version(); err != nil || changed == version {
+		t.Errorf("expected changed reference to change the version, got %s, %v", changed, err)
+	}
+}
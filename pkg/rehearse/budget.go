@@ -0,0 +1,343 @@
+package rehearse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/github"
+
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/jobconfig"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+const (
+	defaultRehearsalRuntime = time.Hour
+
+	clusterProfileVolumeName   = "cluster-profile"
+	clusterProfileSecretPrefix = "cluster-secrets-"
+
+	// budgetCommentMarker identifies the comment pj-rehearse uses to report
+	// the selection of rehearsals on a pull request
+	budgetCommentMarker = "<!-- pj-rehearse: rehearsal budget -->"
+)
+
+// CostConfig describes how the cost of a rehearsal is estimated. The cost of
+// a rehearsal is its expected runtime in hours, multiplied by one plus the
+// hourly cost of the lease the job holds for its cluster profile, if any.
+type CostConfig struct {
+	// DefaultRuntime is used for jobs with no historical runtime recorded.
+	DefaultRuntime *metav1.Duration `json:"default_runtime,omitempty"`
+	// Runtimes holds the historical runtime of jobs, keyed by job name.
+	Runtimes map[string]metav1.Duration `json:"runtimes,omitempty"`
+	// LeaseCosts holds the hourly cost of a lease, keyed by the cluster
+	// type of the cluster profile that acquires it.
+	LeaseCosts map[string]float64 `json:"lease_costs,omitempty"`
+}
+
+// LoadCostConfig loads the cost configuration from a file.
+func LoadCostConfig(path string) (*CostConfig, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cost configuration: %w", err)
+	}
+	var cfg CostConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cost configuration: %w", err)
+	}
+	for name, runtime := range cfg.Runtimes {
+		if runtime.Duration < 0 {
+			return nil, fmt.Errorf("runtime for job %s cannot be negative", name)
+		}
+	}
+	for clusterType, cost := range cfg.LeaseCosts {
+		if cost < 0 {
+			return nil, fmt.Errorf("lease cost for cluster type %s cannot be negative", clusterType)
+		}
+	}
+	return &cfg, nil
+}
+
+// Estimate returns the expected cost of running the job with the given name.
+func (c *CostConfig) Estimate(name string, job *prowconfig.Presubmit) float64 {
+	runtime := defaultRehearsalRuntime
+	if c.DefaultRuntime != nil {
+		runtime = c.DefaultRuntime.Duration
+	}
+	if recorded, ok := c.Runtimes[name]; ok {
+		runtime = recorded.Duration
+	}
+	return runtime.Hours() * (1 + c.LeaseCosts[clusterTypeFor(job.JobBase)])
+}
+
+// clusterTypeFor returns the cluster type of the cluster profile used by
+// the job, or an empty string when the job does not use one.
+func clusterTypeFor(job prowconfig.JobBase) string {
+	if job.Spec == nil {
+		return ""
+	}
+	for _, volume := range job.Spec.Volumes {
+		if volume.Name != clusterProfileVolumeName || volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil && strings.HasPrefix(source.Secret.Name, clusterProfileSecretPrefix) {
+				return strings.TrimPrefix(source.Secret.Name, clusterProfileSecretPrefix)
+			}
+		}
+	}
+	for _, container := range job.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == clusterTypeEnvName {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
+
+// Coverage maps the names of candidate jobs to the changes that rehearsing
+// the job would exercise.
+type Coverage map[string]sets.String
+
+// Add records that the job exercises the change.
+func (c Coverage) Add(job, change string) {
+	if _, ok := c[job]; !ok {
+		c[job] = sets.NewString()
+	}
+	c[job].Insert(change)
+}
+
+// AddPresubmits records that each of the presubmits exercises the change
+// returned by the function.
+func (c Coverage) AddPresubmits(presubmits config.Presubmits, change func(job prowconfig.Presubmit) string) {
+	for _, jobs := range presubmits {
+		for _, job := range jobs {
+			c.Add(job.Name, change(job))
+		}
+	}
+}
+
+// AddPeriodics records that each of the periodics exercises the change
+// returned by the function.
+func (c Coverage) AddPeriodics(periodics config.Periodics, change func(job prowconfig.Periodic) string) {
+	for _, job := range periodics {
+		c.Add(job.Name, change(job))
+	}
+}
+
+// AddConfigMaps records which of the changed templates or cluster profiles,
+// identified by the names of their production ConfigMaps, the jobs use. As
+// the same template behaves differently on each cluster type, the cluster
+// type of the job is a part of the change.
+func (c Coverage) AddConfigMaps(jobs []prowconfig.JobBase, names sets.String, kind string) {
+	for _, job := range jobs {
+		for _, name := range names.List() {
+			if !UsesConfigMap(job, name) {
+				continue
+			}
+			change := fmt.Sprintf("%s `%s`", kind, name)
+			if clusterType := clusterTypeFor(job); clusterType != "" {
+				change += fmt.Sprintf(" on `%s`", clusterType)
+			}
+			c.Add(job.Name, change)
+		}
+	}
+}
+
+// AddRegistryNodes records which of the changed registry nodes or their
+// ancestors the candidate jobs use.
+func (c Coverage) AddRegistryNodes(changed []registry.Node, ciopConfigs config.DataByFilename, candidates sets.String) {
+	nodes := getAffectedNodes(changed)
+	for _, cfg := range ciopConfigs {
+		for _, test := range cfg.Configuration.Tests {
+			if test.MultiStageTestConfiguration == nil || test.Postsubmit {
+				continue
+			}
+			prefix := jobconfig.PresubmitPrefix
+			if test.Cron != nil || test.Interval != nil {
+				prefix = jobconfig.PeriodicPrefix
+			}
+			name := cfg.Info.JobName(prefix, test.As)
+			if !candidates.Has(name) {
+				continue
+			}
+			for _, node := range nodes {
+				if testUsesNode(test.MultiStageTestConfiguration, node) {
+					c.Add(name, fmt.Sprintf("registry %s `%s`", registry.FieldsForNode(node)["node-type"], node.Name()))
+				}
+			}
+		}
+	}
+}
+
+// Decision records whether a rehearsal was selected within the budget.
+type Decision struct {
+	// Job is the name of the rehearsed job
+	Job string
+	// Cost is the estimated cost of the rehearsal
+	Cost float64
+	// Covers lists the changes exercised by the rehearsal
+	Covers []string
+	// Selected is true if the rehearsal will run
+	Selected bool
+	// Reason explains why the rehearsal was not selected
+	Reason string
+}
+
+type budgetCandidate struct {
+	job     *prowconfig.Presubmit
+	name    string
+	cost    float64
+	changes sets.String
+}
+
+// SelectWithinBudget selects rehearsals so that as many changes as possible
+// are exercised within the budget and the limit on the number of jobs. The
+// selection is greedy: the rehearsal exercising the most changes that are
+// not yet covered per unit of cost is picked first. Rehearsals that would
+// not exercise any change not covered by an already selected rehearsal are
+// not run.
+func SelectWithinBudget(rehearsals []*prowconfig.Presubmit, prNumber int, coverage Coverage, costs *CostConfig, budget float64, limit int) ([]*prowconfig.Presubmit, []Decision) {
+	var candidates []*budgetCandidate
+	for _, job := range rehearsals {
+		name := strings.TrimPrefix(job.Name, fmt.Sprintf("rehearse-%d-", prNumber))
+		changes := coverage[name]
+		if changes.Len() == 0 {
+			// we always want to rehearse jobs even when we cannot tell what
+			// they exercise, so they cover themselves
+			changes = sets.NewString(fmt.Sprintf("job `%s`", name))
+		}
+		candidates = append(candidates, &budgetCandidate{job: job, name: name, cost: costs.Estimate(name, job), changes: changes})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].name < candidates[j].name })
+
+	var selected []*prowconfig.Presubmit
+	chosen := sets.NewString()
+	covered := sets.NewString()
+	spent := 0.0
+	for len(selected) < limit {
+		var best *budgetCandidate
+		var bestUncovered int
+		for _, candidate := range candidates {
+			uncovered := candidate.changes.Difference(covered).Len()
+			if chosen.Has(candidate.name) || uncovered == 0 || spent+candidate.cost > budget {
+				continue
+			}
+			// compare the number of uncovered changes per unit of cost
+			// without dividing, as the cost may be zero
+			if best == nil {
+				best, bestUncovered = candidate, uncovered
+				continue
+			}
+			score, bestScore := float64(uncovered)*best.cost, float64(bestUncovered)*candidate.cost
+			if score != bestScore {
+				if score > bestScore {
+					best, bestUncovered = candidate, uncovered
+				}
+				continue
+			}
+			// on a tie, which always happens when both are free, prefer
+			// the rehearsal exercising more changes, then the cheaper one
+			if uncovered > bestUncovered || (uncovered == bestUncovered && candidate.cost < best.cost) {
+				best, bestUncovered = candidate, uncovered
+			}
+		}
+		if best == nil {
+			break
+		}
+		chosen.Insert(best.name)
+		selected = append(selected, best.job)
+		covered.Insert(best.changes.UnsortedList()...)
+		spent += best.cost
+	}
+
+	var ret []Decision
+	for _, candidate := range candidates {
+		decision := Decision{Job: candidate.name, Cost: candidate.cost, Covers: candidate.changes.List()}
+		switch {
+		case chosen.Has(candidate.name):
+			decision.Selected = true
+		case candidate.changes.Difference(covered).Len() == 0:
+			decision.Reason = "all changes it exercises are covered by selected rehearsals"
+		case len(selected) >= limit:
+			decision.Reason = fmt.Sprintf("the limit of %d rehearsals was reached", limit)
+		default:
+			decision.Reason = fmt.Sprintf("its cost would exceed the remaining budget of %.2f", budget-spent)
+		}
+		ret = append(ret, decision)
+	}
+	return selected, ret
+}
+
+// FormatBudgetComment formats the decisions made by SelectWithinBudget into
+// a pull request comment.
+func FormatBudgetComment(decisions []Decision, budget float64) string {
+	var rehearsed, skipped []Decision
+	spent := 0.0
+	for _, decision := range decisions {
+		if decision.Selected {
+			rehearsed = append(rehearsed, decision)
+			spent += decision.Cost
+		} else {
+			skipped = append(skipped, decision)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(budgetCommentMarker + "\n")
+	fmt.Fprintf(&b, "pj-rehearse selected %d of %d rehearsals, with an estimated cost of %.2f within the budget of %.2f.\n", len(rehearsed), len(decisions), spent, budget)
+	if len(rehearsed) > 0 {
+		b.WriteString("\nRehearsed jobs:\n\n| Job | Cost | Exercises |\n| --- | --- | --- |\n")
+		for _, decision := range rehearsed {
+			fmt.Fprintf(&b, "| `%s` | %.2f | %s |\n", decision.Job, decision.Cost, strings.Join(decision.Covers, ", "))
+		}
+	}
+	if len(skipped) > 0 {
+		b.WriteString("\nJobs that were not rehearsed:\n\n| Job | Cost | Exercises | Reason |\n| --- | --- | --- | --- |\n")
+		for _, decision := range skipped {
+			fmt.Fprintf(&b, "| `%s` | %.2f | %s | %s |\n", decision.Job, decision.Cost, strings.Join(decision.Covers, ", "), decision.Reason)
+		}
+	}
+	return b.String()
+}
+
+type commentClient interface {
+	BotUserChecker() (func(candidate string) bool, error)
+	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	CreateComment(org, repo string, number int, comment string) error
+	EditComment(org, repo string, id int, comment string) error
+}
+
+// ReportBudget posts the comment to the pull request, replacing the content
+// of the comment posted by an earlier run of pj-rehearse, if any.
+func ReportBudget(client commentClient, org, repo string, number int, comment string) error {
+	isBot, err := client.BotUserChecker()
+	if err != nil {
+		return fmt.Errorf("failed to get the bot user: %w", err)
+	}
+	comments, err := client.ListIssueComments(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to list comments: %w", err)
+	}
+	for _, existing := range comments {
+		if isBot(existing.User.Login) && strings.HasPrefix(existing.Body, budgetCommentMarker) {
+			if err := client.EditComment(org, repo, existing.ID, comment); err != nil {
+				return fmt.Errorf("failed to edit comment: %w", err)
+			}
+			return nil
+		}
+	}
+	if err := client.CreateComment(org, repo, number, comment); err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package rehearse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/github"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func budgetJob(name, clusterType string) *prowconfig.Presubmit {
+	job := &prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: name, Spec: &v1.PodSpec{Containers: []v1.Container{{}}}}}
+	if clusterType != "" {
+		job.Spec.Volumes = []v1.Volume{{
+			Name: "cluster-profile",
+			VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{{
+				Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "cluster-secrets-" + clusterType}},
+			}}}},
+		}}
+	}
+	return job
+}
+
+func TestEstimate(t *testing.T) {
+	costs := &CostConfig{
+		DefaultRuntime: &metav1.Duration{Duration: 2 * time.Hour},
+		Runtimes:       map[string]metav1.Duration{"known": {Duration: 30 * time.Minute}},
+		LeaseCosts:     map[string]float64{"aws": 3},
+	}
+	testCases := []struct {
+		name     string
+		job      *prowconfig.Presubmit
+		expected float64
+	}{
+		{
+			name:     "no history and no lease uses the default runtime",
+			job:      budgetJob("unknown", ""),
+			expected: 2,
+		},
+		{
+			name:     "historical runtime is used",
+			job:      budgetJob("known", ""),
+			expected: 0.5,
+		},
+		{
+			name:     "lease cost is added to the runtime",
+			job:      budgetJob("known", "aws"),
+			expected: 2,
+		},
+		{
+			name:     "cluster type without a lease cost",
+			job:      budgetJob("known", "gcp"),
+			expected: 0.5,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := costs.Estimate(tc.job.Name, tc.job); actual != tc.expected {
+				t.Errorf("expected cost %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCoverageAddConfigMaps(t *testing.T) {
+	withConfigMap := func(name, clusterType, cm string) prowconfig.JobBase {
+		job := budgetJob(name, "").JobBase
+		job.Spec.Containers[0].Env = []v1.EnvVar{{Name: clusterTypeEnvName, Value: clusterType}}
+		job.Spec.Volumes = []v1.Volume{{Name: "job-definition", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: cm}}}}}
+		return job
+	}
+	coverage := Coverage{}
+	coverage.AddConfigMaps([]prowconfig.JobBase{
+		withConfigMap("aws-job", "aws", "prow-job-cluster-launch-installer-e2e"),
+		withConfigMap("gcp-job", "gcp", "prow-job-cluster-launch-installer-e2e"),
+		withConfigMap("other-job", "gcp", "prow-job-other"),
+	}, sets.NewString("prow-job-cluster-launch-installer-e2e"), "template")
+	expected := Coverage{
+		"aws-job": sets.NewString("template `prow-job-cluster-launch-installer-e2e` on `aws`"),
+		"gcp-job": sets.NewString("template `prow-job-cluster-launch-installer-e2e` on `gcp`"),
+	}
+	if diff := cmp.Diff(expected, coverage); diff != "" {
+		t.Errorf("unexpected coverage: %s", diff)
+	}
+}
+
+func TestSelectWithinBudget(t *testing.T) {
+	costs := &CostConfig{
+		Runtimes: map[string]metav1.Duration{
+			"cheap":     {Duration: 30 * time.Minute},
+			"expensive": {Duration: 4 * time.Hour},
+			"free-a":    {Duration: 0},
+			"free-b":    {Duration: 0},
+		},
+	}
+	testCases := []struct {
+		name      string
+		jobs      []*prowconfig.Presubmit
+		coverage  Coverage
+		budget    float64
+		limit     int
+		selected  []string
+		decisions []Decision
+	}{
+		{
+			name:     "everything fits",
+			jobs:     []*prowconfig.Presubmit{budgetJob("rehearse-1-cheap", ""), budgetJob("rehearse-1-default", "")},
+			coverage: Coverage{},
+			budget:   10,
+			limit:    10,
+			selected: []string{"rehearse-1-cheap", "rehearse-1-default"},
+			decisions: []Decision{
+				{Job: "cheap", Cost: 0.5, Covers: []string{"job `cheap`"}, Selected: true},
+				{Job: "default", Cost: 1, Covers: []string{"job `default`"}, Selected: true},
+			},
+		},
+		{
+			name: "cheaper job covering the same change is preferred",
+			jobs: []*prowconfig.Presubmit{budgetJob("rehearse-1-expensive", ""), budgetJob("rehearse-1-cheap", "")},
+			coverage: Coverage{
+				"cheap":     sets.NewString("registry reference `step`"),
+				"expensive": sets.NewString("registry reference `step`"),
+			},
+			budget:   10,
+			limit:    10,
+			selected: []string{"rehearse-1-cheap"},
+			decisions: []Decision{
+				{Job: "cheap", Cost: 0.5, Covers: []string{"registry reference `step`"}, Selected: true},
+				{Job: "expensive", Cost: 4, Covers: []string{"registry reference `step`"}, Reason: "all changes it exercises are covered by selected rehearsals"},
+			},
+		},
+		{
+			name: "job covering more changes per cost is preferred",
+			jobs: []*prowconfig.Presubmit{budgetJob("rehearse-1-expensive", ""), budgetJob("rehearse-1-cheap", ""), budgetJob("rehearse-1-default", "")},
+			coverage: Coverage{
+				"cheap":     sets.NewString("registry reference `a`"),
+				"default":   sets.NewString("registry reference `a`", "registry reference `b`", "registry chain `c`"),
+				"expensive": sets.NewString("registry reference `a`", "registry reference `b`", "registry chain `c`", "registry workflow `d`"),
+			},
+			budget:   5,
+			limit:    10,
+			selected: []string{"rehearse-1-default", "rehearse-1-expensive"},
+			decisions: []Decision{
+				{Job: "cheap", Cost: 0.5, Covers: []string{"registry reference `a`"}, Reason: "all changes it exercises are covered by selected rehearsals"},
+				{Job: "default", Cost: 1, Covers: []string{"registry chain `c`", "registry reference `a`", "registry reference `b`"}, Selected: true},
+				{Job: "expensive", Cost: 4, Covers: []string{"registry chain `c`", "registry reference `a`", "registry reference `b`", "registry workflow `d`"}, Selected: true},
+			},
+		},
+		{
+			name: "free job covering more changes is preferred",
+			jobs: []*prowconfig.Presubmit{budgetJob("rehearse-1-free-a", ""), budgetJob("rehearse-1-free-b", "")},
+			coverage: Coverage{
+				"free-a": sets.NewString("registry reference `a`"),
+				"free-b": sets.NewString("registry reference `a`", "registry reference `b`"),
+			},
+			budget:   10,
+			limit:    1,
+			selected: []string{"rehearse-1-free-b"},
+			decisions: []Decision{
+				{Job: "free-a", Cost: 0, Covers: []string{"registry reference `a`"}, Reason: "all changes it exercises are covered by selected rehearsals"},
+				{Job: "free-b", Cost: 0, Covers: []string{"registry reference `a`", "registry reference `b`"}, Selected: true},
+			},
+		},
+		{
+			name:     "budget is exhausted",
+			jobs:     []*prowconfig.Presubmit{budgetJob("rehearse-1-expensive", ""), budgetJob("rehearse-1-cheap", "")},
+			coverage: Coverage{},
+			budget:   2,
+			limit:    10,
+			selected: []string{"rehearse-1-cheap"},
+			decisions: []Decision{
+				{Job: "cheap", Cost: 0.5, Covers: []string{"job `cheap`"}, Selected: true},
+				{Job: "expensive", Cost: 4, Covers: []string{"job `expensive`"}, Reason: "its cost would exceed the remaining budget of 1.50"},
+			},
+		},
+		{
+			name:     "limit is reached",
+			jobs:     []*prowconfig.Presubmit{budgetJob("rehearse-1-expensive", ""), budgetJob("rehearse-1-cheap", "")},
+			coverage: Coverage{},
+			budget:   10,
+			limit:    1,
+			selected: []string{"rehearse-1-cheap"},
+			decisions: []Decision{
+				{Job: "cheap", Cost: 0.5, Covers: []string{"job `cheap`"}, Selected: true},
+				{Job: "expensive", Cost: 4, Covers: []string{"job `expensive`"}, Reason: "the limit of 1 rehearsals was reached"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, decisions := SelectWithinBudget(tc.jobs, 1, tc.coverage, costs, tc.budget, tc.limit)
+			var names []string
+			for _, job := range selected {
+				names = append(names, job.Name)
+			}
+			if diff := cmp.Diff(tc.selected, names); diff != "" {
+				t.Errorf("unexpected selected jobs: %s", diff)
+			}
+			if diff := cmp.Diff(tc.decisions, decisions); diff != "" {
+				t.Errorf("unexpected decisions: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatBudgetComment(t *testing.T) {
+	decisions := []Decision{
+		{Job: "cheap", Cost: 0.5, Covers: []string{"registry reference `a`"}, Reason: "all changes it exercises are covered by selected rehearsals"},
+		{Job: "default", Cost: 1, Covers: []string{"registry chain `c`", "registry reference `a`"}, Selected: true},
+		{Job: "expensive", Cost: 4, Covers: []string{"job `expensive`"}, Reason: "its cost would exceed the remaining budget of 1.00"},
+	}
+	testhelper.CompareWithFixture(t, FormatBudgetComment(decisions, 2))
+}
+
+type fakeCommentClient struct {
+	comments []github.IssueComment
+	created  []string
+	edited   map[int]string
+}
+
+func (c *fakeCommentClient) BotUserChecker() (func(candidate string) bool, error) {
+	return func(candidate string) bool { return candidate == "bot" }, nil
+}
+
+func (c *fakeCommentClient) ListIssueComments(_, _ string, _ int) ([]github.IssueComment, error) {
+	return c.comments, nil
+}
+
+func (c *fakeCommentClient) CreateComment(_, _ string, _ int, comment string) error {
+	c.created = append(c.created, comment)
+	return nil
+}
+
+func (c *fakeCommentClient) EditComment(_, _ string, id int, comment string) error {
+	if c.edited == nil {
+		c.edited = map[int]string{}
+	}
+	c.edited[id] = comment
+	return nil
+}
+
+func TestReportBudget(t *testing.T) {
+	comment := budgetCommentMarker + "\nnew"
+	testCases := []struct {
+		name            string
+		comments        []github.IssueComment
+		expectedCreated []string
+		expectedEdited  map[int]string
+	}{
+		{
+			name:            "no previous report",
+			comments:        []github.IssueComment{{ID: 1, User: github.User{Login: "bot"}, Body: "/test all"}},
+			expectedCreated: []string{comment},
+		},
+		{
+			name: "previous report is replaced",
+			comments: []github.IssueComment{
+				{ID: 1, User: github.User{Login: "human"}, Body: budgetCommentMarker + "\nquoted"},
+				{ID: 2, User: github.User{Login: "bot"}, Body: budgetCommentMarker + "\nold"},
+			},
+			expectedEdited: map[int]string{2: comment},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeCommentClient{comments: tc.comments}
+			if err := ReportBudget(client, "openshift", "release", 1, comment); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedCreated, client.created); diff != "" {
+				t.Errorf("unexpected created comments: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedEdited, client.edited); diff != "" {
+				t.Errorf("unexpected edited comments: %s", diff)
+			}
+		})
+	}
+}
@@ -588,25 +588,9 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 				continue
 			}
 
-			// TODO: Handle workflows with overridden logFields.
-			// Workflows can have overridden logFields and thus may have overridden the field that made the workflow an ancestor.
-			// This should be handled to reduce the number of rehearsals being done, but requires much more information than
-			// the graph alone provides.
-			if node.Type() == registry.Workflow {
-				if test.MultiStageTestConfiguration.Workflow != nil && node.Name() == *test.MultiStageTestConfiguration.Workflow {
-					selectJob()
-					return selectedPresubmits, selectedPeriodics
-				}
-				continue
-			}
-			testSteps := append(test.MultiStageTestConfiguration.Pre, append(test.MultiStageTestConfiguration.Test, test.MultiStageTestConfiguration.Post...)...)
-			for _, testStep := range testSteps {
-				hasRef := testStep.Reference != nil && node.Type() == registry.Reference && node.Name() == *testStep.Reference
-				hasChain := testStep.Chain != nil && node.Type() == registry.Chain && node.Name() == *testStep.Chain
-				if hasRef || hasChain {
-					selectJob()
-					return selectedPresubmits, selectedPeriodics
-				}
+			if testUsesNode(test.MultiStageTestConfiguration, node) {
+				selectJob()
+				return selectedPresubmits, selectedPeriodics
 			}
 		}
 	}
@@ -614,6 +598,26 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 	return selectedPresubmits, selectedPeriodics
 }
 
+// testUsesNode determines whether a multi-stage test directly uses a registry node.
+func testUsesNode(test *api.MultiStageTestConfiguration, node registry.Node) bool {
+	// TODO: Handle workflows with overridden logFields.
+	// Workflows can have overridden logFields and thus may have overridden the field that made the workflow an ancestor.
+	// This should be handled to reduce the number of rehearsals being done, but requires much more information than
+	// the graph alone provides.
+	if node.Type() == registry.Workflow {
+		return test.Workflow != nil && node.Name() == *test.Workflow
+	}
+	testSteps := append(test.Pre, append(test.Test, test.Post...)...)
+	for _, testStep := range testSteps {
+		hasRef := testStep.Reference != nil && node.Type() == registry.Reference && node.Name() == *testStep.Reference
+		hasChain := testStep.Chain != nil && node.Type() == registry.Chain && node.Name() == *testStep.Chain
+		if hasRef || hasChain {
+			return true
+		}
+	}
+	return false
+}
+
 // getAffectedNodes returns a sorted list of all nodes affected by a seed list
 // of changed nodes. Affected node is either a directly changed node or any of
 // its ancestors. Each node is present at most once.
@@ -287,11 +287,11 @@ func TestInlineCiopConfig(t *testing.T) {
 		},
 	}
 
-	references, chains, workflows, _, _, observers, err := load.Registry(testingRegistry, false)
+	references, chains, workflows, _, _, observers, deprecations, err := load.Registry(testingRegistry, false)
 	if err != nil {
 		t.Fatalf("Failed to read registry: %v", err)
 	}
-	resolver := registry.NewResolver(references, chains, workflows, observers)
+	resolver := registry.NewResolver(references, chains, workflows, observers, deprecations)
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			testLoggers := Loggers{logrus.New(), logrus.New()}
@@ -548,11 +548,11 @@ func TestExecuteJobsErrors(t *testing.T) {
 		failToCreate: sets.NewString("rehearse-123-job2"),
 	}}
 
-	references, chains, workflows, _, _, observers, err := load.Registry(testingRegistry, false)
+	references, chains, workflows, _, _, observers, deprecations, err := load.Registry(testingRegistry, false)
 	if err != nil {
 		t.Fatalf("Failed to read registry: %v", err)
 	}
-	resolver := registry.NewResolver(references, chains, workflows, observers)
+	resolver := registry.NewResolver(references, chains, workflows, observers, deprecations)
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			testLoggers := Loggers{logrus.New(), logrus.New()}
@@ -618,11 +618,11 @@ func TestExecuteJobsUnsuccessful(t *testing.T) {
 		},
 	}}
 
-	references, chains, workflows, _, _, observers, err := load.Registry(testingRegistry, false)
+	references, chains, workflows, _, _, observers, deprecations, err := load.Registry(testingRegistry, false)
 	if err != nil {
 		t.Fatalf("Failed to read registry: %v", err)
 	}
-	resolver := registry.NewResolver(references, chains, workflows, observers)
+	resolver := registry.NewResolver(references, chains, workflows, observers, deprecations)
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			testLoggers := Loggers{logrus.New(), logrus.New()}
@@ -735,11 +735,11 @@ func TestExecuteJobsPositive(t *testing.T) {
 		},
 	}
 
-	references, chains, workflows, _, _, observers, err := load.Registry(testingRegistry, false)
+	references, chains, workflows, _, _, observers, deprecations, err := load.Registry(testingRegistry, false)
 	if err != nil {
 		t.Fatalf("Failed to read registry: %v", err)
 	}
-	resolver := registry.NewResolver(references, chains, workflows, observers)
+	resolver := registry.NewResolver(references, chains, workflows, observers, deprecations)
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			testLoggers := Loggers{logrus.New(), logrus.New()}
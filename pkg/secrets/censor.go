@@ -1,8 +1,14 @@
 package secrets
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -27,12 +33,123 @@ func NewDynamicCensor() DynamicCensor {
 	}
 }
 
-// AddSecrets adds the content of one or more secrets to the censor list.
+// AddSecrets adds the content of one or more secrets to the censor list,
+// together with the forms derived from them by common encodings.
 func (c *DynamicCensor) AddSecrets(s ...string) {
 	c.Lock()
 	defer c.Unlock()
-	c.secrets.Insert(s...)
-	c.ReloadingCensorer.Refresh(c.secrets.List()...)
+	for _, secret := range s {
+		c.secrets.Insert(secret)
+		c.secrets.Insert(derivedForms(secret)...)
+	}
+	// the censorer prefers the secret passed first when several match at the
+	// same position, so pass the longest first to censor derived forms that
+	// contain others in full
+	secrets := c.secrets.List()
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	c.ReloadingCensorer.Refresh(secrets...)
+}
+
+// minimumDerivedLength is the length of the shortest derived form of a secret
+// that is censored. Shorter forms would match unrelated content too often.
+const minimumDerivedLength = 8
+
+// derivedForms returns the encodings of a secret that commonly end up in
+// output: base64 in both alphabets, also when the secret is a part of a larger
+// encoded blob, URL escaping and JSON string escaping.
+func derivedForms(secret string) []string {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil
+	}
+	forms := sets.NewString()
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		forms.Insert(encoding.EncodeToString([]byte(secret)))
+		// a secret embedded in a larger blob, like the user:password pair in
+		// a docker auth, is encoded differently depending on its offset
+		for offset := 0; offset < 3; offset++ {
+			forms.Insert(base64Core(encoding, secret, offset))
+		}
+	}
+	forms.Insert(url.QueryEscape(secret), url.PathEscape(secret))
+	for _, escapeHTML := range []bool{true, false} {
+		buf := &bytes.Buffer{}
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(escapeHTML)
+		if err := encoder.Encode(secret); err == nil {
+			// the encoder quotes the string and terminates it with a newline
+			quoted := strings.TrimSuffix(buf.String(), "\n")
+			forms.Insert(quoted[1 : len(quoted)-1])
+		}
+	}
+	forms.Delete(secret)
+	var result []string
+	for _, form := range forms.List() {
+		if len(form) >= minimumDerivedLength {
+			result = append(result, form)
+		}
+	}
+	return result
+}
+
+// base64Core returns the part of the base64 encoding of a blob that depends
+// only on the secret, when the secret starts at the given offset in the blob.
+func base64Core(encoding *base64.Encoding, secret string, offset int) string {
+	encoded := encoding.EncodeToString(append(make([]byte, offset), secret...))
+	// every character encodes six bits, so the characters that only encode
+	// bits of the secret start after the offset and end before its last
+	// partially encoded bits
+	start, end := (8*offset+5)/6, 8*(offset+len(secret))/6
+	if end <= start {
+		return ""
+	}
+	return encoded[start:end]
+}
+
+// censoringWriter censors the content written to it before passing it to the
+// upstream writer. The end of the content is held back until the next write
+// or until the writer is closed, so that secrets that span writes are
+// censored as well.
+type censoringWriter struct {
+	upstream io.Writer
+	censor   *DynamicCensor
+	buffer   []byte
+}
+
+// NewCensoringWriter returns a writer that censors secrets in the content
+// written to it. Closing the writer flushes the held back content; it does not
+// close the upstream writer.
+func NewCensoringWriter(upstream io.Writer, censor *DynamicCensor) io.WriteCloser {
+	return &censoringWriter{upstream: upstream, censor: censor}
+}
+
+func (w *censoringWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	w.censor.Censor(&w.buffer)
+	// a secret that starts in the flushed content ends in the buffer, so
+	// it was censored in full
+	held := w.censor.LargestSecret() - 1
+	if held < 0 {
+		held = 0
+	}
+	if len(w.buffer) <= held {
+		return len(p), nil
+	}
+	flushed := len(w.buffer) - held
+	if _, err := w.upstream.Write(w.buffer[:flushed]); err != nil {
+		return 0, err
+	}
+	w.buffer = append(w.buffer[:0], w.buffer[flushed:]...)
+	return len(p), nil
+}
+
+func (w *censoringWriter) Close() error {
+	w.censor.Censor(&w.buffer)
+	_, err := w.upstream.Write(w.buffer)
+	w.buffer = nil
+	return err
 }
 
 // ReadFromEnv loads an environment variable and adds it to the censor list.
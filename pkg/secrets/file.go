@@ -0,0 +1,167 @@
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/vaultclient"
+)
+
+const fileStoreExtension = ".yaml"
+
+type fileStore struct {
+	root string
+	// sops runs the sops binary with the given arguments and returns its
+	// output. It is nil when the files are stored in plain text.
+	sops func(args ...string) ([]byte, error)
+}
+
+// NewFileStore returns a store that keeps every item in a YAML file under
+// root, at the path of the item with a .yaml extension. When useSOPS is set,
+// the files are encrypted and decrypted with the sops binary, which must be
+// configured through a .sops.yaml file or its environment.
+func NewFileStore(root string, useSOPS bool) VaultClient {
+	store := &fileStore{root: root}
+	if useSOPS {
+		store.sops = runSOPS
+	}
+	return store
+}
+
+func runSOPS(args ...string) ([]byte, error) {
+	cmd := exec.Command("sops", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		var stderr []byte
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = exitErr.Stderr
+		}
+		return nil, fmt.Errorf("sops %s failed: %w: %s", strings.Join(args, " "), err, stderr)
+	}
+	return out, nil
+}
+
+// fileFor returns the file that holds the item at path, refusing paths that
+// would escape the root of the store.
+func (s *fileStore) fileFor(path string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash("/" + path))
+	if cleaned == string(filepath.Separator) || strings.Trim(path, "/") != filepath.ToSlash(cleaned[1:]) {
+		return "", fmt.Errorf("invalid item path %q", path)
+	}
+	return filepath.Join(s.root, cleaned) + fileStoreExtension, nil
+}
+
+func (s *fileStore) GetKV(path string) (*vaultclient.KVData, error) {
+	file, err := s.fileFor(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, &notFoundError{path: path}
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", file, err)
+	}
+	var raw []byte
+	if s.sops != nil {
+		raw, err = s.sops("--decrypt", "--input-type", "yaml", "--output-type", "yaml", file)
+	} else {
+		raw, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	data := map[string]string{}
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", file, err)
+	}
+	return &vaultclient.KVData{
+		Data:     data,
+		Metadata: vaultclient.KVMetadata{CreatedTime: info.ModTime()},
+	}, nil
+}
+
+func (s *fileStore) ListKVRecursively(path string) ([]string, error) {
+	dir := filepath.Join(s.root, filepath.Clean(filepath.FromSlash("/"+path)))
+	var result []string
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && file == dir {
+				return nil
+			}
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() && file != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || filepath.Ext(file) != fileStoreExtension {
+			return nil
+		}
+		relative, err := filepath.Rel(dir, strings.TrimSuffix(file, fileStoreExtension))
+		if err != nil {
+			return err
+		}
+		result = append(result, strings.TrimSuffix(path, "/")+"/"+filepath.ToSlash(relative))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items under %s: %w", dir, err)
+	}
+	return result, nil
+}
+
+func (s *fileStore) UpsertKV(path string, data map[string]string) error {
+	current, err := s.GetKV(path)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if current != nil && reflect.DeepEqual(current.Data, data) {
+		return nil
+	}
+	file, err := s.fileFor(path)
+	if err != nil {
+		return err
+	}
+	raw, err := yaml.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal item %s: %w", path, err)
+	}
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	// Write to a hidden file next to the item first, so that readers never
+	// observe a partially written or not yet encrypted item.
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(file)+".*"+fileStoreExtension)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file in %s: %w", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmp.Name(), err)
+	}
+	if s.sops != nil {
+		if _, err := s.sops("--encrypt", "--in-place", "--input-type", "yaml", "--output-type", "yaml", tmp.Name()); err != nil {
+			return fmt.Errorf("failed to encrypt item %s: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("failed to write item %s: %w", path, err)
+	}
+	return nil
+}
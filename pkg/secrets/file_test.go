@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/ci-tools/pkg/api/vault"
+)
+
+// fakeSOPS "encrypts" files by base64-encoding them.
+func fakeSOPS(args ...string) ([]byte, error) {
+	file := args[len(args)-1]
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	switch args[0] {
+	case "--decrypt":
+		return base64.StdEncoding.DecodeString(string(raw))
+	case "--encrypt":
+		return nil, ioutil.WriteFile(file, []byte(base64.StdEncoding.EncodeToString(raw)), 0600)
+	}
+	return nil, fmt.Errorf("unexpected arguments: %v", args)
+}
+
+func TestFileStore(t *testing.T) {
+	for _, useSOPS := range []bool{false, true} {
+		t.Run(fmt.Sprintf("sops=%t", useSOPS), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "file-store")
+			if err != nil {
+				t.Fatalf("failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			store := &fileStore{root: dir}
+			if useSOPS {
+				store.sops = fakeSOPS
+			}
+			censor := NewDynamicCensor()
+			client := NewVaultClient(store, "kv", &censor)
+
+			if has, err := client.HasItem("team/item"); err != nil || has {
+				t.Fatalf("expected no item before writing, got %t, %v", has, err)
+			}
+			if err := client.SetFieldOnItem("team/item", "token", []byte("super-secret")); err != nil {
+				t.Fatalf("failed to set field: %v", err)
+			}
+			if err := client.UpdateNotesOnItem("team/item", "some notes"); err != nil {
+				t.Fatalf("failed to set notes: %v", err)
+			}
+			if err := client.SetFieldOnItem("synced", "secretsync/target-namespace", []byte("ns")); err != nil {
+				t.Fatalf("failed to set field: %v", err)
+			}
+			if err := client.SetFieldOnItem("synced", "secretsync/target-name", []byte("name")); err != nil {
+				t.Fatalf("failed to set field: %v", err)
+			}
+
+			if has, err := client.HasItem("team/item"); err != nil || !has {
+				t.Fatalf("expected item after writing, got %t, %v", has, err)
+			}
+			value, err := client.GetFieldOnItem("team/item", "token")
+			if err != nil {
+				t.Fatalf("failed to get field: %v", err)
+			}
+			if string(value) != "super-secret" {
+				t.Errorf("expected value %q, got %q", "super-secret", string(value))
+			}
+			raw, err := ioutil.ReadFile(filepath.Join(dir, "kv", "team", "item.yaml"))
+			if err != nil {
+				t.Fatalf("failed to read item file: %v", err)
+			}
+			if contains := bytes.Contains(raw, []byte("super-secret")); contains == useSOPS {
+				t.Errorf("expected the item file to contain the secret in plain text: %t, file: %s", !useSOPS, raw)
+			}
+
+			items, err := client.GetInUseInformationForAllItems("")
+			if err != nil {
+				t.Fatalf("failed to list items: %v", err)
+			}
+			var names []string
+			for name := range items {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if diff := cmp.Diff([]string{"synced", "team/item"}, names); diff != "" {
+				t.Errorf("unexpected items: %s", diff)
+			}
+			userSecrets, err := client.GetUserSecrets()
+			if err != nil {
+				t.Fatalf("failed to get user secrets: %v", err)
+			}
+			expected := map[types.NamespacedName]map[string]string{
+				{Namespace: "ns", Name: "name"}: {vault.VaultSourceKey: "kv/synced"},
+			}
+			if diff := cmp.Diff(expected, userSecrets); diff != "" {
+				t.Errorf("unexpected user secrets: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFileStoreRejectsPathsOutsideRoot(t *testing.T) {
+	store := &fileStore{root: "/secrets"}
+	for _, path := range []string{"kv/../../etc/passwd", "", "/", "kv//item"} {
+		if _, err := store.fileFor(path); err == nil {
+			t.Errorf("expected an error for path %q", path)
+		}
+	}
+	file, err := store.fileFor("kv/team/item")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file != "/secrets/kv/team/item.yaml" {
+		t.Errorf("unexpected file: %s", file)
+	}
+}
@@ -5,22 +5,45 @@ import (
 	"flag"
 	"fmt"
 
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/util"
 	"github.com/openshift/ci-tools/pkg/vaultclient"
 )
 
+const (
+	// ProviderVault stores items in the KV engine of a Vault instance.
+	ProviderVault = "vault"
+	// ProviderFile stores items in (optionally SOPS-encrypted) files.
+	ProviderFile = "file"
+	// ProviderKubernetes stores items in secrets in a Kubernetes namespace.
+	ProviderKubernetes = "kubernetes"
+)
+
 type CLIOptions struct {
+	Provider string
+
 	VaultTokenFile string
 	VaultAddr      string
 	VaultPrefix    string
 	VaultRole      string
 
 	VaultToken string
+
+	StoreDir  string
+	StoreSOPS bool
+
+	StoreNamespace string
 }
 
 func (o *CLIOptions) Bind(fs *flag.FlagSet, getenv func(string) string, censor *DynamicCensor) {
+	fs.StringVar(&o.Provider, "secret-store", ProviderVault, fmt.Sprintf("Where to store secrets: %q, %q or %q. The --vault-prefix applies to all of them.", ProviderVault, ProviderFile, ProviderKubernetes))
+	fs.StringVar(&o.StoreDir, "secret-store-dir", "", "Directory that holds the items, one YAML file per item. Mandatory with --secret-store=file.")
+	fs.BoolVar(&o.StoreSOPS, "secret-store-sops", false, "Encrypt and decrypt the items in --secret-store-dir with the sops binary.")
+	fs.StringVar(&o.StoreNamespace, "secret-store-namespace", "", "Namespace that holds the items, one secret per item. Mandatory with --secret-store=kubernetes. The cluster is loaded from the KUBECONFIG env var or the in-cluster config.")
 	fs.StringVar(&o.VaultAddr, "vault-addr", "", "Address of the vault endpoint. Defaults to the VAULT_ADDR env var if unset. Mutually exclusive with --bw-user and --bw-password-path.")
 	fs.StringVar(&o.VaultTokenFile, "vault-token-file", "", "Token file to use when interacting with Vault, defaults to the VAULT_TOKEN env var if unset. Mutually exclusive with --bw-user and --bw-password-path.")
-	fs.StringVar(&o.VaultPrefix, "vault-prefix", "", "Prefix under which to operate in Vault. Mandatory when using vault.")
+	fs.StringVar(&o.VaultPrefix, "vault-prefix", "", "Prefix under which to operate in the secret store. Mandatory.")
 	fs.StringVar(&o.VaultRole, "vault-role", "", "The vault role to use for Kubernetes auth. When passed and no token is passed, login via Kubernetes auth will be attempted.")
 	o.VaultAddr = getenv("VAULT_ADDR")
 	if v := getenv("VAULT_TOKEN"); v != "" {
@@ -30,6 +53,21 @@ func (o *CLIOptions) Bind(fs *flag.FlagSet, getenv func(string) string, censor *
 }
 
 func (o *CLIOptions) Validate() error {
+	switch o.Provider {
+	case ProviderFile:
+		if o.StoreDir == "" || o.VaultPrefix == "" {
+			return errors.New("--secret-store-dir and --vault-prefix must be specified together with --secret-store=file")
+		}
+		return nil
+	case ProviderKubernetes:
+		if o.StoreNamespace == "" || o.VaultPrefix == "" {
+			return errors.New("--secret-store-namespace and --vault-prefix must be specified together with --secret-store=kubernetes")
+		}
+		return nil
+	case ProviderVault, "":
+	default:
+		return fmt.Errorf("--secret-store must be one of %q, %q or %q, not %q", ProviderVault, ProviderFile, ProviderKubernetes, o.Provider)
+	}
 	if o.VaultAddr == "" || (o.VaultToken == "" && o.VaultTokenFile == "" && o.VaultRole == "") || o.VaultPrefix == "" {
 		return errors.New("--vault-addr, one of --vault-token, the VAULT_TOKEN env var or --vault-role and --vault-prefix must be specified together")
 	}
@@ -51,6 +89,20 @@ func (o *CLIOptions) NewReadOnlyClient(censor *DynamicCensor) (ReadOnlyClient, e
 }
 
 func (o *CLIOptions) NewClient(censor *DynamicCensor) (Client, error) {
+	switch o.Provider {
+	case ProviderFile:
+		return NewVaultClient(NewFileStore(o.StoreDir, o.StoreSOPS), o.VaultPrefix, censor), nil
+	case ProviderKubernetes:
+		config, err := util.LoadClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cluster config: %w", err)
+		}
+		client, err := ctrlruntimeclient.New(config, ctrlruntimeclient.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct kubernetes client: %w", err)
+		}
+		return NewVaultClient(NewKubernetesStore(client, o.StoreNamespace), o.VaultPrefix, censor), nil
+	}
 	var c *vaultclient.VaultClient
 	var err error
 	if o.VaultRole != "" {
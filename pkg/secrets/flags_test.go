@@ -23,12 +23,17 @@ func TestParseOptions(t *testing.T) {
 		{
 			name:     "vault address from environment",
 			env:      map[string]string{"VAULT_ADDR": "vault address"},
-			expected: CLIOptions{VaultAddr: "vault address"},
+			expected: CLIOptions{Provider: ProviderVault, VaultAddr: "vault address"},
 		},
 		{
 			name:     "vault token from environment",
 			env:      map[string]string{"VAULT_TOKEN": "vault token"},
-			expected: CLIOptions{VaultToken: "vault token"},
+			expected: CLIOptions{Provider: ProviderVault, VaultToken: "vault token"},
+		},
+		{
+			name:     "file store",
+			given:    []string{"--secret-store=file", "--secret-store-dir=/secrets", "--secret-store-sops", "--vault-prefix=kv"},
+			expected: CLIOptions{Provider: ProviderFile, StoreDir: "/secrets", StoreSOPS: true, VaultPrefix: "kv"},
 		},
 	}
 	censor := NewDynamicCensor()
@@ -101,6 +106,46 @@ func TestValidateOptions(t *testing.T) {
 			},
 			expected: fmt.Errorf("--vault-addr, one of --vault-token, the VAULT_TOKEN env var or --vault-role and --vault-prefix must be specified together"),
 		},
+		{
+			name: "file store",
+			given: CLIOptions{
+				Provider:    ProviderFile,
+				StoreDir:    "/secrets",
+				VaultPrefix: "kv",
+			},
+		},
+		{
+			name: "file store without directory",
+			given: CLIOptions{
+				Provider:    ProviderFile,
+				VaultPrefix: "kv",
+			},
+			expected: fmt.Errorf("--secret-store-dir and --vault-prefix must be specified together with --secret-store=file"),
+		},
+		{
+			name: "kubernetes store",
+			given: CLIOptions{
+				Provider:       ProviderKubernetes,
+				StoreNamespace: "secrets",
+				VaultPrefix:    "kv",
+			},
+		},
+		{
+			name: "kubernetes store without prefix",
+			given: CLIOptions{
+				Provider:       ProviderKubernetes,
+				StoreNamespace: "secrets",
+			},
+			expected: fmt.Errorf("--secret-store-namespace and --vault-prefix must be specified together with --secret-store=kubernetes"),
+		},
+		{
+			name: "unknown store",
+			given: CLIOptions{
+				Provider:    "bitwarden",
+				VaultPrefix: "kv",
+			},
+			expected: fmt.Errorf(`--secret-store must be one of "vault", "file" or "kubernetes", not "bitwarden"`),
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,150 @@
+package secrets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/vaultclient"
+)
+
+const (
+	// KubernetesStoreItemLabel marks the secrets that hold the items of the store.
+	KubernetesStoreItemLabel = "ci.openshift.io/secret-store-item"
+	// KubernetesStorePathAnnotation records the path of the item a secret holds.
+	KubernetesStorePathAnnotation = "ci.openshift.io/secret-store-path"
+	// KubernetesStoreUpdatedAnnotation records when the item was last changed.
+	KubernetesStoreUpdatedAnnotation = "ci.openshift.io/secret-store-updated"
+	// kubernetesStoreDataKey is the key under which the fields of the item are
+	// stored. Fields are serialized together because item fields like
+	// secretsync/target-name are not valid secret keys.
+	kubernetesStoreDataKey = "item.json"
+)
+
+type kubernetesStore struct {
+	client    ctrlruntimeclient.Client
+	namespace string
+	now       func() time.Time
+}
+
+// NewKubernetesStore returns a store that keeps every item in a secret in the
+// given namespace.
+func NewKubernetesStore(client ctrlruntimeclient.Client, namespace string) VaultClient {
+	return &kubernetesStore{client: client, namespace: namespace, now: time.Now}
+}
+
+var invalidSecretNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// secretNameFor derives the name of the secret holding the item at path. The
+// readable part of the name is lossy, so the hash of the path is appended to
+// keep names unique.
+func secretNameFor(path string) string {
+	readable := strings.Trim(invalidSecretNameCharacters.ReplaceAllString(strings.ToLower(path), "-"), "-")
+	if len(readable) > 200 {
+		readable = strings.TrimRight(readable[:200], "-")
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(path)))[:16]
+	if readable == "" {
+		return hash
+	}
+	return readable + "-" + hash
+}
+
+func (s *kubernetesStore) getSecret(path string) (*coreapi.Secret, error) {
+	secret := &coreapi.Secret{}
+	key := types.NamespacedName{Namespace: s.namespace, Name: secretNameFor(path)}
+	if err := s.client.Get(context.TODO(), key, secret); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, &notFoundError{path: path}
+		}
+		return nil, fmt.Errorf("failed to get secret %s: %w", key, err)
+	}
+	if actual := secret.Annotations[KubernetesStorePathAnnotation]; actual != path {
+		return nil, fmt.Errorf("secret %s holds item %q instead of %q", key, actual, path)
+	}
+	return secret, nil
+}
+
+func kvDataFromSecret(secret *coreapi.Secret) (*vaultclient.KVData, error) {
+	data := map[string]string{}
+	if err := json.Unmarshal(secret.Data[kubernetesStoreDataKey], &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	created := secret.CreationTimestamp.Time
+	if updated, err := time.Parse(time.RFC3339, secret.Annotations[KubernetesStoreUpdatedAnnotation]); err == nil {
+		created = updated
+	}
+	return &vaultclient.KVData{Data: data, Metadata: vaultclient.KVMetadata{CreatedTime: created}}, nil
+}
+
+func (s *kubernetesStore) GetKV(path string) (*vaultclient.KVData, error) {
+	secret, err := s.getSecret(path)
+	if err != nil {
+		return nil, err
+	}
+	return kvDataFromSecret(secret)
+}
+
+func (s *kubernetesStore) ListKVRecursively(path string) ([]string, error) {
+	secrets := &coreapi.SecretList{}
+	if err := s.client.List(context.TODO(), secrets, ctrlruntimeclient.InNamespace(s.namespace), ctrlruntimeclient.HasLabels{KubernetesStoreItemLabel}); err != nil {
+		return nil, fmt.Errorf("failed to list secrets in namespace %s: %w", s.namespace, err)
+	}
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	var result []string
+	for _, secret := range secrets.Items {
+		if item := secret.Annotations[KubernetesStorePathAnnotation]; strings.HasPrefix(item, prefix) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func (s *kubernetesStore) UpsertKV(path string, data map[string]string) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal item %s: %w", path, err)
+	}
+	secret, err := s.getSecret(path)
+	if err != nil {
+		if !isNotFound(err) {
+			return err
+		}
+		secret = &coreapi.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   s.namespace,
+				Name:        secretNameFor(path),
+				Labels:      map[string]string{KubernetesStoreItemLabel: "true"},
+				Annotations: map[string]string{KubernetesStorePathAnnotation: path},
+			},
+			Type: coreapi.SecretTypeOpaque,
+		}
+	} else {
+		current, err := kvDataFromSecret(secret)
+		if err == nil && reflect.DeepEqual(current.Data, data) {
+			return nil
+		}
+	}
+	secret.Annotations[KubernetesStoreUpdatedAnnotation] = s.now().UTC().Format(time.RFC3339)
+	secret.Data = map[string][]byte{kubernetesStoreDataKey: raw}
+	if secret.ResourceVersion == "" {
+		err = s.client.Create(context.TODO(), secret)
+	} else {
+		err = s.client.Update(context.TODO(), secret)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write secret %s/%s for item %s: %w", secret.Namespace, secret.Name, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ci-tools/pkg/vaultclient"
+)
+
+func TestSecretNameFor(t *testing.T) {
+	for path, expected := range map[string]string{
+		"kv/dptp/My_Item": "kv-dptp-my-item-",
+		"kv/dptp/my-item": "kv-dptp-my-item-",
+		"///":             "",
+	} {
+		actual := secretNameFor(path)
+		if len(actual) != len(expected)+16 || actual[:len(expected)] != expected {
+			t.Errorf("%s: expected a name starting with %q and a 16 character hash, got %q", path, expected, actual)
+		}
+	}
+	if secretNameFor("kv/dptp/My_Item") == secretNameFor("kv/dptp/my-item") {
+		t.Error("expected paths that sanitize to the same name to get different secret names")
+	}
+}
+
+func TestKubernetesStore(t *testing.T) {
+	client := fakectrlruntimeclient.NewFakeClient()
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := &kubernetesStore{client: client, namespace: "secrets", now: func() time.Time { return now }}
+
+	if _, err := store.GetKV("kv/team/item"); !isNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	data := map[string]string{"token": "super-secret", "secretsync/target-name": "name"}
+	if err := store.UpsertKV("kv/team/item", data); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if err := store.UpsertKV("kv/other", map[string]string{"password": "password"}); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	expected := &vaultclient.KVData{Data: data, Metadata: vaultclient.KVMetadata{CreatedTime: now}}
+	actual, err := store.GetKV("kv/team/item")
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected item: %s", diff)
+	}
+
+	// Writing the same data again does not change the item.
+	now = now.Add(time.Hour)
+	if err := store.UpsertKV("kv/team/item", data); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+	if actual, err := store.GetKV("kv/team/item"); err != nil || !actual.Metadata.CreatedTime.Equal(expected.Metadata.CreatedTime) {
+		t.Errorf("expected unchanged item to keep its time, got %v, %v", actual, err)
+	}
+	data["token"] = "rotated"
+	if err := store.UpsertKV("kv/team/item", data); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+	if actual, err := store.GetKV("kv/team/item"); err != nil || !actual.Metadata.CreatedTime.Equal(now) || actual.Data["token"] != "rotated" {
+		t.Errorf("expected updated item, got %v, %v", actual, err)
+	}
+
+	items, err := store.ListKVRecursively("kv/team")
+	if err != nil {
+		t.Fatalf("failed to list items: %v", err)
+	}
+	if diff := cmp.Diff([]string{"kv/team/item"}, items); diff != "" {
+		t.Errorf("unexpected items: %s", diff)
+	}
+
+	secret := &coreapi.Secret{}
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "secrets", Name: secretNameFor("kv/team/item")}, secret); err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	if secret.Labels[KubernetesStoreItemLabel] != "true" || secret.Annotations[KubernetesStorePathAnnotation] != "kv/team/item" {
+		t.Errorf("unexpected metadata on secret: %v", secret.ObjectMeta)
+	}
+}
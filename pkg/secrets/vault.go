@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -15,12 +16,31 @@ import (
 	"github.com/openshift/ci-tools/pkg/vaultclient"
 )
 
+// VaultClient is the key-value store that backs a Client. Items are addressed
+// by slash-separated paths and hold a flat map of fields. Besides Vault's KV
+// engine, the store can be a directory of (optionally SOPS-encrypted) files
+// or Kubernetes secrets in a namespace.
 type VaultClient interface {
 	GetKV(path string) (*vaultclient.KVData, error)
 	ListKVRecursively(path string) ([]string, error)
 	UpsertKV(path string, data map[string]string) error
 }
 
+// notFoundError is returned by stores other than Vault when no item exists at
+// the requested path.
+type notFoundError struct {
+	path string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("no item at path %q", e.path)
+}
+
+func isNotFound(err error) bool {
+	var notFound *notFoundError
+	return errors.As(err, &notFound) || vaultclient.IsNotFound(err)
+}
+
 type dryRunClient struct {
 	file *os.File
 }
@@ -120,7 +140,7 @@ func (c *vaultClient) setItemAtPath(path, field string, content string) error {
 	path = c.pathFor(path)
 	var data map[string]string
 	if current, err := c.upstream.GetKV(path); err != nil {
-		if !vaultclient.IsNotFound(err) {
+		if !isNotFound(err) {
 			return err
 		}
 		data = map[string]string{field: content}
@@ -136,7 +156,7 @@ func (c *vaultClient) HasItem(itemName string) (bool, error) {
 	path := c.pathFor(itemName)
 	_, err := c.upstream.GetKV(path)
 	if err != nil {
-		if vaultclient.IsNotFound(err) {
+		if isNotFound(err) {
 			return false, nil
 		}
 		return false, err
@@ -2,10 +2,12 @@ package steps
 
 import (
 	"archive/tar"
+	"bufio"
 	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -38,6 +40,7 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 )
 
@@ -174,16 +177,20 @@ type PodClient interface {
 	WithNewLoggingClient() PodClient
 	Exec(namespace, pod string, opts *coreapi.PodExecOptions) (remotecommand.Executor, error)
 	GetLogs(namespace, name string, opts *coreapi.PodLogOptions) *rest.Request
+	// Censorer returns the censor for the content downloaded from pods. It
+	// returns nil when the content does not need to be censored.
+	Censorer() *secrets.DynamicCensor
 }
 
-func NewPodClient(ctrlclient loggingclient.LoggingClient, config *rest.Config, client rest.Interface) PodClient {
-	return &podClient{LoggingClient: ctrlclient, config: config, client: client}
+func NewPodClient(ctrlclient loggingclient.LoggingClient, config *rest.Config, client rest.Interface, censor *secrets.DynamicCensor) PodClient {
+	return &podClient{LoggingClient: ctrlclient, config: config, client: client, censor: censor}
 }
 
 type podClient struct {
 	loggingclient.LoggingClient
 	config *rest.Config
 	client rest.Interface
+	censor *secrets.DynamicCensor
 }
 
 func (c podClient) Exec(namespace, pod string, opts *coreapi.PodExecOptions) (remotecommand.Executor, error) {
@@ -204,9 +211,39 @@ func (c podClient) WithNewLoggingClient() PodClient {
 		LoggingClient: c.New(),
 		config:        c.config,
 		client:        c.client,
+		censor:        c.censor,
 	}
 }
 
+func (c podClient) Censorer() *secrets.DynamicCensor {
+	return c.censor
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// censoringWriter wraps the writer to censor the secrets known to the pod
+// client. Closing the returned writer flushes it, but does not close w.
+func censoringWriter(podClient PodClient, w io.Writer) io.WriteCloser {
+	censor := podClient.Censorer()
+	if censor == nil {
+		return nopWriteCloser{Writer: w}
+	}
+	return secrets.NewCensoringWriter(w, censor)
+}
+
+// sniffLen is the length of the content used to detect whether it is text
+const sniffLen = 512
+
+// isText determines whether the content starting with head is text. Only text
+// is censored, as replacing bytes in binary content would corrupt it.
+func isText(head []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(head), "text/")
+}
+
 type evaluator func(obj runtime.Object) (bool, error)
 
 // waitForConditionOnObject uses a watch to wait for a condition to be true on an object.
@@ -285,7 +322,10 @@ func waitForContainer(podClient PodClient, ns, name, containerName string) error
 	return waitForConditionOnObject(ctx, podClient, ctrlruntimeclient.ObjectKey{Namespace: ns, Name: name}, &corev1.PodList{}, &corev1.Pod{}, evaluatorFunc, 300*5*time.Second)
 }
 
-func copyArtifacts(podClient PodClient, into, ns, name, containerName string, paths []string) error {
+// copyArtifacts copies the files in the paths of the container to the local
+// directory. If include is set, only the files for which it returns true are
+// copied.
+func copyArtifacts(podClient PodClient, into, ns, name, containerName string, paths []string, include func(string) bool) error {
 	logrus.Tracef("Copying artifacts from %s into %s", name, into)
 	var args []string
 	for _, s := range paths {
@@ -338,6 +378,10 @@ func copyArtifacts(podClient PodClient, into, ns, name, containerName string, pa
 		}
 		p := filepath.Join(into, name)
 		if h.FileInfo().IsDir() {
+			if include != nil {
+				// only the directories holding included files are created
+				continue
+			}
 			if err := os.MkdirAll(p, 0750); err != nil {
 				return fmt.Errorf("could not create target directory %s for artifacts: %w", p, err)
 			}
@@ -347,11 +391,33 @@ func copyArtifacts(podClient PodClient, into, ns, name, containerName string, pa
 			fmt.Fprintf(os.Stderr, "warn: ignoring link when copying artifacts to %s: %s\n", into, h.Name)
 			continue
 		}
+		if include != nil {
+			if !include(name) {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+				return fmt.Errorf("could not create target directory %s for artifacts: %w", filepath.Dir(p), err)
+			}
+		}
 		f, err := os.Create(p)
 		if err != nil {
 			return fmt.Errorf("could not create target file %s for artifact: %w", p, err)
 		}
-		if _, err := io.Copy(f, tr); err != nil {
+		content := bufio.NewReaderSize(tr, sniffLen)
+		head, err := content.Peek(sniffLen)
+		if err != nil && err != io.EOF {
+			f.Close()
+			return fmt.Errorf("could not read contents of file %s: %w", p, err)
+		}
+		var out io.WriteCloser = nopWriteCloser{Writer: f}
+		if isText(head) {
+			out = censoringWriter(podClient, f)
+		}
+		if _, err := io.Copy(out, content); err != nil {
+			f.Close()
+			return fmt.Errorf("could not copy contents of file %s: %w", p, err)
+		}
+		if err := out.Close(); err != nil {
 			f.Close()
 			return fmt.Errorf("could not copy contents of file %s: %w", p, err)
 		}
@@ -461,6 +527,8 @@ type ArtifactWorker struct {
 	dir       string
 	podClient PodClient
 	namespace string
+	// include selects the artifacts to copy, all of them if unset
+	include func(string) bool
 
 	// Processing this requires the lock, so it must not be held
 	// when writing into it.
@@ -489,6 +557,17 @@ func NewArtifactWorker(podClient PodClient, artifactDir, namespace string) *Arti
 	return w
 }
 
+// NewJUnitArtifactWorker returns an ArtifactWorker which only copies the jUnit
+// results from the artifacts of the pods, leaving the container logs and the
+// other artifacts to the upload done by the pods themselves.
+func NewJUnitArtifactWorker(podClient PodClient, artifactDir, namespace string) *ArtifactWorker {
+	w := NewArtifactWorker(podClient, artifactDir, namespace)
+	w.include = func(name string) bool {
+		return junit.IsResultFile(path.Base(name))
+	}
+	return w
+}
+
 func (w *ArtifactWorker) run() {
 	for podName := range w.podsToDownload {
 		logger := logrus.WithField("pod", podName)
@@ -513,9 +592,11 @@ func (w *ArtifactWorker) downloadArtifacts(podName string, hasArtifacts bool) er
 	if err := os.MkdirAll(w.dir, 0750); err != nil {
 		return fmt.Errorf("unable to create artifact directory %s: %w", w.dir, err)
 	}
-	logger.Trace("Downloading container logs for Pod.")
-	if err := gatherContainerLogsOutput(w.podClient, filepath.Join(w.dir, "container-logs"), w.namespace, podName); err != nil {
-		logrus.WithError(err).Warn("Unable to gather container logs.")
+	if w.include == nil {
+		logger.Trace("Downloading container logs for Pod.")
+		if err := gatherContainerLogsOutput(w.podClient, filepath.Join(w.dir, "container-logs"), w.namespace, podName); err != nil {
+			logrus.WithError(err).Warn("Unable to gather container logs.")
+		}
 	}
 
 	// only pods with an artifacts container should be gathered
@@ -540,7 +621,7 @@ func (w *ArtifactWorker) downloadArtifacts(podName string, hasArtifacts bool) er
 	}
 
 	logger.Trace("Copying artifacts from Pod.")
-	if err := copyArtifacts(w.podClient, w.dir, w.namespace, podName, "artifacts", []string{"/tmp/artifacts"}); err != nil {
+	if err := copyArtifacts(w.podClient, w.dir, w.namespace, podName, "artifacts", []string{"/tmp/artifacts"}, w.include); err != nil {
 		return fmt.Errorf("unable to retrieve artifacts from pod %s: %w", podName, err)
 	}
 	return nil
@@ -663,6 +744,17 @@ func addArtifactContainersFromPod(pod *coreapi.Pod, worker *ArtifactWorker) {
 	worker.CollectFromPod(pod.Name, containers, waitForContainers)
 }
 
+// collectUploadedArtifacts adds an artifacts container to a pod decorated with
+// the pod utilities, which serves the artifacts that the sidecar uploads. The
+// worker copies them once the container and the sidecar are done.
+func collectUploadedArtifacts(pod *coreapi.Pod, containerName string, worker *ArtifactWorker) {
+	logMount, _ := decorate.LogMountAndVolume()
+	container := artifactsContainer()
+	container.VolumeMounts = []coreapi.VolumeMount{{Name: logMount.Name, MountPath: "/tmp/artifacts", SubPath: "artifacts"}}
+	pod.Spec.Containers = append(pod.Spec.Containers, container)
+	worker.CollectFromPod(pod.Name, []string{containerName, "sidecar"}, nil)
+}
+
 func containerHasVolumeName(container coreapi.Container, name string) bool {
 	for _, v := range container.VolumeMounts {
 		if v.Name == name {
@@ -745,7 +837,11 @@ func gatherContainerLogsOutput(podClient PodClient, artifactDir, namespace, podN
 			w := gzip.NewWriter(file)
 			logger.Trace("Fetching container logs.")
 			if s, err := podClient.GetLogs(namespace, podName, &coreapi.PodLogOptions{Container: status.Name}).Stream(context.TODO()); err == nil {
-				if _, err := io.Copy(w, s); err != nil {
+				censored := censoringWriter(podClient, w)
+				if _, err := io.Copy(censored, s); err != nil {
+					validationErrors = append(validationErrors, fmt.Errorf("error: Unable to copy log output from pod container %s: %w", status.Name, err))
+				}
+				if err := censored.Close(); err != nil {
 					validationErrors = append(validationErrors, fmt.Errorf("error: Unable to copy log output from pod container %s: %w", status.Name, err))
 				}
 				s.Close()
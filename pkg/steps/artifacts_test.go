@@ -1,12 +1,17 @@
 package steps
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -25,6 +30,7 @@ import (
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
@@ -497,6 +503,10 @@ func TestTestCaseNotifier_SubTests(t *testing.T) {
 type fakePodClient struct {
 	*fakePodExecutor
 	namespace, name string
+	censor          *secrets.DynamicCensor
+	// artifacts is the tarball served from the artifacts container, a
+	// default one holding test.txt is served if unset
+	artifacts []byte
 }
 
 func (f *fakePodClient) Exec(namespace, name string, opts *coreapi.PodExecOptions) (remotecommand.Executor, error) {
@@ -506,7 +516,7 @@ func (f *fakePodClient) Exec(namespace, name string, opts *coreapi.PodExecOption
 	if name != f.name {
 		return nil, fmt.Errorf("unexpected name: %q", name)
 	}
-	return &testExecutor{command: opts.Command}, nil
+	return &testExecutor{command: opts.Command, artifacts: f.artifacts}, nil
 }
 
 func (*fakePodClient) GetLogs(string, string, *coreapi.PodLogOptions) *rest.Request {
@@ -517,12 +527,21 @@ func (f *fakePodClient) WithNewLoggingClient() PodClient {
 	return f
 }
 
+func (f *fakePodClient) Censorer() *secrets.DynamicCensor {
+	return f.censor
+}
+
 type testExecutor struct {
-	command []string
+	command   []string
+	artifacts []byte
 }
 
 func (e testExecutor) Stream(opts remotecommand.StreamOptions) error {
 	if reflect.DeepEqual(e.command, []string{"tar", "czf", "-", "-C", "/tmp/artifacts", "."}) {
+		if e.artifacts != nil {
+			_, err := opts.Stdout.Write(e.artifacts)
+			return err
+		}
 		var tar []byte
 		tar, err := base64.StdEncoding.DecodeString(`
 H4sIAMq1b10AA+3RPQrDMAyGYc09hU8QrCpOzuOAKR2y2Ar0+HX/tnboEErhfRbxoW8QyEvzwS8uO4r
@@ -550,6 +569,8 @@ func TestArtifactWorker(t *testing.T) {
 		}
 	}()
 	pod := "pod"
+	censor := secrets.NewDynamicCensor()
+	censor.AddSecrets("test")
 	podClient := &fakePodClient{
 		fakePodExecutor: &fakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewFakeClient(
 			&coreapi.Pod{
@@ -571,7 +592,14 @@ func TestArtifactWorker(t *testing.T) {
 		},
 		namespace: "namespace",
 		name:      pod,
+		censor:    &censor,
 	}
+	// binary content holding a secret would be corrupted by censoring it
+	binary := []byte{0x1f, 0x8b, 0x08, 0x00, 't', 'e', 's', 't', 0x00, 0xff}
+	podClient.artifacts = artifactsTarball(t, map[string][]byte{
+		"test.txt":  []byte("test\n"),
+		"binary.gz": binary,
+	})
 	w := NewArtifactWorker(podClient, tmp, "namespace")
 	w.CollectFromPod(pod, []string{"container"}, nil)
 	w.Complete(pod)
@@ -588,9 +616,116 @@ func TestArtifactWorker(t *testing.T) {
 	for _, f := range files {
 		names = append(names, f.Name())
 	}
-	if diff := cmp.Diff(names, []string{"test.txt"}); diff != "" {
+	if diff := cmp.Diff(names, []string{"binary.gz", "test.txt"}); diff != "" {
 		t.Fatalf("artifacts do not match expected: %s", diff)
 	}
+	content, err := ioutil.ReadFile(filepath.Join(tmp, "test.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("****\n", string(content)); diff != "" {
+		t.Errorf("artifact was not censored: %s", diff)
+	}
+	content, err = ioutil.ReadFile(filepath.Join(tmp, "binary.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(binary, content); diff != "" {
+		t.Errorf("binary artifact was modified: %s", diff)
+	}
+}
+
+// artifactsTarball creates the gzipped tarball of the files.
+func artifactsTarball(t *testing.T, files map[string][]byte) []byte {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name]))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestJUnitArtifactWorker(t *testing.T) {
+	tmp := t.TempDir()
+	pod := &coreapi.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: "pod", Namespace: "namespace"},
+		Spec:       coreapi.PodSpec{Containers: []coreapi.Container{{Name: "test"}, {Name: "sidecar"}}},
+	}
+	podClient := &fakePodClient{
+		fakePodExecutor: &fakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewFakeClient(
+			&coreapi.Pod{
+				ObjectMeta: pod.ObjectMeta,
+				Status: coreapi.PodStatus{
+					ContainerStatuses: []coreapi.ContainerStatus{
+						{
+							Name: "artifacts",
+							State: coreapi.ContainerState{
+								Running: &coreapi.ContainerStateRunning{},
+							},
+						},
+					},
+				},
+			})),
+		},
+		namespace: "namespace",
+		name:      "pod",
+		artifacts: artifactsTarball(t, map[string][]byte{
+			"junit_e2e.xml":          []byte("<testsuite/>"),
+			"results/junit_more.xml": []byte("<testsuite/>"),
+			"build-log.txt":          []byte("log"),
+			"must-gather/dump.xml":   []byte("<dump/>"),
+		}),
+	}
+	w := NewJUnitArtifactWorker(podClient, tmp, "namespace")
+	collectUploadedArtifacts(pod, "test", w)
+	expectedContainer := artifactsContainer()
+	expectedContainer.VolumeMounts = []coreapi.VolumeMount{{Name: "logs", MountPath: "/tmp/artifacts", SubPath: "artifacts"}}
+	if diff := cmp.Diff(pod.Spec.Containers[len(pod.Spec.Containers)-1], expectedContainer); diff != "" {
+		t.Errorf("unexpected artifacts container: %s", diff)
+	}
+	w.Notify(pod, "test")
+	select {
+	case <-w.Done("pod"):
+		t.Fatal("artifacts were collected before the sidecar finished")
+	default:
+	}
+	w.Notify(pod, "sidecar")
+	select {
+	case <-w.Done("pod"):
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for artifact worker to finish")
+	}
+	var copied []string
+	if err := filepath.Walk(tmp, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(tmp, path)
+		copied = append(copied, rel)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"junit_e2e.xml", "results/junit_more.xml"}, copied); diff != "" {
+		t.Errorf("unexpected artifacts copied: %s", diff)
+	}
 }
 
 func TestAddArtifactsToPod(t *testing.T) {
@@ -0,0 +1,114 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	crcontrollerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// CheckpointConfigMapName is the name of the ConfigMap in the test
+	// namespace which records the steps that have completed successfully.
+	CheckpointConfigMapName = "ci-operator-checkpoint"
+	// checkpointInputHashKey holds the input hash of the execution that
+	// wrote the checkpoint.
+	checkpointInputHashKey = "input-hash"
+	// checkpointStepsKey holds a serialized list of completed step names.
+	// Step names are not valid ConfigMap keys, so they are stored together.
+	checkpointStepsKey = "completed-steps"
+)
+
+// Checkpoint records which steps in the graph have completed successfully, so
+// that a later execution of the same graph can skip them.
+type Checkpoint interface {
+	// Completed determines if the step completed in a previous execution.
+	Completed(name string) bool
+	// Record persists the successful completion of a step.
+	Record(ctx context.Context, name string) error
+}
+
+type configMapCheckpoint struct {
+	client    ctrlruntimeclient.Client
+	namespace string
+	inputHash string
+
+	lock      sync.Mutex
+	completed sets.String
+}
+
+// NewConfigMapCheckpoint loads the checkpoint stored in the test namespace.
+// Steps recorded by an execution with a different input hash are ignored,
+// since their outputs may not match what this execution would produce. When
+// not resuming, the stored checkpoint is discarded and only steps completed
+// by this execution will be recorded.
+func NewConfigMapCheckpoint(ctx context.Context, client ctrlruntimeclient.Client, namespace, inputHash string, resume bool) (Checkpoint, error) {
+	c := &configMapCheckpoint{
+		client:    client,
+		namespace: namespace,
+		inputHash: inputHash,
+		completed: sets.NewString(),
+	}
+	if !resume {
+		return c, nil
+	}
+	cm := &coreapi.ConfigMap{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: CheckpointConfigMapName}, cm); err != nil {
+		if kerrors.IsNotFound(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("could not get checkpoint: %w", err)
+	}
+	if hash := cm.Data[checkpointInputHashKey]; hash != inputHash {
+		logrus.Infof("Ignoring checkpoint written for input hash %q, current input hash is %q", hash, inputHash)
+		return c, nil
+	}
+	var names []string
+	if raw, ok := cm.Data[checkpointStepsKey]; ok {
+		if err := json.Unmarshal([]byte(raw), &names); err != nil {
+			return nil, fmt.Errorf("could not parse checkpoint: %w", err)
+		}
+	}
+	c.completed.Insert(names...)
+	if c.completed.Len() > 0 {
+		logrus.Infof("Resuming from checkpoint, %d steps already completed: %s", c.completed.Len(), strings.Join(c.completed.List(), ", "))
+	}
+	return c, nil
+}
+
+func (c *configMapCheckpoint) Completed(name string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.completed.Has(name)
+}
+
+func (c *configMapCheckpoint) Record(ctx context.Context, name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.completed.Insert(name)
+	raw, err := json.Marshal(c.completed.List())
+	if err != nil {
+		return fmt.Errorf("could not serialize checkpoint: %w", err)
+	}
+	cm := &coreapi.ConfigMap{ObjectMeta: meta.ObjectMeta{Namespace: c.namespace, Name: CheckpointConfigMapName}}
+	if _, err := crcontrollerutil.CreateOrUpdate(ctx, c.client, cm, func() error {
+		cm.Data = map[string]string{
+			checkpointInputHashKey: c.inputHash,
+			checkpointStepsKey:     string(raw),
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("could not record completion of step %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigMapCheckpoint(t *testing.T) {
+	stored := func(hash, steps string) *coreapi.ConfigMap {
+		return &coreapi.ConfigMap{
+			ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: CheckpointConfigMapName},
+			Data:       map[string]string{checkpointInputHashKey: hash, checkpointStepsKey: steps},
+		}
+	}
+	for _, tc := range []struct {
+		name      string
+		existing  []runtime.Object
+		resume    bool
+		completed []string
+		expected  string
+	}{
+		{
+			name:     "no checkpoint",
+			resume:   true,
+			expected: `["e2e"]`,
+		},
+		{
+			name:      "resuming from checkpoint with the same input hash",
+			existing:  []runtime.Object{stored("hash", `["[images]","src"]`)},
+			resume:    true,
+			completed: []string{"[images]", "src"},
+			expected:  `["[images]","e2e","src"]`,
+		},
+		{
+			name:     "checkpoint for a different input hash is ignored",
+			existing: []runtime.Object{stored("other", `["[images]","src"]`)},
+			resume:   true,
+			expected: `["e2e"]`,
+		},
+		{
+			name:     "checkpoint is discarded when not resuming",
+			existing: []runtime.Object{stored("hash", `["[images]","src"]`)},
+			expected: `["e2e"]`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := fakectrlruntimeclient.NewFakeClient(tc.existing...)
+			checkpoint, err := NewConfigMapCheckpoint(ctx, client, "ns", "hash", tc.resume)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, name := range []string{"[images]", "src", "e2e"} {
+				if expected, actual := sets.NewString(tc.completed...).Has(name), checkpoint.Completed(name); expected != actual {
+					t.Errorf("step %s: expected completed to be %t, got %t", name, expected, actual)
+				}
+			}
+			if err := checkpoint.Record(ctx, "e2e"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cm := &coreapi.ConfigMap{}
+			if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: CheckpointConfigMapName}, cm); err != nil {
+				t.Fatalf("could not get checkpoint: %v", err)
+			}
+			if diff := cmp.Diff(map[string]string{checkpointInputHashKey: "hash", checkpointStepsKey: tc.expected}, cm.Data); diff != "" {
+				t.Errorf("unexpected checkpoint: %s", diff)
+			}
+		})
+	}
+}
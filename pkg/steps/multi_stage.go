@@ -3,9 +3,13 @@ package steps
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -17,6 +21,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/remotecommand"
 	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/entrypoint"
 	utilpointer "k8s.io/utils/pointer"
@@ -74,6 +79,10 @@ type multiStageTestStep struct {
 	allowBestEffortPostSteps *bool
 	leases                   []api.StepLease
 	clusterClaim             *api.ClusterClaim
+	observers                []api.Observer
+	// lock guards subTests and subSteps, which are recorded concurrently
+	// by the steps of parallel groups
+	lock sync.Mutex
 }
 
 func MultiStageTestStep(
@@ -111,6 +120,7 @@ func newMultiStageTestStep(
 		allowBestEffortPostSteps: ms.AllowBestEffortPostSteps,
 		leases:                   leases,
 		clusterClaim:             testConfig.ClusterClaim,
+		observers:                ms.Observers,
 	}
 }
 
@@ -150,15 +160,22 @@ func (s *multiStageTestStep) run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	observers, err := s.newObserverRunner(ctx, env, secretVolumes, secretVolumeMounts)
+	if err != nil {
+		return fmt.Errorf("failed to generate observer pods: %w", err)
+	}
+	// observers that no step asks for explicitly run for the whole test
+	observers.start(s.unrequestedObservers())
 	var errs []error
-	if err := s.runSteps(ctx, s.pre, env, true, false, secretVolumes, secretVolumeMounts); err != nil {
+	if err := s.runSteps(ctx, s.pre, env, true, false, secretVolumes, secretVolumeMounts, observers); err != nil {
 		errs = append(errs, fmt.Errorf("%q pre steps failed: %w", s.name, err))
-	} else if err := s.runSteps(ctx, s.test, env, true, len(errs) != 0, secretVolumes, secretVolumeMounts); err != nil {
+	} else if err := s.runSteps(ctx, s.test, env, true, len(errs) != 0, secretVolumes, secretVolumeMounts, observers); err != nil {
 		errs = append(errs, fmt.Errorf("%q test steps failed: %w", s.name, err))
 	}
-	if err := s.runSteps(context.Background(), s.post, env, false, len(errs) != 0, secretVolumes, secretVolumeMounts); err != nil {
+	if err := s.runSteps(context.Background(), s.post, env, false, len(errs) != 0, secretVolumes, secretVolumeMounts, observers); err != nil {
 		errs = append(errs, fmt.Errorf("%q post steps failed: %w", s.name, err))
 	}
+	observers.stop()
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -180,7 +197,7 @@ func (s *multiStageTestStep) Requires() (ret []api.StepLink) {
 		claimRelease = s.clusterClaim.ClaimRelease(s.name)
 	}
 	var needsReleaseImage, needsReleasePayload bool
-	for _, step := range append(append(s.pre, s.test...), s.post...) {
+	for _, step := range append(s.allSteps(), s.observerSteps()...) {
 		if link, ok := step.FromImageTag(); ok {
 			ret = append(ret, api.InternalImageLink(link))
 		} else {
@@ -393,13 +410,14 @@ func (s *multiStageTestStep) runSteps(
 	hasPrevErrs bool,
 	secretVolumes []coreapi.Volume,
 	secretVolumeMounts []coreapi.VolumeMount,
+	observers *observerRunner,
 ) error {
 	pods, isBestEffort, err := s.generatePods(steps, env, hasPrevErrs, secretVolumes, secretVolumeMounts)
 	if err != nil {
 		return err
 	}
 	var errs []error
-	if err := s.runPods(ctx, pods, shortCircuit, isBestEffort); err != nil {
+	if err := s.runPods(ctx, pods, shortCircuit, hasPrevErrs, isBestEffort, observers); err != nil {
 		errs = append(errs, err)
 	}
 	select {
@@ -818,30 +836,175 @@ func addCliInjector(imagestream string, pod *coreapi.Pod) {
 	})
 }
 
-func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, shortCircuit bool, isBestEffort func(string) bool) error {
+func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, shortCircuit, hasPrevErrs bool, isBestEffort func(string) bool, observers *observerRunner) error {
 	var errs []error
-	for _, pod := range pods {
-		err := s.runPod(ctx, &pod, NewTestCaseNotifier(NopNotifier))
-		if err != nil {
-			if isBestEffort(pod.Name) {
-				logrus.Infof("Pod %s is running in best-effort mode, ignoring the failure...", pod.Name)
+	for _, group := range s.parallelGroups(pods) {
+		// all steps in a parallel group see the outcome of the steps
+		// before the group, as they start at the same time
+		failed := hasPrevErrs || len(errs) > 0
+		var run []coreapi.Pod
+		for _, pod := range group {
+			condition := s.conditionFor(pod.Labels[LabelMetadataStep])
+			// once a step fails, only the steps that handle the failure
+			// are executed in phases that stop at the first failure
+			if shortCircuit && len(errs) > 0 && condition.Outcome != api.StepOutcomeFailure {
 				continue
 			}
-			errs = append(errs, err)
-			if shortCircuit {
-				break
+			if !condition.MatchesOutcome(failed) {
+				logrus.Infof("Skipping step %s, it only runs on %s of the previous steps.", pod.Name, condition.Outcome)
+				continue
 			}
+			run = append(run, pod)
 		}
+		errs = append(errs, s.runParallelPods(ctx, run, isBestEffort, observers)...)
 	}
 	return utilerrors.NewAggregate(errs)
 }
 
-func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notifier *TestCaseNotifier) error {
+// parallelGroups splits the Pods into the groups that are executed
+// together. Steps which are not part of a parallel group form a group of
+// their own.
+func (s *multiStageTestStep) parallelGroups(pods []coreapi.Pod) [][]coreapi.Pod {
+	var groups [][]coreapi.Pod
+	var last string
+	for _, pod := range pods {
+		group := s.parallelGroupFor(pod.Labels[LabelMetadataStep])
+		if group != "" && group == last {
+			groups[len(groups)-1] = append(groups[len(groups)-1], pod)
+			continue
+		}
+		groups = append(groups, []coreapi.Pod{pod})
+		last = group
+	}
+	return groups
+}
+
+// runParallelPods executes the Pods concurrently and waits for all of them.
+func (s *multiStageTestStep) runParallelPods(ctx context.Context, pods []coreapi.Pod, isBestEffort func(string) bool, observers *observerRunner) []error {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+	run := func(pod coreapi.Pod) {
+		observers.start(s.observersForStep(pod.Labels[LabelMetadataStep]))
+		err := s.runPod(ctx, &pod)
+		if err == nil {
+			return
+		}
+		if isBestEffort(pod.Name) {
+			logrus.Infof("Pod %s is running in best-effort mode, ignoring the failure...", pod.Name)
+			return
+		}
+		lock.Lock()
+		errs = append(errs, err)
+		lock.Unlock()
+	}
+	switch len(pods) {
+	case 0:
+		return nil
+	case 1:
+		run(pods[0])
+		return errs
+	}
+	logrus.Infof("Running steps %s in parallel.", strings.Join(podNames(pods), ", "))
+	for _, pod := range pods {
+		wg.Add(1)
+		go func(pod coreapi.Pod) {
+			defer wg.Done()
+			run(pod)
+		}(pod)
+	}
+	wg.Wait()
+	return errs
+}
+
+func podNames(pods []coreapi.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod) error {
+	retry := s.retryPolicyFor(pod.Labels[LabelMetadataStep])
+	base := pod.DeepCopy()
+	var err error
+	for attempt := uint(1); ; attempt++ {
+		name := base.Name
+		junitDir := filepath.Join(s.name, base.Labels[LabelMetadataStep])
+		if retry != nil && retry.Attempts > 1 {
+			name = fmt.Sprintf("%s (attempt %d)", base.Name, attempt)
+			// zero-padded, so that the results of later attempts sort last
+			junitDir = filepath.Join(junitDir, fmt.Sprintf("attempt-%02d", attempt))
+		}
+		pod = base.DeepCopy()
+		var nested ContainerNotifier = NopNotifier
+		if artifactDir, artifactsRequested := api.Artifacts(); artifactsRequested {
+			// the step's artifacts are uploaded by its sidecar, but its jUnit
+			// results are also copied locally to be reported by ci-operator
+			worker := NewJUnitArtifactWorker(s.client, filepath.Join(artifactDir, junitDir), pod.Namespace)
+			collectUploadedArtifacts(pod, multiStageTestStepContainerName, worker)
+			nested = worker
+		}
+		pod, err = s.runPodAttempt(ctx, pod, name, NewTestCaseNotifier(nested))
+		if err == nil || retry == nil || attempt >= retry.Attempts || ctx.Err() != nil {
+			break
+		}
+		if !s.shouldRetry(ctx, pod, retry) {
+			logrus.Infof("Step %s failed in a way its retry policy does not cover, not retrying.", pod.Name)
+			break
+		}
+		var backoff time.Duration
+		if retry.Backoff != nil {
+			backoff = retryBackoff(retry.Backoff.Duration, attempt)
+		}
+		logrus.Infof("Retrying step %s in %s (attempt %d of %d).", pod.Name, backoff, attempt+1, retry.Attempts)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+	}
+	if err != nil {
+		linksText := strings.Builder{}
+		linksText.WriteString(fmt.Sprintf("Link to step on registry info site: https://steps.ci.openshift.org/reference/%s", strings.TrimPrefix(pod.Name, s.name+"-")))
+		linksText.WriteString(fmt.Sprintf("\nLink to job on registry info site: https://steps.ci.openshift.org/job?org=%s&repo=%s&branch=%s&test=%s", s.config.Metadata.Org, s.config.Metadata.Repo, s.config.Metadata.Branch, s.name))
+		if s.config.Metadata.Variant != "" {
+			linksText.WriteString(fmt.Sprintf("&variant=%s", s.config.Metadata.Variant))
+		}
+		status := "failed"
+		if pod.Status.Phase == coreapi.PodFailed && pod.Status.Reason == "DeadlineExceeded" {
+			status = "exceeded the configured timeout"
+			if pod.Spec.ActiveDeadlineSeconds != nil {
+				status = fmt.Sprintf("%s activeDeadlineSeconds=%d", status, *pod.Spec.ActiveDeadlineSeconds)
+			}
+		}
+		return fmt.Errorf("%q pod %q %s: %w\n%s", s.name, pod.Name, status, err, linksText.String())
+	}
+	return nil
+}
+
+// retryBackoff returns the wait time after the given attempt: the initial
+// backoff, doubled for every previous retry and capped at api.MaxRetryBackoff.
+func retryBackoff(initial time.Duration, attempt uint) time.Duration {
+	backoff := initial
+	for i := uint(1); i < attempt && backoff < api.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > api.MaxRetryBackoff {
+		backoff = api.MaxRetryBackoff
+	}
+	return backoff
+}
+
+// runPodAttempt executes the pod once, recording the execution as a sub-step
+// and as JUnit test cases under the given name, which tells attempts apart.
+// It returns the last observed state of the pod.
+func (s *multiStageTestStep) runPodAttempt(ctx context.Context, pod *coreapi.Pod, name string, notifier *TestCaseNotifier) (*coreapi.Pod, error) {
 	start := time.Now()
 	logrus.Infof("Running step %s.", pod.Name)
 	client := s.client.WithNewLoggingClient()
 	if _, err := createOrRestartPod(ctx, client, pod); err != nil {
-		return fmt.Errorf("failed to create or restart %s pod: %w", pod.Name, err)
+		return pod, fmt.Errorf("failed to create or restart %s pod: %w", pod.Name, err)
 	}
 	newPod, err := waitForPodCompletion(ctx, client, pod.Namespace, pod.Name, notifier, false)
 	if newPod != nil {
@@ -854,31 +1017,334 @@ func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notif
 		verb = "failed"
 	}
 	logrus.Infof("Step %s %s after %s.", pod.Name, verb, duration.Truncate(time.Second))
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	s.subSteps = append(s.subSteps, api.CIOperatorStepDetailInfo{
-		StepName:    pod.Name,
-		Description: fmt.Sprintf("Run pod %s", pod.Name),
+		StepName:    name,
+		Description: fmt.Sprintf("Run pod %s", name),
 		StartedAt:   &start,
 		FinishedAt:  &finished,
 		Duration:    &duration,
 		Failed:      utilpointer.BoolPtr(err != nil),
 		Manifests:   client.Objects(),
 	})
-	s.subTests = append(s.subTests, notifier.SubTests(fmt.Sprintf("%s - %s ", s.Description(), pod.Name))...)
+	s.subTests = append(s.subTests, notifier.SubTests(fmt.Sprintf("%s - %s ", s.Description(), name))...)
+	return pod, err
+}
+
+func (s *multiStageTestStep) retryPolicyFor(name string) *api.RetryPolicy {
+	if step, ok := s.stepFor(name); ok {
+		return step.Retry
+	}
+	return nil
+}
+
+// shouldRetry determines whether the failure of the pod is covered by the
+// filters in the retry policy.
+func (s *multiStageTestStep) shouldRetry(ctx context.Context, pod *coreapi.Pod, retry *api.RetryPolicy) bool {
+	if len(retry.ExitCodes) > 0 {
+		var exitCode *int32
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == multiStageTestStepContainerName && status.State.Terminated != nil {
+				exitCode = &status.State.Terminated.ExitCode
+			}
+		}
+		if exitCode == nil {
+			return false
+		}
+		var matched bool
+		for _, code := range retry.ExitCodes {
+			if code == *exitCode {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if retry.LogPattern != "" {
+		pattern, err := regexp.Compile(retry.LogPattern)
+		if err != nil {
+			logrus.WithError(err).Warnf("Invalid log pattern in retry policy for step %s.", pod.Name)
+			return false
+		}
+		logs, err := s.client.GetLogs(pod.Namespace, pod.Name, &coreapi.PodLogOptions{Container: multiStageTestStepContainerName}).Stream(ctx)
+		if err != nil {
+			logrus.WithError(err).Warnf("Unable to retrieve logs of step %s to match the retry policy.", pod.Name)
+			return false
+		}
+		defer logs.Close()
+		content, err := ioutil.ReadAll(logs)
+		if err != nil {
+			logrus.WithError(err).Warnf("Unable to read logs of step %s to match the retry policy.", pod.Name)
+			return false
+		}
+		if !pattern.Match(content) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *multiStageTestStep) allSteps() []api.LiteralTestStep {
+	return append(append(append([]api.LiteralTestStep{}, s.pre...), s.test...), s.post...)
+}
+
+// observerSteps adapts observers to steps so they can share the logic for
+// dependencies and Pod generation.
+func (s *multiStageTestStep) observerSteps() []api.LiteralTestStep {
+	var ret []api.LiteralTestStep
+	timeout := s.observerTimeout()
+	for _, observer := range s.observers {
+		ret = append(ret, api.LiteralTestStep{
+			As:        observer.Name,
+			From:      observer.From,
+			FromImage: observer.FromImage,
+			Commands:  observer.Commands,
+			Timeout:   &prowapi.Duration{Duration: timeout},
+		})
+	}
+	return ret
+}
+
+// observerTimeout is long enough for an observer to outlive every step in the
+// test, as they are expected to run until we ask them to stop.
+func (s *multiStageTestStep) observerTimeout() time.Duration {
+	var timeout time.Duration
+	for _, step := range s.allSteps() {
+		if step.Timeout != nil {
+			timeout += step.Timeout.Duration
+		} else {
+			timeout += entrypoint.DefaultTimeout
+		}
+	}
+	return timeout
+}
+
+func (s *multiStageTestStep) stepFor(name string) (api.LiteralTestStep, bool) {
+	for _, step := range s.allSteps() {
+		if step.As == name {
+			return step, true
+		}
+	}
+	return api.LiteralTestStep{}, false
+}
+
+// parallelGroupFor returns the parallel group of the named step.
+func (s *multiStageTestStep) parallelGroupFor(name string) string {
+	if step, ok := s.stepFor(name); ok {
+		return step.ParallelGroup
+	}
+	return ""
+}
+
+// conditionFor returns the condition of the named step that is evaluated
+// at runtime.
+func (s *multiStageTestStep) conditionFor(name string) api.StepCondition {
+	if step, ok := s.stepFor(name); ok && step.When != nil {
+		return *step.When
+	}
+	return api.StepCondition{}
+}
+
+// observersForStep returns the observers requested by the named step.
+func (s *multiStageTestStep) observersForStep(name string) []string {
+	if step, ok := s.stepFor(name); ok {
+		return step.Observers
+	}
+	return nil
+}
+
+// unrequestedObservers returns the observers that were enabled for the test
+// without any step asking for them.
+func (s *multiStageTestStep) unrequestedObservers() []string {
+	requested := sets.NewString()
+	for _, step := range s.allSteps() {
+		requested.Insert(step.Observers...)
+	}
+	var ret []string
+	for _, observer := range s.observers {
+		if !requested.Has(observer.Name) {
+			ret = append(ret, observer.Name)
+		}
+	}
+	return ret
+}
+
+// observerRunner launches observer Pods when they are first needed and
+// stops them once the multi-stage test is done. Observers run concurrently
+// with the steps, so their results are only handed back to the step when
+// they are stopped.
+type observerRunner struct {
+	step *multiStageTestStep
+	ctx  context.Context
+	pods map[string]coreapi.Pod
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	lock     sync.Mutex
+	started  sets.String
+	subTests []*junit.TestCase
+	subSteps []api.CIOperatorStepDetailInfo
+}
+
+func (s *multiStageTestStep) newObserverRunner(ctx context.Context, env []coreapi.EnvVar, secretVolumes []coreapi.Volume, secretVolumeMounts []coreapi.VolumeMount) (*observerRunner, error) {
+	pods, _, err := s.generatePods(s.observerSteps(), env, false, secretVolumes, secretVolumeMounts)
 	if err != nil {
-		linksText := strings.Builder{}
-		linksText.WriteString(fmt.Sprintf("Link to step on registry info site: https://steps.ci.openshift.org/reference/%s", strings.TrimPrefix(pod.Name, s.name+"-")))
-		linksText.WriteString(fmt.Sprintf("\nLink to job on registry info site: https://steps.ci.openshift.org/job?org=%s&repo=%s&branch=%s&test=%s", s.config.Metadata.Org, s.config.Metadata.Repo, s.config.Metadata.Branch, s.name))
-		if s.config.Metadata.Variant != "" {
-			linksText.WriteString(fmt.Sprintf("&variant=%s", s.config.Metadata.Variant))
+		return nil, err
+	}
+	r := &observerRunner{
+		step:    s,
+		ctx:     ctx,
+		pods:    map[string]coreapi.Pod{},
+		done:    make(chan struct{}),
+		started: sets.NewString(),
+	}
+	for i := range pods {
+		addObserverArtifacts(&pods[i])
+		r.pods[pods[i].Labels[LabelMetadataStep]] = pods[i]
+	}
+	return r, nil
+}
+
+// addObserverArtifacts backs the artifact directory of the observer with a
+// volume that the artifacts container can serve to the ArtifactWorker.
+func addObserverArtifacts(pod *coreapi.Pod) {
+	container := &pod.Spec.Containers[0]
+	for _, env := range container.Env {
+		if env.Name != artifactEnv {
+			continue
 		}
-		status := "failed"
-		if pod.Status.Phase == coreapi.PodFailed && pod.Status.Reason == "DeadlineExceeded" {
-			status = "exceeded the configured timeout"
-			if pod.Spec.ActiveDeadlineSeconds != nil {
-				status = fmt.Sprintf("%s activeDeadlineSeconds=%d", status, *pod.Spec.ActiveDeadlineSeconds)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, coreapi.Volume{
+			Name:         "artifacts",
+			VolumeSource: coreapi.VolumeSource{EmptyDir: &coreapi.EmptyDirVolumeSource{}},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, coreapi.VolumeMount{Name: "artifacts", MountPath: env.Value})
+		addArtifactsToPod(pod)
+		return
+	}
+}
+
+// start launches any of the named observers which are not running yet.
+func (r *observerRunner) start(names []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, name := range names {
+		pod, ok := r.pods[name]
+		if !ok || r.started.Has(name) {
+			// observers may be disabled for the test even if a step asks for them
+			continue
+		}
+		r.started.Insert(name)
+		r.wg.Add(1)
+		go r.run(name, pod)
+	}
+}
+
+// stop signals all running observers to terminate gracefully, waits for them
+// to exit and records their results on the multi-stage test step.
+func (r *observerRunner) stop() {
+	close(r.done)
+	r.wg.Wait()
+	r.step.subTests = append(r.step.subTests, r.subTests...)
+	r.step.subSteps = append(r.step.subSteps, r.subSteps...)
+}
+
+func (r *observerRunner) run(name string, pod coreapi.Pod) {
+	defer r.wg.Done()
+	start := time.Now()
+	logrus.Infof("Running observer %s.", pod.Name)
+	client := r.step.client.WithNewLoggingClient()
+	var notifier ContainerNotifier = NopNotifier
+	if artifactDir, artifactsRequested := api.Artifacts(); artifactsRequested {
+		artifacts := NewArtifactWorker(client, filepath.Join(artifactDir, r.step.name, name), pod.Namespace)
+		addArtifactContainersFromPod(&pod, artifacts)
+		notifier = artifacts
+	}
+	finished := make(chan struct{})
+	stopped := make(chan struct{})
+	_, err := createOrRestartPod(r.ctx, client, &pod)
+	if err != nil {
+		err = fmt.Errorf("failed to create or restart %s pod: %w", pod.Name, err)
+	} else {
+		go func() {
+			select {
+			case <-finished:
+			case <-r.done:
+				close(stopped)
+				logrus.Infof("Signalling observer %s to terminate.", pod.Name)
+				if err := signalObserver(r.ctx, client, pod.Namespace, pod.Name); err != nil {
+					logrus.WithError(err).Warnf("Failed to signal observer %s to terminate.", pod.Name)
+				}
 			}
+		}()
+		_, err = waitForPodCompletion(r.ctx, client, pod.Namespace, pod.Name, notifier, false)
+		close(finished)
+	}
+	select {
+	case <-stopped:
+		// exiting with an error is expected when the observer was terminated
+		if err != nil && r.ctx.Err() == nil {
+			logrus.Debugf("Observer %s exited after being signalled: %v", pod.Name, err)
+			err = nil
 		}
-		return fmt.Errorf("%q pod %q %s: %w\n%s", s.name, pod.Name, status, err, linksText.String())
+	default:
+	}
+	finishedAt := time.Now()
+	duration := finishedAt.Sub(start)
+	verb := "succeeded"
+	test := &junit.TestCase{
+		Name:     fmt.Sprintf("%s - %s observer", r.step.Description(), pod.Name),
+		Duration: duration.Seconds(),
+	}
+	if err != nil {
+		verb = "failed"
+		test.FailureOutput = &junit.FailureOutput{Output: err.Error()}
+	}
+	logrus.Infof("Observer %s %s after %s.", pod.Name, verb, duration.Truncate(time.Second))
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.subTests = append(r.subTests, test)
+	r.subSteps = append(r.subSteps, api.CIOperatorStepDetailInfo{
+		StepName:    pod.Name,
+		Description: fmt.Sprintf("Run observer pod %s", pod.Name),
+		StartedAt:   &start,
+		FinishedAt:  &finishedAt,
+		Duration:    &duration,
+		Failed:      utilpointer.BoolPtr(err != nil),
+		Manifests:   client.Objects(),
+	})
+}
+
+// signalObserver sends SIGTERM to the entrypoint of a running observer, which
+// forwards it to the observer process and gives it a grace period to exit, so
+// that artifacts are still gathered and uploaded.
+func signalObserver(ctx context.Context, client PodClient, namespace, name string) error {
+	pod := &coreapi.Pod{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("could not get observer pod: %w", err)
+	}
+	if podJobIsOK(pod) || podJobIsFailed(pod) {
+		return nil
+	}
+	e, err := client.Exec(namespace, name, &coreapi.PodExecOptions{
+		Container: multiStageTestStepContainerName,
+		Stdout:    true,
+		Stderr:    true,
+		Command:   []string{"/bin/bash", "-c", "kill -s TERM 1"},
+	})
+	if err != nil {
+		return err
+	}
+	if err := e.Stream(remotecommand.StreamOptions{
+		Stdout: os.Stderr,
+		Stderr: os.Stderr,
+	}); err != nil {
+		return fmt.Errorf("could not run remote command: %w", err)
 	}
 	return nil
 }
@@ -7,6 +7,8 @@ import (
 	"path"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -86,6 +88,15 @@ func TestRequires(t *testing.T) {
 			api.InternalImageLink(
 				api.PipelineImageStreamTagReferenceSource),
 		},
+	}, {
+		name: "observer needs pipeline image, should have InternalImageLink",
+		steps: api.MultiStageTestConfigurationLiteral{
+			Observers: []api.Observer{{Name: "observer", From: "src"}},
+		},
+		req: []api.StepLink{
+			api.InternalImageLink(
+				api.PipelineImageStreamTagReferenceSource),
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			step := MultiStageTestStep(api.TestStepConfiguration{
@@ -339,6 +350,7 @@ func TestGeneratePodBestEffort(t *testing.T) {
 type fakePodExecutor struct {
 	loggingclient.LoggingClient
 	failures    sets.String
+	lock        sync.Mutex
 	createdPods []*coreapi.Pod
 }
 
@@ -347,7 +359,9 @@ func (f *fakePodExecutor) Create(ctx context.Context, o ctrlruntimeclient.Object
 		if pod.Namespace == "" {
 			return errors.New("pod had no namespace set")
 		}
+		f.lock.Lock()
 		f.createdPods = append(f.createdPods, pod.DeepCopy())
+		f.lock.Unlock()
 		pod.Status.Phase = coreapi.PodPending
 	}
 	return f.LoggingClient.Create(ctx, o, opts...)
@@ -381,9 +395,11 @@ func (f *fakePodExecutor) Get(ctx context.Context, n ctrlruntimeclient.ObjectKey
 func TestRun(t *testing.T) {
 	yes := true
 	for _, tc := range []struct {
-		name     string
-		failures sets.String
-		expected []string
+		name       string
+		failures   sets.String
+		conditions map[string]api.StepOutcome
+		parallel   sets.String
+		expected   []string
 	}{{
 		name: "no step fails, no error",
 		expected: []string{
@@ -414,6 +430,32 @@ func TestRun(t *testing.T) {
 			"test-test0", "test-test1",
 			"test-post0",
 		},
+	}, {
+		name:       "no step fails, steps conditioned on failure do not run",
+		conditions: map[string]api.StepOutcome{"test1": api.StepOutcomeFailure, "post0": api.StepOutcomeSuccess},
+		expected: []string{
+			"test-pre0", "test-pre1",
+			"test-test0",
+			"test-post0",
+		},
+	}, {
+		name:       "failure in a test step, steps conditioned on failure still run",
+		failures:   sets.NewString("test-test0"),
+		conditions: map[string]api.StepOutcome{"test1": api.StepOutcomeFailure, "post0": api.StepOutcomeSuccess},
+		expected: []string{
+			"test-pre0", "test-pre1",
+			"test-test0", "test-test1",
+			"test-post1",
+		},
+	}, {
+		name:     "failure in a parallel group, all steps of the group run",
+		failures: sets.NewString("test-test0"),
+		parallel: sets.NewString("test0", "test1"),
+		expected: []string{
+			"test-pre0", "test-pre1",
+			"test-test0", "test-test1",
+			"test-post0", "test-post1",
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			sa := &coreapi.ServiceAccount{
@@ -439,14 +481,25 @@ func TestRun(t *testing.T) {
 				},
 			}
 			jobSpec.SetNamespace("ns")
+			literal := api.MultiStageTestConfigurationLiteral{
+				Pre:                []api.LiteralTestStep{{As: "pre0"}, {As: "pre1"}},
+				Test:               []api.LiteralTestStep{{As: "test0"}, {As: "test1"}},
+				Post:               []api.LiteralTestStep{{As: "post0"}, {As: "post1", OptionalOnSuccess: &yes}},
+				AllowSkipOnSuccess: &yes,
+			}
+			for _, phase := range [][]api.LiteralTestStep{literal.Pre, literal.Test, literal.Post} {
+				for i := range phase {
+					if outcome, ok := tc.conditions[phase[i].As]; ok {
+						phase[i].When = &api.StepCondition{Outcome: outcome}
+					}
+					if tc.parallel.Has(phase[i].As) {
+						phase[i].ParallelGroup = "group"
+					}
+				}
+			}
 			step := MultiStageTestStep(api.TestStepConfiguration{
-				As: name,
-				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
-					Pre:                []api.LiteralTestStep{{As: "pre0"}, {As: "pre1"}},
-					Test:               []api.LiteralTestStep{{As: "test0"}, {As: "test1"}},
-					Post:               []api.LiteralTestStep{{As: "post0"}, {As: "post1", OptionalOnSuccess: &yes}},
-					AllowSkipOnSuccess: &yes,
-				},
+				As:                                 name,
+				MultiStageTestConfigurationLiteral: &literal,
 			}, &api.ReleaseBuildConfiguration{}, nil, &fakePodClient{fakePodExecutor: crclient}, &jobSpec, nil)
 			if err := step.Run(context.Background()); (err != nil) != (tc.failures != nil) {
 				t.Errorf("expected error: %t, got error: %v", (tc.failures != nil), err)
@@ -465,6 +518,15 @@ func TestRun(t *testing.T) {
 				}
 				names = append(names, pod.Name)
 			}
+			// steps in parallel groups start in any order
+			for i := 0; i < len(names); i++ {
+				j := i
+				for j < len(names) && tc.parallel.Has(strings.TrimPrefix(names[j], name+"-")) {
+					j++
+				}
+				sort.Strings(names[i:j])
+				i = j
+			}
 			if diff := cmp.Diff(names, tc.expected); diff != "" {
 				t.Errorf("did not execute correct pods: %s, actual: %v, expected: %v", diff, names, tc.expected)
 			}
@@ -561,6 +623,191 @@ func TestJUnit(t *testing.T) {
 	}
 }
 
+func TestRetries(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		retry            *api.RetryPolicy
+		expected         []string
+		expectedSubTests []string
+	}{{
+		name:             "no retry policy, step runs once",
+		expected:         []string{"test-test0"},
+		expectedSubTests: []string{"Run multi-stage test test - test-test0 container test"},
+	}, {
+		name:     "failing step is executed for all attempts",
+		retry:    &api.RetryPolicy{Attempts: 3},
+		expected: []string{"test-test0", "test-test0", "test-test0"},
+		expectedSubTests: []string{
+			"Run multi-stage test test - test-test0 (attempt 1) container test",
+			"Run multi-stage test test - test-test0 (attempt 2) container test",
+			"Run multi-stage test test - test-test0 (attempt 3) container test",
+		},
+	}, {
+		name:             "failure not matching the exit codes is not retried",
+		retry:            &api.RetryPolicy{Attempts: 3, ExitCodes: []int32{2}},
+		expected:         []string{"test-test0"},
+		expectedSubTests: []string{"Run multi-stage test test - test-test0 (attempt 1) container test"},
+	}, {
+		name:     "failure matching the exit codes is retried",
+		retry:    &api.RetryPolicy{Attempts: 2, ExitCodes: []int32{1, 2}},
+		expected: []string{"test-test0", "test-test0"},
+		expectedSubTests: []string{
+			"Run multi-stage test test - test-test0 (attempt 1) container test",
+			"Run multi-stage test test - test-test0 (attempt 2) container test",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &coreapi.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			client := &fakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewFakeClient(sa.DeepCopyObject())), failures: sets.NewString("test-test0")}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Test: []api.LiteralTestStep{{As: "test0", Retry: tc.retry}},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, &fakePodClient{fakePodExecutor: client}, &jobSpec, nil)
+			if err := step.Run(context.Background()); err == nil {
+				t.Error("expected an error, got none")
+			}
+			var pods []string
+			for _, pod := range client.createdPods {
+				pods = append(pods, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expected, pods); diff != "" {
+				t.Errorf("did not create correct pods: %s", diff)
+			}
+			var subTests []string
+			for _, test := range step.(subtestReporter).SubTests() {
+				subTests = append(subTests, test.Name)
+			}
+			if diff := cmp.Diff(tc.expectedSubTests, subTests); diff != "" {
+				t.Errorf("did not record one sub-test per attempt: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		initial  time.Duration
+		attempt  uint
+		expected time.Duration
+	}{
+		{initial: time.Minute, attempt: 1, expected: time.Minute},
+		{initial: time.Minute, attempt: 3, expected: 4 * time.Minute},
+		{initial: time.Minute, attempt: 10, expected: api.MaxRetryBackoff},
+		{initial: time.Minute, attempt: 100, expected: api.MaxRetryBackoff},
+		{initial: 0, attempt: 100, expected: 0},
+	} {
+		if actual := retryBackoff(tc.initial, tc.attempt); actual != tc.expected {
+			t.Errorf("backoff %s after attempt %d: expected %s, got %s", tc.initial, tc.attempt, tc.expected, actual)
+		}
+	}
+}
+
+func TestObservers(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		failures         sets.String
+		expectedPods     []string
+		expectedSubTests []string
+	}{{
+		name: "observers requested by steps and enabled for the test run",
+		expectedPods: []string{
+			"test-pre0", "test-test0", "test-post0",
+			"test-requested", "test-unrequested",
+		},
+		expectedSubTests: []string{
+			"Run multi-stage test test - test-pre0 container test",
+			"Run multi-stage test test - test-test0 container test",
+			"Run multi-stage test test - test-post0 container test",
+			"Run multi-stage test test - test-requested observer",
+			"Run multi-stage test test - test-unrequested observer",
+		},
+	}, {
+		name:     "observer requested by a step that does not run is not started",
+		failures: sets.NewString("test-pre0"),
+		expectedPods: []string{
+			"test-pre0", "test-post0",
+			"test-unrequested",
+		},
+		expectedSubTests: []string{
+			"Run multi-stage test test - test-pre0 container test",
+			"Run multi-stage test test - test-post0 container test",
+			"Run multi-stage test test - test-unrequested observer",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &coreapi.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			client := &fakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewFakeClient(sa.DeepCopyObject())), failures: tc.failures}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Pre:       []api.LiteralTestStep{{As: "pre0"}},
+					Test:      []api.LiteralTestStep{{As: "test0", Observers: []string{"requested", "disabled"}}},
+					Post:      []api.LiteralTestStep{{As: "post0"}},
+					Observers: []api.Observer{{Name: "requested"}, {Name: "unrequested"}},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, &fakePodClient{fakePodExecutor: client}, &jobSpec, nil)
+			if err := step.Run(context.Background()); (err != nil) != (tc.failures != nil) {
+				t.Errorf("expected error: %t, got error: %v", tc.failures != nil, err)
+			}
+			var pods []string
+			for _, pod := range client.createdPods {
+				pods = append(pods, pod.Name)
+			}
+			sort.Strings(pods)
+			sort.Strings(tc.expectedPods)
+			if diff := cmp.Diff(tc.expectedPods, pods); diff != "" {
+				t.Errorf("did not create correct pods: %s", diff)
+			}
+			var subTests []string
+			for _, t := range step.(subtestReporter).SubTests() {
+				subTests = append(subTests, t.Name)
+			}
+			// observers finish in an arbitrary order
+			sort.Strings(subTests)
+			sort.Strings(tc.expectedSubTests)
+			if diff := cmp.Diff(tc.expectedSubTests, subTests); diff != "" {
+				t.Errorf("did not report correct sub-tests: %s", diff)
+			}
+		})
+	}
+}
+
 func TestAddCredentials(t *testing.T) {
 	var testCases = []struct {
 		name        string
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/results"
@@ -15,11 +17,17 @@ type message struct {
 	node            *api.StepNode
 	duration        time.Duration
 	err             error
+	skipped         bool
 	additionalTests []*junit.TestCase
 	stepDetails     api.CIOperatorStepDetails
 }
 
-func Run(ctx context.Context, graph []*api.StepNode) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
+// Run executes the graph, starting from the roots. When a checkpoint is given,
+// steps it records as completed are not executed again. The links they create
+// are derived from the graph, which is identical for identical inputs, so the
+// steps that depend on them continue as if the completed step had just run.
+// Every step that succeeds is recorded in the checkpoint.
+func Run(ctx context.Context, graph []*api.StepNode, checkpoint Checkpoint) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
 	var seen []api.StepLink
 	executionResults := make(chan message)
 	done := make(chan bool)
@@ -34,7 +42,7 @@ func Run(ctx context.Context, graph []*api.StepNode) (*junit.TestSuites, []api.C
 
 	start := time.Now()
 	for _, root := range graph {
-		go runStep(ctx, root, executionResults)
+		go runStep(ctx, root, checkpoint, executionResults)
 	}
 
 	suites := &junit.TestSuites{
@@ -53,12 +61,21 @@ func Run(ctx context.Context, graph []*api.StepNode) (*junit.TestSuites, []api.C
 			ctxDone = nil
 		case out := <-executionResults:
 			testCase := &junit.TestCase{Name: out.node.Step.Description(), Duration: out.duration.Seconds()}
-			stepDetails = append(stepDetails, out.stepDetails)
+			if out.skipped {
+				testCase.SkipMessage = &junit.SkipMessage{Message: "Step completed in a previous execution"}
+			} else {
+				stepDetails = append(stepDetails, out.stepDetails)
+			}
 			if out.err != nil {
 				testCase.FailureOutput = &junit.FailureOutput{Output: out.err.Error()}
 				executionErrors = append(executionErrors, results.ForReason("step_failed").WithError(out.err).Errorf("step %s failed: %v", out.node.Step.Name(), out.err))
 			} else {
 				seen = append(seen, out.node.Step.Creates()...)
+				if checkpoint != nil && !out.skipped && out.node.Step.Name() != "" {
+					if err := checkpoint.Record(ctx, out.node.Step.Name()); err != nil {
+						logrus.WithError(err).Warn("Could not record step in checkpoint.")
+					}
+				}
 				if !interrupted {
 					for _, child := range out.node.Children {
 						// we can trigger a child if all of it's pre-requisites
@@ -68,7 +85,7 @@ func Run(ctx context.Context, graph []*api.StepNode) (*junit.TestSuites, []api.C
 						// when the last of its parents finishes.
 						if api.HasAllLinks(child.Step.Requires(), seen) {
 							wg.Add(1)
-							go runStep(ctx, child, executionResults)
+							go runStep(ctx, child, checkpoint, executionResults)
 						}
 					}
 				}
@@ -114,7 +131,12 @@ type SubStepReporter interface {
 	SubSteps() []api.CIOperatorStepDetailInfo
 }
 
-func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
+func runStep(ctx context.Context, node *api.StepNode, checkpoint Checkpoint, out chan<- message) {
+	if name := node.Step.Name(); checkpoint != nil && name != "" && checkpoint.Completed(name) {
+		logrus.Infof("Skipping step %s, it completed in a previous execution", name)
+		out <- message{node: node, skipped: true}
+		return
+	}
 	start := time.Now()
 	err := node.Step.Run(ctx)
 	var additionalTests []*junit.TestCase
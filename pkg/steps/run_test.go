@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift/ci-tools/pkg/api"
@@ -293,7 +294,7 @@ func TestStepsRun(t *testing.T) {
 			if tc.cancelled {
 				cancel()
 			}
-			suites, _, errs := Run(ctx, api.BuildGraph(steps))
+			suites, _, errs := Run(ctx, api.BuildGraph(steps), nil)
 			if errs == nil && len(tc.errExpected) > 0 {
 				t.Error("got no error but expected one")
 			}
@@ -339,3 +340,53 @@ func TestStepsRun(t *testing.T) {
 		})
 	}
 }
+
+func TestStepsRunFromCheckpoint(t *testing.T) {
+	src := &fakeStep{
+		name:     "src",
+		requires: []api.StepLink{api.ExternalImageLink(api.ImageStreamTagReference{Namespace: "ns", Name: "base", Tag: "latest"})},
+		creates:  []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceSource)},
+	}
+	bin := &fakeStep{
+		name:     "bin",
+		requires: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceSource)},
+		creates:  []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceBinaries)},
+	}
+	e2e := &fakeStep{
+		name:     "e2e",
+		requires: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceBinaries)},
+	}
+	checkpoint := &fakeCheckpoint{completed: sets.NewString("src")}
+	suites, details, errs := Run(context.Background(), api.BuildGraph([]api.Step{src, bin, e2e}), checkpoint)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	for step, expected := range map[*fakeStep]int{src: 0, bin: 1, e2e: 1} {
+		if step.numRuns != expected {
+			t.Errorf("step %s: expected %d runs, got %d", step.name, expected, step.numRuns)
+		}
+	}
+	if diff := cmp.Diff([]string{"bin", "e2e"}, checkpoint.recorded); diff != "" {
+		t.Errorf("unexpected recorded steps: %s", diff)
+	}
+	if len(details) != 2 {
+		t.Errorf("expected details for 2 steps, got %d", len(details))
+	}
+	if suite := suites.Suites[0]; suite.NumTests != 3 || suite.NumSkipped != 1 || suite.NumFailed != 0 {
+		t.Errorf("unexpected junit output: %#v", suite)
+	}
+}
+
+type fakeCheckpoint struct {
+	completed sets.String
+	recorded  []string
+}
+
+func (c *fakeCheckpoint) Completed(name string) bool {
+	return c.completed.Has(name)
+}
+
+func (c *fakeCheckpoint) Record(_ context.Context, name string) error {
+	c.recorded = append(c.recorded, name)
+	return nil
+}
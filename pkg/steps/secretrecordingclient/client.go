@@ -2,6 +2,9 @@ package secretrecordingclient
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -119,6 +122,11 @@ func (c *client) recordSecret(secret *v1.Secret) {
 		}
 		values = append(values, value)
 	}
+	for _, key := range []string{v1.DockerConfigJsonKey, v1.DockerConfigKey} {
+		if value, ok := secret.Data[key]; ok {
+			values = append(values, dockerConfigValues(value)...)
+		}
+	}
 	for _, key := range []string{"openshift.io/token-secret.value", "kubectl.kubernetes.io/last-applied-configuration"} {
 		if _, ok := secret.Annotations[key]; ok {
 			values = append(values, secret.Annotations[key])
@@ -127,6 +135,45 @@ func (c *client) recordSecret(secret *v1.Secret) {
 	c.censor.AddSecrets(values...)
 }
 
+// dockerAuth holds the credentials for a registry in a docker config
+type dockerAuth struct {
+	Auth          string `json:"auth"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// dockerConfigValues returns the credentials in a docker config. The config
+// itself is censored as a whole, but tools print the credentials for single
+// registries, most notably the base64-encoded auth.
+func dockerConfigValues(raw []byte) []string {
+	var auths map[string]dockerAuth
+	var config struct {
+		Auths map[string]dockerAuth `json:"auths"`
+	}
+	if err := json.Unmarshal(raw, &config); err == nil && config.Auths != nil {
+		auths = config.Auths
+	} else if err := json.Unmarshal(raw, &auths); err != nil {
+		return nil
+	}
+	var values []string
+	for _, auth := range auths {
+		values = append(values, auth.Auth, auth.Password, auth.IdentityToken)
+		if decoded, err := base64.StdEncoding.DecodeString(auth.Auth); err == nil {
+			values = append(values, string(decoded))
+			if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+				values = append(values, parts[1])
+			}
+		}
+	}
+	var nonEmpty []string
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return nonEmpty
+}
+
 func (c *client) Status() ctrlruntimeclient.StatusWriter {
 	return c.upstream.Status()
 }
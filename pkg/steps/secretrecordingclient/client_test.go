@@ -0,0 +1,38 @@
+package secretrecordingclient
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDockerConfigValues(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		raw      string
+		expected []string
+	}{
+		{
+			name:     "docker config json",
+			raw:      `{"auths":{"quay.io":{"auth":"dXNlcjpwYXNzd29yZA==","email":"user@example.com"},"registry.ci.openshift.org":{"identitytoken":"token"}}}`,
+			expected: []string{"dXNlcjpwYXNzd29yZA==", "user:password", "password", "token"},
+		},
+		{
+			name:     "legacy docker config",
+			raw:      `{"quay.io":{"auth":"dXNlcjpwYXNzd29yZA==","password":"password"}}`,
+			expected: []string{"dXNlcjpwYXNzd29yZA==", "user:password", "password", "password"},
+		},
+		{
+			name: "invalid config",
+			raw:  `not json`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := dockerConfigValues([]byte(tc.raw))
+			if diff := cmp.Diff(tc.expected, actual, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected values: %s", diff)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
 )
 
 // testStage is the point in a multi-stage test where a step is located.
@@ -72,7 +73,20 @@ var trapPattern = regexp.MustCompile(`(^|\W)\s*trap\s*['"]?\w*['"]?\s*\w*`)
 // component, the image references exist in the test configuration, etc.) are
 // not performed.
 func IsValidReference(step api.LiteralTestStep) []error {
-	return validateLiteralTestStep(&context{field: fieldPath(step.As)}, testStageUnknown, step, nil)
+	context := &context{field: fieldPath(step.As)}
+	ret := validateLiteralTestStep(context, testStageUnknown, step, nil)
+	if step.ParallelGroup != "" {
+		ret = append(ret, context.addField("parallel_group").errorf("cannot be set in registry references, use it where the reference is used instead"))
+	}
+	return ret
+}
+
+// IsValidChain validates the parameters a registry chain declares, including
+// their defaults, which resolution only checks against the parameters of the
+// steps they are passed to.
+func IsValidChain(chain api.RegistryChain) []error {
+	context := &context{field: fieldPath(chain.As)}
+	return validateParameterTypes(context.addField("env"), chain.Environment)
 }
 
 func validateTestStepConfiguration(fieldRoot string, input []api.TestStepConfiguration, release *api.ReleaseTagConfiguration, releases sets.String, resolved bool) []error {
@@ -440,6 +454,7 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 		}
 		context := newContext(fieldPath(fieldRoot), testConfig.Environment, releases)
 		validationErrors = append(validationErrors, validateLeases(context.addField("leases"), testConfig.Leases)...)
+		validationErrors = append(validationErrors, validateRegistryVersion(context.addField("registry_version"), testConfig.RegistryVersion)...)
 		validationErrors = append(validationErrors, validateTestSteps(context.addField("pre"), testStagePre, testConfig.Pre, claimRelease)...)
 		validationErrors = append(validationErrors, validateTestSteps(context.addField("test"), testStageTest, testConfig.Test, claimRelease)...)
 		validationErrors = append(validationErrors, validateTestSteps(context.addField("post"), testStagePost, testConfig.Post, claimRelease)...)
@@ -452,6 +467,7 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 			validationErrors = append(validationErrors, validateClusterProfile(fieldRoot, testConfig.ClusterProfile)...)
 		}
 		validationErrors = append(validationErrors, validateLeases(context.addField("leases"), testConfig.Leases)...)
+		validationErrors = append(validationErrors, validateRegistryVersion(context.addField("registry_version"), testConfig.RegistryVersion)...)
 		for i, s := range testConfig.Pre {
 			validationErrors = append(validationErrors, validateLiteralTestStep(context.addField("pre").addIndex(i), testStagePre, s, claimRelease)...)
 		}
@@ -461,6 +477,19 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 		for i, s := range testConfig.Post {
 			validationErrors = append(validationErrors, validateLiteralTestStep(context.addField("post").addIndex(i), testStagePost, s, claimRelease)...)
 		}
+		for _, phase := range []struct {
+			field string
+			steps []api.LiteralTestStep
+		}{{field: "pre", steps: testConfig.Pre}, {field: "test", steps: testConfig.Test}, {field: "post", steps: testConfig.Post}} {
+			groups := make([]string, 0, len(phase.steps))
+			for i, s := range phase.steps {
+				if s.When != nil && !s.When.RuntimeOnly() {
+					validationErrors = append(validationErrors, context.addField(phase.field).addIndex(i).addField("when").errorf("only `outcome` can be set in conditions of resolved tests"))
+				}
+				groups = append(groups, s.ParallelGroup)
+			}
+			validationErrors = append(validationErrors, validateParallelGroups(context.addField(phase.field), groups)...)
+		}
 	}
 	if typeCount == 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("%s has no type, you may want to specify 'container' for a container based test", fieldRoot))
@@ -479,9 +508,17 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 }
 
 func validateTestSteps(context *context, stage testStage, steps []api.TestStep, claimRelease *api.ClaimRelease) (ret []error) {
+	groups := make([]string, 0, len(steps))
+	for _, s := range steps {
+		groups = append(groups, s.ParallelGroup)
+	}
+	ret = append(ret, validateParallelGroups(context, groups)...)
 	for i, s := range steps {
 		contextI := context.addIndex(i)
 		ret = append(ret, validateTestStep(contextI, s)...)
+		if s.When != nil {
+			ret = append(ret, validateStepCondition(contextI.addField("when"), *s.When)...)
+		}
 		if s.LiteralTestStep != nil {
 			ret = append(ret, validateLiteralTestStep(contextI, stage, *s.LiteralTestStep, claimRelease)...)
 		}
@@ -577,6 +614,7 @@ func validateLiteralTestStep(context *context, stage testStage, step api.Literal
 
 	ret = append(ret, validateResourceRequirements(string(context.field)+".resources", step.Resources)...)
 	ret = append(ret, validateCredentials(string(context.field), step.Credentials)...)
+	ret = append(ret, validateParameterTypes(context.addField("env"), step.Environment)...)
 	if context.env != nil {
 		if err := validateParameters(context, step.Environment); err != nil {
 			ret = append(ret, err)
@@ -584,6 +622,12 @@ func validateLiteralTestStep(context *context, stage testStage, step api.Literal
 	}
 	ret = append(ret, validateDependencies(string(context.field), step.Dependencies)...)
 	ret = append(ret, validateLeases(context.addField("leases"), step.Leases)...)
+	if step.Retry != nil {
+		ret = append(ret, validateRetryPolicy(context.addField("retry"), *step.Retry)...)
+	}
+	if step.When != nil {
+		ret = append(ret, validateStepCondition(context.addField("when"), *step.When)...)
+	}
 	switch stage {
 	case testStagePre, testStageTest:
 		if step.OptionalOnSuccess != nil {
@@ -650,20 +694,73 @@ func validateCredentials(fieldRoot string, credentials []api.CredentialReference
 
 func validateParameters(context *context, params []api.StepParameter) error {
 	var missing []string
+	var invalid []string
 	for _, param := range params {
-		if param.Default != nil {
+		if value, ok := context.env[param.Name]; ok {
+			if err := param.ValidateValue(value); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %v", param.Name, err))
+			}
 			continue
 		}
-		if _, ok := context.env[param.Name]; !ok {
+		if param.Default == nil {
 			missing = append(missing, param.Name)
 		}
 	}
+	if invalid != nil {
+		return context.errorf("invalid parameter(s): %s", strings.Join(invalid, "; "))
+	}
 	if missing != nil {
 		return context.errorf("unresolved parameter(s): %s", missing)
 	}
 	return nil
 }
 
+// validateParameterTypes ensures typed parameters are well-defined and that
+// their defaults are valid. In resolved configurations, the defaults hold the
+// values set for the test.
+func validateParameterTypes(context *context, params []api.StepParameter) (ret []error) {
+	for i, param := range params {
+		context := context.addIndex(i)
+		if param.Type == "" {
+			if len(param.Values) > 0 || param.Pattern != "" {
+				ret = append(ret, context.errorf("`values` and `pattern` require the `type` of %s to be set", param.Name))
+			}
+			continue
+		}
+		valid := false
+		for _, t := range api.ValidStepParameterTypes {
+			valid = valid || param.Type == t
+		}
+		if !valid {
+			ret = append(ret, context.errorf("unknown type %q for %s, expected one of %v", param.Type, param.Name, api.ValidStepParameterTypes))
+			continue
+		}
+		if (param.Type == api.StepParameterTypeEnum) != (len(param.Values) > 0) {
+			ret = append(ret, context.errorf("`values` must be set for %s if and only if its type is %s", param.Name, api.StepParameterTypeEnum))
+			continue
+		}
+		if param.Type == api.StepParameterTypePattern {
+			if param.Pattern == "" {
+				ret = append(ret, context.errorf("`pattern` must be set for %s if its type is %s", param.Name, api.StepParameterTypePattern))
+				continue
+			}
+			if _, err := regexp.Compile(param.Pattern); err != nil {
+				ret = append(ret, context.errorf("invalid pattern for %s: %v", param.Name, err))
+				continue
+			}
+		} else if param.Pattern != "" {
+			ret = append(ret, context.errorf("`pattern` may only be set for %s if its type is %s", param.Name, api.StepParameterTypePattern))
+			continue
+		}
+		if param.Default != nil {
+			if err := param.ValidateValue(*param.Default); err != nil {
+				ret = append(ret, context.errorf("invalid value for %s: %v", param.Name, err))
+			}
+		}
+	}
+	return ret
+}
+
 func validateDependencies(fieldRoot string, dependencies []api.StepDependency) []error {
 	var errs []error
 	env := sets.NewString()
@@ -695,6 +792,59 @@ func validateDNSConfig(fieldRoot string, dnsConfig []api.StepDNSConfig) (ret []e
 	return errs
 }
 
+func validateRetryPolicy(context *context, retry api.RetryPolicy) (ret []error) {
+	if retry.Attempts < 1 {
+		ret = append(ret, context.addField("attempts").errorf("must be at least 1"))
+	}
+	if retry.Attempts > api.MaxRetryAttempts {
+		ret = append(ret, context.addField("attempts").errorf("must be at most %d", api.MaxRetryAttempts))
+	}
+	if retry.Backoff != nil && retry.Backoff.Duration < 0 {
+		ret = append(ret, context.addField("backoff").errorf("cannot be negative"))
+	}
+	if retry.LogPattern != "" {
+		if _, err := regexp.Compile(retry.LogPattern); err != nil {
+			ret = append(ret, context.addField("log_pattern").errorf("invalid regular expression: %v", err))
+		}
+	}
+	return
+}
+
+func validateStepCondition(context *context, condition api.StepCondition) (ret []error) {
+	for i, p := range condition.ClusterProfiles {
+		ret = append(ret, validateClusterProfile(string(context.addField("cluster_profiles").addIndex(i).field), p)...)
+	}
+	for i, env := range condition.Env {
+		if env.Name == "" {
+			ret = append(ret, context.addField("env").addIndex(i).errorf("`name` is required"))
+		}
+		if len(env.Values) == 0 {
+			ret = append(ret, context.addField("env").addIndex(i).errorf("`values` cannot be empty"))
+		}
+	}
+	switch condition.Outcome {
+	case "", api.StepOutcomeSuccess, api.StepOutcomeFailure:
+	default:
+		ret = append(ret, context.addField("outcome").errorf("must be %q or %q", api.StepOutcomeSuccess, api.StepOutcomeFailure))
+	}
+	return
+}
+
+// validateParallelGroups ensures the steps of each parallel group in a phase
+// are consecutive, given the group of every step in the phase.
+func validateParallelGroups(context *context, groups []string) (ret []error) {
+	done := sets.NewString()
+	for i, group := range groups {
+		if i > 0 && groups[i-1] != group {
+			done.Insert(groups[i-1])
+		}
+		if group != "" && done.Has(group) {
+			ret = append(ret, context.addIndex(i).addField("parallel_group").errorf("steps in parallel group %q must be consecutive", group))
+		}
+	}
+	return
+}
+
 func validateLeases(context *context, leases []api.StepLease) (ret []error) {
 	for i, l := range leases {
 		if l.ResourceType == "" {
@@ -712,3 +862,13 @@ func validateLeases(context *context, leases []api.StepLease) (ret []error) {
 	}
 	return
 }
+
+func validateRegistryVersion(context *context, version string) []error {
+	if version == "" {
+		return nil
+	}
+	if err := registry.ValidateVersion(version); err != nil {
+		return []error{context.errorf("%v", err)}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/utils/diff"
+	utilpointer "k8s.io/utils/pointer"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/testhelper"
@@ -516,6 +518,7 @@ func TestValidateTestSteps(t *testing.T) {
 	// string pointers in golang are annoying
 	myReference := "my-reference"
 	asReference := "as"
+	myChain := "my-chain"
 	yes := true
 	defaultDuration := &prowv1.Duration{Duration: 1 * time.Minute}
 	for _, tc := range []struct {
@@ -866,6 +869,111 @@ func TestValidateTestSteps(t *testing.T) {
 				Resources: resources},
 		}},
 		clusterClaim: api.ClaimRelease{ReleaseName: "myclaim-as", OverrideName: "myclaim"},
+	}, {
+		name: "valid retry policy",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Retry: &api.RetryPolicy{
+					Attempts:   3,
+					Backoff:    defaultDuration,
+					ExitCodes:  []int32{1},
+					LogPattern: "Throttling: Rate exceeded",
+				},
+			},
+		}},
+	}, {
+		name: "invalid retry policy",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Retry: &api.RetryPolicy{
+					Backoff:    &prowv1.Duration{Duration: -time.Minute},
+					LogPattern: "(unclosed",
+				},
+			},
+		}},
+		errs: []error{
+			errors.New("test[0].retry.attempts: must be at least 1"),
+			errors.New("test[0].retry.backoff: cannot be negative"),
+			errors.New("test[0].retry.log_pattern: invalid regular expression: error parsing regexp: missing closing ): `(unclosed`"),
+		},
+	}, {
+		name: "too many retry attempts",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				Retry:     &api.RetryPolicy{Attempts: 100},
+			},
+		}},
+		errs: []error{
+			errors.New("test[0].retry.attempts: must be at most 10"),
+		},
+	}, {
+		name: "valid conditions",
+		steps: []api.TestStep{{
+			Reference: &myReference,
+			When: &api.StepCondition{
+				ClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS},
+				Env:             []api.EnvCondition{{Name: "UPGRADE", Values: []string{"true"}}},
+			},
+		}, {
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				When:      &api.StepCondition{Outcome: api.StepOutcomeFailure},
+			},
+		}},
+	}, {
+		name: "invalid conditions",
+		steps: []api.TestStep{{
+			Reference: &myReference,
+			When: &api.StepCondition{
+				ClusterProfiles: []api.ClusterProfile{"moon"},
+				Env:             []api.EnvCondition{{Values: []string{"true"}}, {Name: "UPGRADE"}},
+			},
+		}, {
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				When:      &api.StepCondition{Outcome: "always"},
+			},
+		}},
+		errs: []error{
+			errors.New(`test[0].when.cluster_profiles[0]: invalid cluster profile "moon"`),
+			errors.New("test[0].when.env[0]: `name` is required"),
+			errors.New("test[0].when.env[1]: `values` cannot be empty"),
+			errors.New(`test[1].when.outcome: must be "success" or "failure"`),
+		},
+	}, {
+		name: "consecutive parallel group",
+		steps: []api.TestStep{
+			{Reference: &myReference, ParallelGroup: "group"},
+			{Reference: &asReference, ParallelGroup: "group"},
+		},
+	}, {
+		name: "parallel group is not consecutive",
+		steps: []api.TestStep{
+			{Reference: &myReference, ParallelGroup: "group"},
+			{Reference: &asReference},
+			{Chain: &myChain, ParallelGroup: "group"},
+		},
+		errs: []error{
+			errors.New(`test[2].parallel_group: steps in parallel group "group" must be consecutive`),
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			context := newContext("test", nil, tc.releases)
@@ -942,6 +1050,39 @@ func TestValidateParameters(t *testing.T) {
 		params: []api.StepParameter{{Name: "TEST0"}, {Name: "TEST1"}},
 		env:    api.TestEnvironment{"TEST0": "test0"},
 		err:    []error{errors.New("test: unresolved parameter(s): [TEST1]")},
+	}, {
+		name:   "typed parameter, valid value provided",
+		params: []api.StepParameter{{Name: "FIPS_ENABLED", Type: api.StepParameterTypeBool}},
+		env:    api.TestEnvironment{"FIPS_ENABLED": "true"},
+	}, {
+		name:   "typed parameter, invalid value provided",
+		params: []api.StepParameter{{Name: "FIPS_ENABLED", Type: api.StepParameterTypeBool}},
+		env:    api.TestEnvironment{"FIPS_ENABLED": "ture"},
+		err:    []error{errors.New(`test: invalid parameter(s): FIPS_ENABLED: value "ture" is not a boolean, expected true or false`)},
+	}, {
+		name:   "typed parameter, empty value provided",
+		params: []api.StepParameter{{Name: "COUNT", Type: api.StepParameterTypeInt}},
+		env:    api.TestEnvironment{"COUNT": ""},
+	}, {
+		name:   "enum parameter with invalid default",
+		params: []api.StepParameter{{Name: "TEST", Type: api.StepParameterTypeEnum, Values: []string{"a", "b"}, Default: &defaultStr}},
+		err:    []error{errors.New(`test.env[0]: invalid value for TEST: value "default" is not one of a, b`)},
+	}, {
+		name:   "enum parameter without values",
+		params: []api.StepParameter{{Name: "TEST", Type: api.StepParameterTypeEnum, Default: &defaultStr}},
+		err:    []error{errors.New("test.env[0]: `values` must be set for TEST if and only if its type is enum")},
+	}, {
+		name:   "pattern parameter with invalid pattern",
+		params: []api.StepParameter{{Name: "TEST", Type: api.StepParameterTypePattern, Pattern: "(", Default: &defaultStr}},
+		err:    []error{errors.New("test.env[0]: invalid pattern for TEST: error parsing regexp: missing closing ): `(`")},
+	}, {
+		name:   "unknown type",
+		params: []api.StepParameter{{Name: "TEST", Type: "float", Default: &defaultStr}},
+		err:    []error{errors.New("test.env[0]: unknown type \"float\" for TEST, expected one of [enum bool int pattern duration]")},
+	}, {
+		name:   "values without type",
+		params: []api.StepParameter{{Name: "TEST", Values: []string{"a"}, Default: &defaultStr}},
+		err:    []error{errors.New("test.env[0]: `values` and `pattern` require the `type` of TEST to be set")},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := validateLiteralTestStep(newContext("test", tc.env, tc.releases), testStageTest, api.LiteralTestStep{
@@ -961,6 +1102,36 @@ func TestValidateParameters(t *testing.T) {
 	}
 }
 
+func TestIsValidChain(t *testing.T) {
+	valid, invalid := "a", "c"
+	for _, tc := range []struct {
+		name   string
+		params []api.StepParameter
+		err    []error
+	}{{
+		name:   "untyped parameter",
+		params: []api.StepParameter{{Name: "TEST", Default: &invalid}},
+	}, {
+		name:   "typed parameter with valid default",
+		params: []api.StepParameter{{Name: "TEST", Type: api.StepParameterTypeEnum, Values: []string{"a", "b"}, Default: &valid}},
+	}, {
+		name:   "typed parameter with invalid default",
+		params: []api.StepParameter{{Name: "TEST", Type: api.StepParameterTypeEnum, Values: []string{"a", "b"}, Default: &invalid}},
+		err:    []error{errors.New(`chain.env[0]: invalid value for TEST: value "c" is not one of a, b`)},
+	}, {
+		name:   "pattern parameter with invalid default",
+		params: []api.StepParameter{{Name: "TEST", Type: api.StepParameterTypePattern, Pattern: "[ab]", Default: &invalid}},
+		err:    []error{errors.New(`chain.env[0]: invalid value for TEST: value "c" does not match "[ab]"`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := IsValidChain(api.RegistryChain{As: "chain", Environment: tc.params})
+			if diff := diff.ObjectReflectDiff(err, tc.err); diff != "<no diffs>" {
+				t.Errorf("incorrect error: %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateCredentials(t *testing.T) {
 	var testCases = []struct {
 		name   string
@@ -1213,6 +1384,49 @@ func TestValidateLeases(t *testing.T) {
 	}
 }
 
+func TestValidateResolvedConditions(t *testing.T) {
+	step := func(when *api.StepCondition) api.LiteralTestStep {
+		return api.LiteralTestStep{
+			As:       "as",
+			From:     "from",
+			Commands: "commands",
+			Resources: api.ResourceRequirements{
+				Requests: api.ResourceList{"cpu": "1"},
+				Limits:   api.ResourceList{"memory": "1m"},
+			},
+			When: when,
+		}
+	}
+	for _, tc := range []struct {
+		name string
+		test api.MultiStageTestConfigurationLiteral
+		err  []error
+	}{{
+		name: "outcome is kept in resolved tests",
+		test: api.MultiStageTestConfigurationLiteral{
+			Post: []api.LiteralTestStep{step(&api.StepCondition{Outcome: api.StepOutcomeFailure})},
+		},
+	}, {
+		name: "conditions evaluated at resolution are not allowed",
+		test: api.MultiStageTestConfigurationLiteral{
+			Test: []api.LiteralTestStep{step(&api.StepCondition{ClusterProfiles: []api.ClusterProfile{api.ClusterProfileAWS}})},
+		},
+		err: []error{
+			errors.New("test.test[0].when: only `outcome` can be set in conditions of resolved tests"),
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			test := api.TestStepConfiguration{
+				MultiStageTestConfigurationLiteral: &tc.test,
+			}
+			err := validateTestConfigurationType("test", test, nil, nil, true)
+			if diff := diff.ObjectReflectDiff(tc.err, err); diff != "<no diffs>" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateTestConfigurationType(t *testing.T) {
 	for _, tc := range []struct {
 		name     string
@@ -1333,6 +1547,25 @@ func TestValidateTestConfigurationType(t *testing.T) {
 			},
 			expected: []error{fmt.Errorf("test.cluster is not a vailid cluster: bar")},
 		},
+		{
+			name: "valid registry version",
+			test: api.TestStepConfiguration{
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Workflow:        utilpointer.StringPtr("workflow"),
+					RegistryVersion: strings.Repeat("a", 64),
+				},
+			},
+		},
+		{
+			name: "invalid registry version",
+			test: api.TestStepConfiguration{
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Workflow:        utilpointer.StringPtr("workflow"),
+					RegistryVersion: "latest",
+				},
+			},
+			expected: []error{fmt.Errorf(`test.registry_version: invalid registry version "latest": must be a hex-encoded SHA256 hash`)},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			actual := validateTestConfigurationType("test", tc.test, nil, nil, false)
@@ -25,6 +25,8 @@ type subgraph struct {
 	lastNode    int
 	hasSGParent bool
 	linkable    bool
+	// parallel is set for the subgraphs of parallel step groups
+	parallel bool
 }
 
 type edge struct {
@@ -53,6 +55,12 @@ const (
 	bootstrap413monospace = "fontname=\"SFMono-Regular,Menlo,Monaco,Consolas,'Liberation Mono','Courier New',monospace\""
 )
 
+// element is a node or subgraph that can be connected with an edge
+type element struct {
+	index    int
+	elemType edgeType
+}
+
 func addSubgraph(mainGraph graph, name string, root []api.TestStep, chains registry.ChainByName, isChild, linkable, isRoot bool) (graph, int) {
 	sg := subgraph{
 		label:       name,
@@ -60,8 +68,21 @@ func addSubgraph(mainGraph graph, name string, root []api.TestStep, chains regis
 		hasSGParent: isChild,
 		linkable:    linkable,
 	}
-	lastIndex := -1
-	lastType := subgraphType
+	// steps in a parallel group fork from the elements before the group and
+	// join into the element after it, so more than one element can be last
+	var last []element
+	var group *subgraph
+	var groupName string
+	var beforeGroup, groupMembers []element
+	closeGroup := func() {
+		if group == nil {
+			return
+		}
+		mainGraph.subgraphs = append(mainGraph.subgraphs, *group)
+		sg.subgraphs = append(sg.subgraphs, len(mainGraph.subgraphs)-1)
+		last = groupMembers
+		group, groupName, beforeGroup, groupMembers = nil, "", nil, nil
+	}
 	for _, step := range root {
 		var currNode *node
 		currSG := -1
@@ -70,44 +91,70 @@ func addSubgraph(mainGraph graph, name string, root []api.TestStep, chains regis
 		} else if step.Reference != nil {
 			currNode = &node{label: *step.Reference, linkable: true}
 		} else if step.Chain != nil {
-			mainGraph, currSG = addSubgraph(mainGraph, *step.Chain, chains[*step.Chain].Steps, chains, !isRoot, true, false)
+			mainGraph, currSG = addSubgraph(mainGraph, *step.Chain, chains[*step.Chain].Steps, chains, !isRoot || step.ParallelGroup != "", true, false)
+		}
+		if step.ParallelGroup == "" || step.ParallelGroup != groupName {
+			closeGroup()
+		}
+		if step.ParallelGroup != "" && group == nil {
+			group = &subgraph{
+				label:       "parallel: " + step.ParallelGroup,
+				firstNode:   -1,
+				hasSGParent: !isRoot,
+				parallel:    true,
+			}
+			groupName = step.ParallelGroup
+			beforeGroup = last
+		}
+		// the subgraph holding the new element
+		parent := &sg
+		if group != nil {
+			parent = group
 		}
 		// create new edge
-		var newIndex int
-		var newType edgeType
+		var curr element
+		var firstNode, lastNode int
 		if currNode != nil {
-			newType = nodeType
 			mainGraph.nodes = append(mainGraph.nodes, *currNode)
-			newIndex = len(mainGraph.nodes) - 1
-			if sg.firstNode == -1 {
-				sg.firstNode = newIndex
-			}
-			sg.nodes = append(sg.nodes, newIndex)
+			curr = element{index: len(mainGraph.nodes) - 1, elemType: nodeType}
+			firstNode, lastNode = curr.index, curr.index
+			parent.nodes = append(parent.nodes, curr.index)
 		} else if currSG != -1 {
-			newType = subgraphType
-			newIndex = currSG
-			if sg.firstNode == -1 {
-				sg.firstNode = mainGraph.subgraphs[newIndex].firstNode
-			}
-			sg.subgraphs = append(sg.subgraphs, newIndex)
+			curr = element{index: currSG, elemType: subgraphType}
+			firstNode, lastNode = mainGraph.subgraphs[currSG].firstNode, mainGraph.subgraphs[currSG].lastNode
+			parent.subgraphs = append(parent.subgraphs, currSG)
 		}
-		if lastIndex != -1 {
-			newEdge := edge{
-				dst:     newIndex,
-				dstType: newType,
-			}
-			newEdge.srcType = lastType
-			newEdge.src = lastIndex
-			mainGraph.edges = append(mainGraph.edges, newEdge)
+		if parent.firstNode == -1 {
+			parent.firstNode = firstNode
+		}
+		parent.lastNode = lastNode
+		if sg.firstNode == -1 {
+			sg.firstNode = firstNode
+		}
+		sources := last
+		if group != nil {
+			sources = beforeGroup
+		}
+		for _, src := range sources {
+			mainGraph.edges = append(mainGraph.edges, edge{
+				src:     src.index,
+				srcType: src.elemType,
+				dst:     curr.index,
+				dstType: curr.elemType,
+			})
+		}
+		if group != nil {
+			groupMembers = append(groupMembers, curr)
+		} else {
+			last = []element{curr}
 		}
-		lastIndex = newIndex
-		lastType = newType
 	}
+	closeGroup()
 	// this is used to identify a node that can be linked from is this sg is a src for an edge
-	if lastType == nodeType {
-		sg.lastNode = lastIndex
+	if final := last[len(last)-1]; final.elemType == nodeType {
+		sg.lastNode = final.index
 	} else {
-		sg.lastNode = mainGraph.subgraphs[lastIndex].lastNode
+		sg.lastNode = mainGraph.subgraphs[final.index].lastNode
 	}
 	if !isRoot {
 		mainGraph.subgraphs = append(mainGraph.subgraphs, sg)
@@ -202,6 +249,9 @@ func writeSubgraph(mainGraph graph, sg subgraph, index int, indentPrefix string)
 	indentPrefix = fmt.Sprint(indentPrefix, "\t")
 	builder.WriteString(fmt.Sprintf("%slabel=\"%s\";\n", indentPrefix, html.EscapeString(sg.label)))
 	builder.WriteString(fmt.Sprint(indentPrefix, "labeljust=\"l\";\n"))
+	if sg.parallel {
+		builder.WriteString(fmt.Sprint(indentPrefix, "style=dashed;\n"))
+	}
 	if sg.linkable {
 		builder.WriteString(fmt.Sprintf("%shref=\"/%s/%s\";\n", indentPrefix, "chain", html.EscapeString(sg.label)))
 		builder.WriteString(fmt.Sprint(indentPrefix, bootstrap413monospace, ";\n"))
@@ -5,7 +5,9 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/diff"
 
+	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry"
 )
 
 const ipiWorkflow = `digraph Webreg {
@@ -90,7 +92,7 @@ const deprovisionChain = `digraph Webreg {
 }`
 
 func TestChainDotFile(t *testing.T) {
-	_, chains, _, _, _, _, err := load.Registry("../../test/multistage-registry/registry", false)
+	_, chains, _, _, _, _, _, err := load.Registry("../../test/multistage-registry/registry", false)
 	if err != nil {
 		t.Fatalf("Failed to load registry: %v", err)
 	}
@@ -105,7 +107,7 @@ func TestChainDotFile(t *testing.T) {
 }
 
 func TestWorkflowDotFile(t *testing.T) {
-	_, chains, workflows, _, _, _, err := load.Registry("../../test/multistage-registry/registry", false)
+	_, chains, workflows, _, _, _, _, err := load.Registry("../../test/multistage-registry/registry", false)
 	if err != nil {
 		t.Fatalf("Failed to load registry: %v", err)
 	}
@@ -114,3 +116,56 @@ func TestWorkflowDotFile(t *testing.T) {
 		t.Errorf("Generated dot file for ipi differs from expected: %s", diff.StringDiff(ipiWorkflow, ipi))
 	}
 }
+
+const parallelChain = `digraph Webreg {
+	compound=true;
+	color=blue;
+	fontname="-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,'Helvetica Neue',Arial,sans-serif,'Apple Color Emoji','Segoe UI Emoji','Segoe UI Symbol','Noto Color Emoji'";
+	node[shape=rectangle fontname="SFMono-Regular,Menlo,Monaco,Consolas,'Liberation Mono','Courier New',monospace"];
+	rankdir=TB;
+	label="Chain &#34;parallel&#34;";
+
+	0 [label="setup" href="/reference/setup"];
+	1 [label="suite" href="/reference/suite"];
+	2 [label="suite" href="/reference/suite"];
+	3 [label="teardown" href="/reference/teardown"];
+	4 [label="teardown" href="/reference/teardown"];
+
+	0 -> 1 ;
+	2 -> 3 ;
+	0 -> 2 [lhead=cluster_0 minlen=2];
+	1 -> 4 ;
+	3 -> 4 [ltail=cluster_0];
+
+	subgraph cluster_1 {
+		label="parallel: conformance";
+		labeljust="l";
+		style=dashed;
+		fontname="-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,'Helvetica Neue',Arial,sans-serif,'Apple Color Emoji','Segoe UI Emoji','Segoe UI Symbol','Noto Color Emoji'";
+		1;
+		subgraph cluster_0 {
+			label="suites";
+			labeljust="l";
+			href="/chain/suites";
+			fontname="SFMono-Regular,Menlo,Monaco,Consolas,'Liberation Mono','Courier New',monospace";
+			2;
+			3;
+		}
+	}
+}`
+
+func TestParallelGroupDotFile(t *testing.T) {
+	setup, suite, teardown, suites := "setup", "suite", "teardown", "suites"
+	chains := registry.ChainByName{
+		suites: {As: suites, Steps: []api.TestStep{{Reference: &suite}, {Reference: &teardown}}},
+		"parallel": {As: "parallel", Steps: []api.TestStep{
+			{Reference: &setup},
+			{Reference: &suite, ParallelGroup: "conformance"},
+			{Chain: &suites, ParallelGroup: "conformance"},
+			{Reference: &teardown},
+		}},
+	}
+	if dot := chainDotFile("parallel", chains); dot != parallelChain {
+		t.Errorf("Generated dot file for parallel differs from expected: %s", diff.StringDiff(parallelChain, dot))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"net/http"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -170,6 +171,7 @@ const mainPage = `
 
 const referencePage = `
 <h2 id="title"><a href="#title">Step:</a> <nobr style="font-family:monospace">{{ .Reference.As }}</nobr></h2>
+{{ template "deprecationNotice" .Reference.Deprecation }}
 <p id="documentation">{{ .Reference.Documentation }}</p>
 <h3 id="image"><a href="#image">Container image used for this step:</a> <span style="font-family:monospace">{{ fromImage .Reference.From .Reference.FromImage }}</span></h3>
 <p id="image">{{ fromImageDescription .Reference.From .Reference.FromImage }}<d/p>
@@ -179,12 +181,15 @@ const referencePage = `
 {{ syntaxedSource .Reference.Commands }}
 <h3 id="properties"><a href="#properties">Properties</a></h3>
 {{ template "referenceProperties" .Reference }}
+<h3 id="usage"><a href="#usage">Usage</a></h3>
+<p><a href="/impact/reference/{{ .Reference.As }}">Workflows, chains and jobs executing this step</a></p>
 <h3 id="github"><p><a href="#github">GitHub Link:</a></h3></p>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 `
 
 const chainPage = `
 <h2 id="title"><a href="#title">Chain:</a> <nobr style="font-family:monospace">{{ .Chain.As }}</nobr></h2>
+{{ template "deprecationNotice" .Chain.Deprecation }}
 <p id="documentation">{{ .Chain.Documentation }}</p>
 <h3 id="steps" title="Step run by the chain, in runtime order"><a href="#steps">Steps</a></h3>
 {{ template "stepTable" .Chain.Steps}}
@@ -195,6 +200,8 @@ const chainPage = `
 {{ template "refEnvironment" .Chain.As }}
 <h3 id="graph" title="Visual representation of steps run by this chain"><a href="#graph">Step Graph</a></h3>
 {{ chainGraph .Chain.As }}
+<h3 id="usage"><a href="#usage">Usage</a></h3>
+<p><a href="/impact/chain/{{ .Chain.As }}">Workflows, chains and jobs executing this chain</a></p>
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 `
@@ -203,6 +210,7 @@ const chainPage = `
 const workflowJobPage = `
 {{ $type := .Workflow.Type }}
 <h2 id="title"><a href="#title">{{ $type }}:</a> <nobr style="font-family:monospace">{{ .Workflow.As }}</nobr></h2>
+{{ template "deprecationNotice" .Workflow.Deprecation }}
 {{ if .Workflow.Documentation }}
 	<p id="documentation">{{ .Workflow.Documentation }}</p>
 {{ end }}
@@ -223,16 +231,65 @@ const workflowJobPage = `
 <h3 id="graph" title="Visual representation of steps run by this {{ toLower $type }}"><a href="#graph">Step Graph</a></h3>
 {{ workflowGraph .Workflow.As .Workflow.Type }}
 {{ if eq $type "Workflow" }}
+<h3 id="usage"><a href="#usage">Usage</a></h3>
+<p><a href="/impact/workflow/{{ .Workflow.As }}">Jobs executing this workflow</a></p>
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 {{ end }}
 `
 
+const impactPage = `
+<h2 id="title"><a href="#title">Usage of {{ .Type }}:</a> <nobr><a href="/{{ .Type }}/{{ .Name }}" style="font-family:monospace">{{ .Name }}</a></nobr></h2>
+<p>{{ len .Tests }} tests execute this {{ .Type }}.</p>
+{{ if .Workflows }}
+<h3 id="workflows" title="Workflows that execute this component through their steps"><a href="#workflows">Workflows</a></h3>
+<ul>
+{{ range .Workflows }}<li><nobr><a href="/workflow/{{ . }}" style="font-family:monospace">{{ . }}</a></nobr></li>
+{{ end }}</ul>
+{{ end }}
+{{ if .Chains }}
+<h3 id="chains" title="Chains that execute this component"><a href="#chains">Chains</a></h3>
+<ul>
+{{ range .Chains }}<li><nobr><a href="/chain/{{ . }}" style="font-family:monospace">{{ . }}</a></nobr></li>
+{{ end }}</ul>
+{{ end }}
+{{ if .Repos }}
+<h3 id="repos" title="Number of tests executing this component in each repository"><a href="#repos">Repositories</a></h3>
+<table class="table">
+	<thead><tr><th class="info">Repository</th><th class="info">Tests</th></tr></thead>
+	<tbody>
+	{{ range .Repos }}<tr><td>{{ .Name }}</td><td>{{ .Count }}</td></tr>
+	{{ end }}</tbody>
+</table>
+<h3 id="jobs" title="Prow jobs generated for the tests executing this component"><a href="#jobs">Jobs</a></h3>
+<table class="table">
+	<thead><tr><th class="info">Job</th></tr></thead>
+	<tbody>
+	{{ range .Tests }}<tr><td><a href="/job?org={{ .Org }}&repo={{ .Repo }}&branch={{ .Branch }}{{ if .Variant }}&variant={{ .Variant }}{{ end }}&test={{ .Test }}" style="font-family:monospace">{{ .JobName }}</a></td></tr>
+	{{ end }}</tbody>
+</table>
+{{ end }}
+`
+
 const jobSearchPage = `
 {{ template "jobTable" . }}
 `
 
 const templateDefinitions = `
+{{ define "deprecationNotice" }}
+	{{ if . }}
+	<div class="alert alert-warning" role="alert" id="deprecation">
+		<b>Deprecated:</b> {{ .Message }}
+		{{ if .Replacement }}<br>Use <span style="font-family:monospace">{{ .Replacement }}</span> instead.{{ end }}
+		{{ if .RemovalDate }}<br>This component will be removed after {{ .RemovalDate }}.{{ end }}
+	</div>
+	{{ end }}
+{{ end }}
+
+{{ define "deprecatedBadge" }}
+	{{ with . }}<span class="badge badge-warning" title="{{ .Message }}">deprecated</span>{{ end }}
+{{ end }}
+
 {{ define "nameWithLink" }}
 	<nobr><a href="/{{ .Type }}/{{ .Name }}" style="font-family:monospace">{{ .Name }}</a></nobr>
 {{ end }}
@@ -303,6 +360,20 @@ const templateDefinitions = `
       <td>This step's failure will not cause whole job to fail if the step is run in <span style="font-family:monospace">post</span> phase.</td>
     </tr>
   {{ end }}
+  {{ if .Retry }}
+    <tr>
+      <td>Retry policy</td>
+      <td>{{ .Retry.Attempts }} attempts{{ if .Retry.Backoff }}, {{ .Retry.Backoff.String }} backoff{{ end }}</td>
+      <td>The step is executed again when it fails{{ if .Retry.ExitCodes }} with one of the exit codes <span style="font-family:monospace">{{ range $i, $code := .Retry.ExitCodes }}{{ if $i }}, {{ end }}{{ $code }}{{ end }}</span>{{ end }}{{ if .Retry.LogPattern }}{{ if .Retry.ExitCodes }} and{{ end }} with output matching <span style="font-family:monospace">{{ .Retry.LogPattern }}</span>{{ end }}. The wait time between attempts doubles with every retry.</td>
+    </tr>
+  {{ end }}
+  {{ if .When }}
+    <tr>
+      <td>Condition</td>
+      <td>{{ template "stepCondition" .When }}</td>
+      <td>The step is only executed when all of the conditions are met.</td>
+    </tr>
+  {{ end }}
   {{ if .Cli }}
     <tr>
       <td>Inject <span style="font-family:monospace">oc</span> CLI<sup>[<a href="https://docs.ci.openshift.org/docs/architecture/step-registry/#sharing-data-between-steps">?</a>]</sup></td>
@@ -427,6 +498,9 @@ const templateDefinitions = `
          (default: <span style="font-family:monospace">{{ $env.Default }}</span>)
        {{ end }}
        {{ end }}
+       {{ with $env.AllowedValues }}
+         <br>Allowed values: <span style="font-family:monospace">{{ . }}</span>
+       {{ end }}
      </td>
    </tr>
    {{ end }}
@@ -448,6 +522,18 @@ const templateDefinitions = `
 {{ end }}
 {{ end }}
 
+{{ define "stepCondition" }}
+	{{- $parts := 0 }}
+	{{- if .ClusterProfiles }}cluster profile is {{ range $i, $profile := .ClusterProfiles }}{{ if $i }} or {{ end }}<span style="font-family:monospace">{{ $profile }}</span>{{ end }}{{ $parts = 1 }}{{ end }}
+	{{- range $env := .Env }}{{ if $parts }}, {{ end }}<span style="font-family:monospace">{{ $env.Name }}</span> is {{ range $i, $value := $env.Values }}{{ if $i }} or {{ end }}<span style="font-family:monospace">"{{ $value }}"</span>{{ end }}{{ $parts = 1 }}{{ end }}
+	{{- if .Outcome }}{{ if $parts }}, {{ end }}previous steps ended in <span style="font-family:monospace">{{ .Outcome }}</span>{{ end }}
+{{- end }}
+
+{{ define "stepAnnotations" }}
+	{{- if .ParallelGroup }}<br><small>runs in parallel group <span style="font-family:monospace">{{ .ParallelGroup }}</span></small>{{ end }}
+	{{- if .When }}<br><small>when {{ template "stepCondition" .When }}</small>{{ end }}
+{{- end }}
+
 {{ define "stepTable" }}
 {{ if not . }}
 	<p>No test steps configured.</p>
@@ -465,9 +551,9 @@ const templateDefinitions = `
 				{{ $nameAndType := testStepNameAndType $step }}
 				{{ $doc := docsForName $nameAndType.Name }}
 				{{ if not $step.LiteralTestStep }}
-					<td>{{ template "nameWithLink" $nameAndType }}</td>
+					<td>{{ template "nameWithLink" $nameAndType }}{{ template "stepAnnotations" $step }}</td>
 				{{ else }}
-					<td>{{ $nameAndType.Name }}</td>
+					<td>{{ $nameAndType.Name }}{{ template "stepAnnotations" $step }}</td>
 				{{ end }}
 				<td>{{ noescape $doc }}</td>
 			</tr>
@@ -499,7 +585,7 @@ const templateDefinitions = `
 		<tbody>
 			{{ range $name, $config := . }}
 				<tr>
-					<td><b>Name:</b> {{ template "nameWithLinkWorkflow" $name }}<p>
+					<td><b>Name:</b> {{ template "nameWithLinkWorkflow" $name }} {{ template "deprecatedBadge" (deprecationFor "workflow" $name) }}<p>
 						<b>Description:</b><br>{{ docsForName $name }}
 					</td>
 					<td>{{ if gt (len $config.Pre) 0 }}<b>Pre:</b>{{ template "stepList" $config.Pre }}{{ end }}
@@ -526,7 +612,7 @@ const templateDefinitions = `
 		<tbody>
 			{{ range $name, $config := . }}
 				<tr>
-					<td>{{ template "nameWithLinkChain" $name }}</td>
+					<td>{{ template "nameWithLinkChain" $name }} {{ template "deprecatedBadge" (deprecationFor "chain" $name) }}</td>
 					<td>{{ docsForName $name }}</td>
 					<td>{{ template "stepList" $config.Steps }}</td>
 				</tr>
@@ -548,7 +634,7 @@ const templateDefinitions = `
 		<tbody>
 			{{ range $name, $config := . }}
 				<tr>
-					<td>{{ template "nameWithLinkReference" $name }}</td>
+					<td>{{ template "nameWithLinkReference" $name }} {{ template "deprecatedBadge" (deprecationFor "reference" $name) }}</td>
 					<td>{{ docsForName $name }}</td>
 				</tr>
 			{{ end }}
@@ -727,6 +813,7 @@ func getBaseTemplate() (*template.Template, error) {
 			// These three are placeholders to be overwritten by the handlers
 			// that actually care about this data (see set{Docs,ChainGraph,WorkflowGraph) functions
 			"docsForName":     func(string) string { return "" },
+			"deprecationFor":  func(_, _ string) *api.Deprecation { return nil },
 			"workflowGraph":   func(_, _ string) string { return "" },
 			"chainGraph":      func(string) string { return "" },
 			"getDependencies": func(string) dependencyData { return dependencyData{} },
@@ -859,6 +946,23 @@ func setDocs(t *template.Template, docs map[string]string) *template.Template {
 		})
 }
 
+func setDeprecations(t *template.Template, deprecations registry.Deprecations) *template.Template {
+	return t.Funcs(
+		template.FuncMap{
+			"deprecationFor": func(typeName, name string) *api.Deprecation {
+				componentType, err := registry.ParseType(typeName)
+				if err != nil {
+					return nil
+				}
+				component, ok := deprecations.For(componentType, name)
+				if !ok {
+					return nil
+				}
+				return &component.Deprecation
+			},
+		})
+}
+
 func setWorkflowGraph(t *template.Template, chains registry.ChainByName, workflows registry.WorkflowByName) *template.Template {
 	return t.Funcs(
 		template.FuncMap{
@@ -1119,6 +1223,7 @@ func mainPageHandler(agent agents.RegistryAgent, templateString string, w http.R
 		return
 	}
 	page = setDocs(page, docs)
+	page = setDeprecations(page, agent.GetDeprecations())
 	page = setWorkflowGraph(page, chains, workflows)
 	page = setChainGraph(page, chains)
 	if page, err = page.Parse(templateString); err != nil {
@@ -1159,6 +1264,9 @@ func WebRegHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent)
 				writeErrorPage(w, errors.New("Invalid path"), http.StatusNotImplemented)
 			}
 			return
+		} else if len(splitURI) == 3 && splitURI[0] == "impact" {
+			impactHandler(regAgent, confAgent, w, req)
+			return
 		} else if len(splitURI) == 2 {
 			switch splitURI[0] {
 			case "reference":
@@ -1390,8 +1498,11 @@ func referenceHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *ht
 				OptionalOnSuccess: refs[name].OptionalOnSuccess,
 				BestEffort:        refs[name].BestEffort,
 				Cli:               refs[name].Cli,
+				Retry:             refs[name].Retry,
+				When:              refs[name].When,
 			},
 			Documentation: docs[name],
+			Deprecation:   deprecationOf(agent, registry.Reference, name),
 		},
 		Metadata: metadata[refMetadataName],
 	}
@@ -1435,6 +1546,7 @@ func chainHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.R
 			As:            name,
 			Documentation: docs[name],
 			Steps:         chains[name].Steps,
+			Deprecation:   deprecationOf(agent, registry.Chain, name),
 		},
 		Metadata: metadata[chainMetadataName],
 	}
@@ -1482,6 +1594,7 @@ func workflowHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *htt
 				As:            name,
 				Documentation: docs[name],
 				Steps:         workflows[name],
+				Deprecation:   deprecationOf(agent, registry.Workflow, name),
 			},
 			Type: workflowType},
 		Metadata: metadata[workflowMetadataName],
@@ -1489,6 +1602,14 @@ func workflowHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *htt
 	writePage(w, "Registry Workflow Help Page", page, workflow)
 }
 
+func deprecationOf(agent agents.RegistryAgent, t registry.Type, name string) *api.Deprecation {
+	component, ok := agent.GetDeprecations().For(t, name)
+	if !ok {
+		return nil
+	}
+	return &component.Deprecation
+}
+
 func findConfigForJob(testName string, config api.ReleaseBuildConfiguration) (api.MultiStageTestConfiguration, error) {
 	for _, test := range config.Tests {
 		if test.As == testName {
@@ -1710,6 +1831,67 @@ func getAllMultiStageTests(confAgent agents.ConfigAgent) *Jobs {
 	return jobs
 }
 
+type repoCount struct {
+	Name  string
+	Count int
+}
+
+// impactHandler lists everything that executes a registry component, for
+// paths like `/impact/<type>/<name>`.
+func impactHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	name := path.Base(req.URL.Path)
+	componentType, err := registry.ParseType(path.Base(path.Dir(req.URL.Path)))
+	if err != nil {
+		writeErrorPage(w, err, http.StatusNotFound)
+		return
+	}
+	refs, chains, workflows, _, _ := regAgent.GetRegistryComponents()
+	graph, err := registry.NewGraph(refs, chains, workflows)
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to build registry graph: %w", err), http.StatusInternalServerError)
+		return
+	}
+	node, ok := graph.Lookup(componentType, name)
+	if !ok {
+		writeErrorPage(w, fmt.Errorf("Could not find %s %s", componentType, name), http.StatusNotFound)
+		return
+	}
+	var configs []api.ReleaseBuildConfiguration
+	for _, byRepo := range confAgent.GetAll() {
+		for _, repoConfigs := range byRepo {
+			configs = append(configs, repoConfigs...)
+		}
+	}
+	usage := registry.UsageOf(node, workflows, configs)
+	data := struct {
+		registry.Usage
+		Repos []repoCount
+	}{Usage: usage}
+	for repo, count := range usage.TestsByRepo {
+		data.Repos = append(data.Repos, repoCount{Name: repo, Count: count})
+	}
+	sort.Slice(data.Repos, func(i, j int) bool {
+		if data.Repos[i].Count == data.Repos[j].Count {
+			return data.Repos[i].Name < data.Repos[j].Name
+		}
+		return data.Repos[i].Count > data.Repos[j].Count
+	})
+
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if page, err = page.Parse(impactPage); err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Registry Component Usage", page, data)
+}
+
 func searchHandler(confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
@@ -424,6 +424,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # be used with rehearsals. Otherwise, the overrides should be passed in as parameters to ci-operator.\n" +
 	"            dependency_overrides:\n" +
 	"                \"\": \"\"\n" +
+	"            # Deprecations lists the deprecated registry components the test uses.\n" +
+	"            deprecations:\n" +
+	"                - # Message explains why the component is deprecated.\n" +
+	"                  message: ' '\n" +
+	"                  name: ' '\n" +
+	"                  # RemovalDate is the date after which the component may be removed,\n" +
+	"                  # formatted as YYYY-MM-DD, optional.\n" +
+	"                  removal_date: ' '\n" +
+	"                  # Replacement names the component to use instead, optional.\n" +
+	"                  replacement: ' '\n" +
+	"                  # Type is the type of the component: reference, chain or workflow.\n" +
+	"                  type: ' '\n" +
 	"            # DnsConfig for step's Pod.\n" +
 	"            dnsConfig:\n" +
 	"                # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -502,6 +514,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      documentation: ' '\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Pattern is a regular expression that values of a parameter of the\n" +
+	"                      # `pattern` type must match in full.\n" +
+	"                      pattern: ' '\n" +
+	"                      # Type restricts the values the parameter accepts, optional. Values of\n" +
+	"                      # untyped parameters are not validated.\n" +
+	"                      type: ' '\n" +
+	"                      # Values lists the values accepted by a parameter of the `enum` type.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -528,6 +549,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelGroup is the name of the group of steps this step is executed\n" +
+	"                  # concurrently with. Steps in a group must be consecutive in a phase, and\n" +
+	"                  # the group fails if any of them fails. Set for the steps of a test when\n" +
+	"                  # it is resolved, registry references cannot set it.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -538,11 +564,42 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry defines if and how this step should be executed again when it fails.\n" +
+	"                  retry:\n" +
+	"                    # Attempts is the maximum number of times the step is executed, including\n" +
+	"                    # the first execution.\n" +
+	"                    attempts: 0\n" +
+	"                    # Backoff is how long to wait before the first retry. The wait time is\n" +
+	"                    # doubled for every subsequent retry, up to MaxRetryBackoff.\n" +
+	"                    backoff: 0s\n" +
+	"                    # ExitCodes limits retries to failures where the step's process exited\n" +
+	"                    # with one of these codes.\n" +
+	"                    exit_codes:\n" +
+	"                        - 0\n" +
+	"                    # LogPattern limits retries to failures where the step's output matches\n" +
+	"                    # this regular expression.\n" +
+	"                    log_pattern: ' '\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"                  # When defines the conditions under which this step is executed. The\n" +
+	"                  # step is always executed if not set.\n" +
+	"                  when:\n" +
+	"                    # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        - \"\"\n" +
+	"                    # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                    env:\n" +
+	"                        - # Name is the name of the parameter.\n" +
+	"                          name: ' '\n" +
+	"                          # Values lists the values that meet the condition. An unset parameter\n" +
+	"                          # has the empty value.\n" +
+	"                          values:\n" +
+	"                            - \"\"\n" +
+	"                    # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                    outcome: ' '\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
 	"                - # As is the name of the LiteralTestStep.\n" +
@@ -588,6 +645,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      documentation: ' '\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Pattern is a regular expression that values of a parameter of the\n" +
+	"                      # `pattern` type must match in full.\n" +
+	"                      pattern: ' '\n" +
+	"                      # Type restricts the values the parameter accepts, optional. Values of\n" +
+	"                      # untyped parameters are not validated.\n" +
+	"                      type: ' '\n" +
+	"                      # Values lists the values accepted by a parameter of the `enum` type.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -614,6 +680,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelGroup is the name of the group of steps this step is executed\n" +
+	"                  # concurrently with. Steps in a group must be consecutive in a phase, and\n" +
+	"                  # the group fails if any of them fails. Set for the steps of a test when\n" +
+	"                  # it is resolved, registry references cannot set it.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -624,11 +695,45 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry defines if and how this step should be executed again when it fails.\n" +
+	"                  retry:\n" +
+	"                    # Attempts is the maximum number of times the step is executed, including\n" +
+	"                    # the first execution.\n" +
+	"                    attempts: 0\n" +
+	"                    # Backoff is how long to wait before the first retry. The wait time is\n" +
+	"                    # doubled for every subsequent retry, up to MaxRetryBackoff.\n" +
+	"                    backoff: 0s\n" +
+	"                    # ExitCodes limits retries to failures where the step's process exited\n" +
+	"                    # with one of these codes.\n" +
+	"                    exit_codes:\n" +
+	"                        - 0\n" +
+	"                    # LogPattern limits retries to failures where the step's output matches\n" +
+	"                    # this regular expression.\n" +
+	"                    log_pattern: ' '\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"                  # When defines the conditions under which this step is executed. The\n" +
+	"                  # step is always executed if not set.\n" +
+	"                  when:\n" +
+	"                    # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        - \"\"\n" +
+	"                    # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                    env:\n" +
+	"                        - # Name is the name of the parameter.\n" +
+	"                          name: ' '\n" +
+	"                          # Values lists the values that meet the condition. An unset parameter\n" +
+	"                          # has the empty value.\n" +
+	"                          values:\n" +
+	"                            - \"\"\n" +
+	"                    # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                    outcome: ' '\n" +
+	"            # RegistryVersion is the content hash of the version of the step registry\n" +
+	"            # the test was resolved with, if known.\n" +
+	"            registry_version: ' '\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                - # As is the name of the LiteralTestStep.\n" +
@@ -674,6 +779,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      documentation: ' '\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Pattern is a regular expression that values of a parameter of the\n" +
+	"                      # `pattern` type must match in full.\n" +
+	"                      pattern: ' '\n" +
+	"                      # Type restricts the values the parameter accepts, optional. Values of\n" +
+	"                      # untyped parameters are not validated.\n" +
+	"                      type: ' '\n" +
+	"                      # Values lists the values accepted by a parameter of the `enum` type.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -700,6 +814,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelGroup is the name of the group of steps this step is executed\n" +
+	"                  # concurrently with. Steps in a group must be consecutive in a phase, and\n" +
+	"                  # the group fails if any of them fails. Set for the steps of a test when\n" +
+	"                  # it is resolved, registry references cannot set it.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -710,11 +829,42 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry defines if and how this step should be executed again when it fails.\n" +
+	"                  retry:\n" +
+	"                    # Attempts is the maximum number of times the step is executed, including\n" +
+	"                    # the first execution.\n" +
+	"                    attempts: 0\n" +
+	"                    # Backoff is how long to wait before the first retry. The wait time is\n" +
+	"                    # doubled for every subsequent retry, up to MaxRetryBackoff.\n" +
+	"                    backoff: 0s\n" +
+	"                    # ExitCodes limits retries to failures where the step's process exited\n" +
+	"                    # with one of these codes.\n" +
+	"                    exit_codes:\n" +
+	"                        - 0\n" +
+	"                    # LogPattern limits retries to failures where the step's output matches\n" +
+	"                    # this regular expression.\n" +
+	"                    log_pattern: ' '\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"                  # When defines the conditions under which this step is executed. The\n" +
+	"                  # step is always executed if not set.\n" +
+	"                  when:\n" +
+	"                    # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        - \"\"\n" +
+	"                    # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                    env:\n" +
+	"                        - # Name is the name of the parameter.\n" +
+	"                          name: ' '\n" +
+	"                          # Values lists the values that meet the condition. An unset parameter\n" +
+	"                          # has the empty value.\n" +
+	"                          values:\n" +
+	"                            - \"\"\n" +
+	"                    # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                    outcome: ' '\n" +
 	"        openshift_ansible:\n" +
 	"            cluster_profile: ' '\n" +
 	"        openshift_ansible_custom:\n" +
@@ -830,6 +980,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
 	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
+	"                      values:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -846,6 +1001,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelGroup is the name of the group of steps this step, or all steps\n" +
+	"                  # in the chain, are executed concurrently with. Consecutive steps with the\n" +
+	"                  # same group are executed at the same time and the group fails if any of\n" +
+	"                  # them fails. Chains cannot contain a parallel group if they are part of\n" +
+	"                  # one themselves. The changes the steps make to the files in SHARED_DIR\n" +
+	"                  # are merged; if more than one step writes the same file, the content\n" +
+	"                  # written by the step finishing last is kept.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -857,8 +1020,32 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    exit_codes:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - 0\n" +
+	"                    log_pattern: ' '\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
+	"                  # When defines the conditions under which the step, or all steps in the\n" +
+	"                  # chain, are executed, in addition to their own conditions.\n" +
+	"                  when:\n" +
+	"                    # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        - \"\"\n" +
+	"                    # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                    env:\n" +
+	"                        - # Name is the name of the parameter.\n" +
+	"                          name: ' '\n" +
+	"                          # Values lists the values that meet the condition. An unset parameter\n" +
+	"                          # has the empty value.\n" +
+	"                          values:\n" +
+	"                            - \"\"\n" +
+	"                    # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                    outcome: ' '\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -892,6 +1079,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
 	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
+	"                      values:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -908,6 +1100,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelGroup is the name of the group of steps this step, or all steps\n" +
+	"                  # in the chain, are executed concurrently with. Consecutive steps with the\n" +
+	"                  # same group are executed at the same time and the group fails if any of\n" +
+	"                  # them fails. Chains cannot contain a parallel group if they are part of\n" +
+	"                  # one themselves. The changes the steps make to the files in SHARED_DIR\n" +
+	"                  # are merged; if more than one step writes the same file, the content\n" +
+	"                  # written by the step finishing last is kept.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -919,8 +1119,37 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    exit_codes:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - 0\n" +
+	"                    log_pattern: ' '\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
+	"                  # When defines the conditions under which the step, or all steps in the\n" +
+	"                  # chain, are executed, in addition to their own conditions.\n" +
+	"                  when:\n" +
+	"                    # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        - \"\"\n" +
+	"                    # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                    env:\n" +
+	"                        - # Name is the name of the parameter.\n" +
+	"                          name: ' '\n" +
+	"                          # Values lists the values that meet the condition. An unset parameter\n" +
+	"                          # has the empty value.\n" +
+	"                          values:\n" +
+	"                            - \"\"\n" +
+	"                    # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                    outcome: ' '\n" +
+	"            # RegistryVersion pins the test to the content hash of a version of the\n" +
+	"            # step registry, so that the test resolves to the same steps as it did\n" +
+	"            # when that version was current. The latest version is used when unset.\n" +
+	"            # Resolving with a local registry fails unless it is at this version.\n" +
+	"            registry_version: ' '\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -954,6 +1183,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
 	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
+	"                      values:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -970,6 +1204,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  # ParallelGroup is the name of the group of steps this step, or all steps\n" +
+	"                  # in the chain, are executed concurrently with. Consecutive steps with the\n" +
+	"                  # same group are executed at the same time and the group fails if any of\n" +
+	"                  # them fails. Chains cannot contain a parallel group if they are part of\n" +
+	"                  # one themselves. The changes the steps make to the files in SHARED_DIR\n" +
+	"                  # are merged; if more than one step writes the same file, the content\n" +
+	"                  # written by the step finishing last is kept.\n" +
+	"                  parallel_group: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -981,8 +1223,32 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    exit_codes:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - 0\n" +
+	"                    log_pattern: ' '\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
+	"                  # When defines the conditions under which the step, or all steps in the\n" +
+	"                  # chain, are executed, in addition to their own conditions.\n" +
+	"                  when:\n" +
+	"                    # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        - \"\"\n" +
+	"                    # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                    env:\n" +
+	"                        - # Name is the name of the parameter.\n" +
+	"                          name: ' '\n" +
+	"                          # Values lists the values that meet the condition. An unset parameter\n" +
+	"                          # has the empty value.\n" +
+	"                          values:\n" +
+	"                            - \"\"\n" +
+	"                    # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                    outcome: ' '\n" +
 	"            # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"            # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
 	"            workflow: \"\"\n" +
@@ -1125,6 +1391,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # be used with rehearsals. Otherwise, the overrides should be passed in as parameters to ci-operator.\n" +
 	"        dependency_overrides:\n" +
 	"            \"\": \"\"\n" +
+	"        # Deprecations lists the deprecated registry components the test uses.\n" +
+	"        deprecations:\n" +
+	"            - # Message explains why the component is deprecated.\n" +
+	"              message: ' '\n" +
+	"              name: ' '\n" +
+	"              # RemovalDate is the date after which the component may be removed,\n" +
+	"              # formatted as YYYY-MM-DD, optional.\n" +
+	"              removal_date: ' '\n" +
+	"              # Replacement names the component to use instead, optional.\n" +
+	"              replacement: ' '\n" +
+	"              # Type is the type of the component: reference, chain or workflow.\n" +
+	"              type: ' '\n" +
 	"        # DnsConfig for step's Pod.\n" +
 	"        dnsConfig:\n" +
 	"            # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -1203,6 +1481,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  documentation: ' '\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Pattern is a regular expression that values of a parameter of the\n" +
+	"                  # `pattern` type must match in full.\n" +
+	"                  pattern: ' '\n" +
+	"                  # Type restricts the values the parameter accepts, optional. Values of\n" +
+	"                  # untyped parameters are not validated.\n" +
+	"                  type: ' '\n" +
+	"                  # Values lists the values accepted by a parameter of the `enum` type.\n" +
+	"                  values:\n" +
+	"                    - \"\"\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1229,6 +1516,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelGroup is the name of the group of steps this step is executed\n" +
+	"              # concurrently with. Steps in a group must be consecutive in a phase, and\n" +
+	"              # the group fails if any of them fails. Set for the steps of a test when\n" +
+	"              # it is resolved, registry references cannot set it.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1239,11 +1531,42 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry defines if and how this step should be executed again when it fails.\n" +
+	"              retry:\n" +
+	"                # Attempts is the maximum number of times the step is executed, including\n" +
+	"                # the first execution.\n" +
+	"                attempts: 0\n" +
+	"                # Backoff is how long to wait before the first retry. The wait time is\n" +
+	"                # doubled for every subsequent retry, up to MaxRetryBackoff.\n" +
+	"                backoff: 0s\n" +
+	"                # ExitCodes limits retries to failures where the step's process exited\n" +
+	"                # with one of these codes.\n" +
+	"                exit_codes:\n" +
+	"                    - 0\n" +
+	"                # LogPattern limits retries to failures where the step's output matches\n" +
+	"                # this regular expression.\n" +
+	"                log_pattern: ' '\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"              # When defines the conditions under which this step is executed. The\n" +
+	"              # step is always executed if not set.\n" +
+	"              when:\n" +
+	"                # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    - \"\"\n" +
+	"                # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                env:\n" +
+	"                    - # Name is the name of the parameter.\n" +
+	"                      name: ' '\n" +
+	"                      # Values lists the values that meet the condition. An unset parameter\n" +
+	"                      # has the empty value.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
+	"                # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                outcome: ' '\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
 	"            - # As is the name of the LiteralTestStep.\n" +
@@ -1289,6 +1612,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  documentation: ' '\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Pattern is a regular expression that values of a parameter of the\n" +
+	"                  # `pattern` type must match in full.\n" +
+	"                  pattern: ' '\n" +
+	"                  # Type restricts the values the parameter accepts, optional. Values of\n" +
+	"                  # untyped parameters are not validated.\n" +
+	"                  type: ' '\n" +
+	"                  # Values lists the values accepted by a parameter of the `enum` type.\n" +
+	"                  values:\n" +
+	"                    - \"\"\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1315,6 +1647,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelGroup is the name of the group of steps this step is executed\n" +
+	"              # concurrently with. Steps in a group must be consecutive in a phase, and\n" +
+	"              # the group fails if any of them fails. Set for the steps of a test when\n" +
+	"              # it is resolved, registry references cannot set it.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1325,11 +1662,45 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry defines if and how this step should be executed again when it fails.\n" +
+	"              retry:\n" +
+	"                # Attempts is the maximum number of times the step is executed, including\n" +
+	"                # the first execution.\n" +
+	"                attempts: 0\n" +
+	"                # Backoff is how long to wait before the first retry. The wait time is\n" +
+	"                # doubled for every subsequent retry, up to MaxRetryBackoff.\n" +
+	"                backoff: 0s\n" +
+	"                # ExitCodes limits retries to failures where the step's process exited\n" +
+	"                # with one of these codes.\n" +
+	"                exit_codes:\n" +
+	"                    - 0\n" +
+	"                # LogPattern limits retries to failures where the step's output matches\n" +
+	"                # this regular expression.\n" +
+	"                log_pattern: ' '\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"              # When defines the conditions under which this step is executed. The\n" +
+	"              # step is always executed if not set.\n" +
+	"              when:\n" +
+	"                # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    - \"\"\n" +
+	"                # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                env:\n" +
+	"                    - # Name is the name of the parameter.\n" +
+	"                      name: ' '\n" +
+	"                      # Values lists the values that meet the condition. An unset parameter\n" +
+	"                      # has the empty value.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
+	"                # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                outcome: ' '\n" +
+	"        # RegistryVersion is the content hash of the version of the step registry\n" +
+	"        # the test was resolved with, if known.\n" +
+	"        registry_version: ' '\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            - # As is the name of the LiteralTestStep.\n" +
@@ -1375,6 +1746,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  documentation: ' '\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Pattern is a regular expression that values of a parameter of the\n" +
+	"                  # `pattern` type must match in full.\n" +
+	"                  pattern: ' '\n" +
+	"                  # Type restricts the values the parameter accepts, optional. Values of\n" +
+	"                  # untyped parameters are not validated.\n" +
+	"                  type: ' '\n" +
+	"                  # Values lists the values accepted by a parameter of the `enum` type.\n" +
+	"                  values:\n" +
+	"                    - \"\"\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1401,6 +1781,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelGroup is the name of the group of steps this step is executed\n" +
+	"              # concurrently with. Steps in a group must be consecutive in a phase, and\n" +
+	"              # the group fails if any of them fails. Set for the steps of a test when\n" +
+	"              # it is resolved, registry references cannot set it.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1411,11 +1796,42 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry defines if and how this step should be executed again when it fails.\n" +
+	"              retry:\n" +
+	"                # Attempts is the maximum number of times the step is executed, including\n" +
+	"                # the first execution.\n" +
+	"                attempts: 0\n" +
+	"                # Backoff is how long to wait before the first retry. The wait time is\n" +
+	"                # doubled for every subsequent retry, up to MaxRetryBackoff.\n" +
+	"                backoff: 0s\n" +
+	"                # ExitCodes limits retries to failures where the step's process exited\n" +
+	"                # with one of these codes.\n" +
+	"                exit_codes:\n" +
+	"                    - 0\n" +
+	"                # LogPattern limits retries to failures where the step's output matches\n" +
+	"                # this regular expression.\n" +
+	"                log_pattern: ' '\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"              # When defines the conditions under which this step is executed. The\n" +
+	"              # step is always executed if not set.\n" +
+	"              when:\n" +
+	"                # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    - \"\"\n" +
+	"                # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                env:\n" +
+	"                    - # Name is the name of the parameter.\n" +
+	"                      name: ' '\n" +
+	"                      # Values lists the values that meet the condition. An unset parameter\n" +
+	"                      # has the empty value.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
+	"                # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                outcome: ' '\n" +
 	"      openshift_ansible:\n" +
 	"        cluster_profile: ' '\n" +
 	"      openshift_ansible_custom:\n" +
@@ -1531,6 +1947,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
 	"                  name: ' '\n" +
+	"                  pattern: ' '\n" +
+	"                  type: ' '\n" +
+	"                  values:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1547,6 +1968,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelGroup is the name of the group of steps this step, or all steps\n" +
+	"              # in the chain, are executed concurrently with. Consecutive steps with the\n" +
+	"              # same group are executed at the same time and the group fails if any of\n" +
+	"              # them fails. Chains cannot contain a parallel group if they are part of\n" +
+	"              # one themselves. The changes the steps make to the files in SHARED_DIR\n" +
+	"              # are merged; if more than one step writes the same file, the content\n" +
+	"              # written by the step finishing last is kept.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -1558,8 +1987,32 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                attempts: 0\n" +
+	"                backoff: 0s\n" +
+	"                exit_codes:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - 0\n" +
+	"                log_pattern: ' '\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
+	"              # When defines the conditions under which the step, or all steps in the\n" +
+	"              # chain, are executed, in addition to their own conditions.\n" +
+	"              when:\n" +
+	"                # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    - \"\"\n" +
+	"                # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                env:\n" +
+	"                    - # Name is the name of the parameter.\n" +
+	"                      name: ' '\n" +
+	"                      # Values lists the values that meet the condition. An unset parameter\n" +
+	"                      # has the empty value.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
+	"                # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                outcome: ' '\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
@@ -1593,6 +2046,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
 	"                  name: ' '\n" +
+	"                  pattern: ' '\n" +
+	"                  type: ' '\n" +
+	"                  values:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1609,6 +2067,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelGroup is the name of the group of steps this step, or all steps\n" +
+	"              # in the chain, are executed concurrently with. Consecutive steps with the\n" +
+	"              # same group are executed at the same time and the group fails if any of\n" +
+	"              # them fails. Chains cannot contain a parallel group if they are part of\n" +
+	"              # one themselves. The changes the steps make to the files in SHARED_DIR\n" +
+	"              # are merged; if more than one step writes the same file, the content\n" +
+	"              # written by the step finishing last is kept.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -1620,8 +2086,37 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                attempts: 0\n" +
+	"                backoff: 0s\n" +
+	"                exit_codes:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - 0\n" +
+	"                log_pattern: ' '\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
+	"              # When defines the conditions under which the step, or all steps in the\n" +
+	"              # chain, are executed, in addition to their own conditions.\n" +
+	"              when:\n" +
+	"                # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    - \"\"\n" +
+	"                # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                env:\n" +
+	"                    - # Name is the name of the parameter.\n" +
+	"                      name: ' '\n" +
+	"                      # Values lists the values that meet the condition. An unset parameter\n" +
+	"                      # has the empty value.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
+	"                # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                outcome: ' '\n" +
+	"        # RegistryVersion pins the test to the content hash of a version of the\n" +
+	"        # step registry, so that the test resolves to the same steps as it did\n" +
+	"        # when that version was current. The latest version is used when unset.\n" +
+	"        # Resolving with a local registry fails unless it is at this version.\n" +
+	"        registry_version: ' '\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
@@ -1655,6 +2150,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
 	"                  name: ' '\n" +
+	"                  pattern: ' '\n" +
+	"                  type: ' '\n" +
+	"                  values:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1671,6 +2171,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              # ParallelGroup is the name of the group of steps this step, or all steps\n" +
+	"              # in the chain, are executed concurrently with. Consecutive steps with the\n" +
+	"              # same group are executed at the same time and the group fails if any of\n" +
+	"              # them fails. Chains cannot contain a parallel group if they are part of\n" +
+	"              # one themselves. The changes the steps make to the files in SHARED_DIR\n" +
+	"              # are merged; if more than one step writes the same file, the content\n" +
+	"              # written by the step finishing last is kept.\n" +
+	"              parallel_group: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -1682,8 +2190,32 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                attempts: 0\n" +
+	"                backoff: 0s\n" +
+	"                exit_codes:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - 0\n" +
+	"                log_pattern: ' '\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
+	"              # When defines the conditions under which the step, or all steps in the\n" +
+	"              # chain, are executed, in addition to their own conditions.\n" +
+	"              when:\n" +
+	"                # ClusterProfiles limits the step to tests using one of these cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    - \"\"\n" +
+	"                # Env limits the step to tests where the parameters have one of the listed values.\n" +
+	"                env:\n" +
+	"                    - # Name is the name of the parameter.\n" +
+	"                      name: ' '\n" +
+	"                      # Values lists the values that meet the condition. An unset parameter\n" +
+	"                      # has the empty value.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
+	"                # Outcome limits the step to the outcome of the steps before it.\n" +
+	"                outcome: ' '\n" +
 	"        # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"        # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
 	"        workflow: \"\"\n" +